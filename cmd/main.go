@@ -3,46 +3,148 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 	"todo-list-task/internal/app"
+	"todo-list-task/internal/config"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/events"
+	"todo-list-task/internal/infrastructure/file"
 	handlerHttp "todo-list-task/internal/infrastructure/http"
 	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/infrastructure/repository"
+	"todo-list-task/internal/infrastructure/sqldb"
 	"todo-list-task/internal/middleware"
+	"todo-list-task/internal/search"
 	"todo-list-task/internal/utils"
+
+	_ "modernc.org/sqlite"
 )
 
 func main() {
 
-	taskRepo := memory.NewInMemoryTaskRepository()
-	taskService := app.NewTaskService(taskRepo)
-	taskHandler := handlerHttp.NewTaskHandler(taskService)
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		log.Fatalf("Configuración inválida: %v", err)
+	}
 
 	bcryptCrypto := app.BcryptCrypto{}
-	appCrypto := utils.NewHashPassword(bcryptCrypto)
-	userRepo := memory.NewInMemoryUserRepository(appCrypto)
+	appCrypto := utils.NewHashPassword(bcryptCrypto, cfg.Auth.BcryptCost)
+	jwtManager, err := newJWTManager(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Error al cargar las llaves de firma: %v", err)
+	}
+
+	repos, err := newRepositories(cfg.Storage, appCrypto, jwtManager)
+	if err != nil {
+		log.Fatalf("Error al inicializar el almacenamiento: %v", err)
+	}
+	defer repos.close()
+
+	index := search.NewIndex()
+	tasks, err := newIndexedTasks(repos, index)
+	if err != nil {
+		log.Fatalf("Error al reconstruir el índice de búsqueda: %v", err)
+	}
+
+	bus := events.NewBus()
+	webhooks := memory.NewInMemoryWebhookRepository()
+	dispatcher := app.NewWebhookDispatcher(webhooks, app.NewWebhookClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivate), cfg.Webhooks.MaxAttempts, cfg.Webhooks.Backoff, cfg.Webhooks.Workers)
+	bus.Subscribe(dispatcher.Handle)
+	stream := app.NewTaskStream(repos.lists, cfg.Stream.BufferSize)
+	bus.Subscribe(stream.Handle)
+	streamTickets := app.NewStreamTickets(app.StreamTicketTTL)
 
-	userService := app.NewUserService(userRepo)
+	taskService := app.NewTaskService(tasks, repos.lists, repos.audit)
+	taskHandler := handlerHttp.NewTaskHandler(taskService)
+	listHandler := handlerHttp.NewListHandler(app.NewListService(repos.lists, tasks, repos.users))
+	searchHandler := handlerHttp.NewSearchHandler(app.NewSearchService(index))
+	webhookHandler := handlerHttp.NewWebhookHandler(app.NewWebhookService(webhooks, net.DefaultResolver, cfg.Webhooks.AllowPrivate))
+	streamHandler := handlerHttp.NewStreamHandler(stream, streamTickets, cfg.Stream.Heartbeat)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	reminders := app.NewReminderScheduler(tasks, cfg.Tasks.ReminderInterval, func(event domain.ReminderEvent) {
+		log.Printf("Recordatorio: la tarea %q (%s) de %s vence el %s", event.Title, event.TaskID, event.OwnerID, event.DueAt.Format(time.RFC3339))
+	})
+	go reminders.Run(ctx)
+	go app.NewOutboxRelay(tasks, bus, cfg.Tasks.OutboxInterval).Run(ctx)
+	go app.NewTrashPurger(tasks, cfg.Tasks.TrashRetention, cfg.Tasks.PurgeInterval).Run(ctx)
+	go dispatcher.Run(ctx)
+
+	tokenService := app.NewTokenService(memory.NewInMemoryRefreshTokenRepository(), jwtManager, cfg.Auth.RefreshTokenTTL)
+	userService := app.NewUserService(repos.users, tokenService)
+	if cfg.Auth.AdminUsername != "" {
+		if err := userService.BootstrapAdmin(cfg.Auth.AdminUsername, cfg.Auth.AdminPassword); err != nil {
+			log.Fatalf("Error al crear el administrador inicial: %v", err)
+		}
+	}
 	userHandler := handlerHttp.NewUserHandler(userService)
 
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 
 	r.POST("/users", userHandler.RegisterUser)
 	r.POST("/login", userHandler.LoginUser)
+	r.POST("/token/refresh", userHandler.RefreshToken)
+	r.POST("/logout", userHandler.Logout)
+	r.GET("/.well-known/jwks.json", handlerHttp.NewJWKSHandler(jwtManager.Keys()).GetJWKS)
+
+	auth := middleware.AuthMiddleware(jwtManager, repos.users)
+	r.POST("/tasks", auth, taskHandler.RegisterTask)
+	r.POST("/tasks:batch", auth, taskHandler.BatchTasks)
+	r.GET("/tasks/:id", auth, taskHandler.GetTaskByID)
+	r.GET("/tasks/:id/subtasks", auth, taskHandler.GetSubtasks)
+	r.GET("/tasks/:id/blockers", auth, taskHandler.GetBlockers)
+	r.GET("/tasks", auth, taskHandler.GetAllTask)
+	r.GET("/tasks/export", auth, taskHandler.ExportTasks)
+	r.GET("/tasks/search", auth, searchHandler.SearchTasks)
+	streamAuth := middleware.StreamAuthMiddleware(jwtManager, repos.users, streamTickets)
+	r.POST("/tasks/stream/tickets", auth, streamHandler.CreateStreamTicket)
+	r.GET("/tasks/stream", streamAuth, streamHandler.StreamTasks)
+	r.GET("/tasks/stream/ws", streamAuth, streamHandler.StreamTasksWebSocket)
+	r.POST("/tasks/import", auth, taskHandler.ImportTasks)
+	r.PUT("/tasks/:id", auth, taskHandler.UpdateTask)
+	r.PATCH("/tasks/:id", auth, taskHandler.PatchTask)
+	r.DELETE("tasks/:id", auth, taskHandler.DeleteTask)
+	r.GET("/tasks/:id/history", auth, taskHandler.GetTaskHistory)
+	r.POST("/tasks/:id/history/:revision/restore", auth, taskHandler.RestoreTaskRevision)
+	r.POST("/tasks/:id/restore", auth, taskHandler.RestoreTask)
+	r.GET("/trash", auth, taskHandler.GetTrash)
+	r.GET("/tags", auth, taskHandler.GetTags)
+
+	r.POST("/lists", auth, listHandler.CreateList)
+	r.GET("/lists", auth, listHandler.GetLists)
+	r.GET("/lists/:id", auth, listHandler.GetList)
+	r.PUT("/lists/:id", auth, listHandler.UpdateList)
+	r.DELETE("/lists/:id", auth, listHandler.DeleteList)
+	r.PUT("/lists/:id/members/:username", auth, listHandler.ShareList)
+	r.DELETE("/lists/:id/members/:username", auth, listHandler.UnshareList)
+
+	r.POST("/webhooks", auth, webhookHandler.CreateWebhook)
+	r.GET("/webhooks", auth, webhookHandler.GetWebhooks)
+	r.GET("/webhooks/dead-letters", auth, webhookHandler.GetDeadLetters)
+	r.DELETE("/webhooks/:id", auth, webhookHandler.DeleteWebhook)
+	r.GET("/webhooks/:id/deliveries", auth, webhookHandler.GetDeliveries)
 
-	r.POST("/tasks", middleware.AuthMiddleware(), taskHandler.RegisterTask)
-	r.GET("/tasks/:id", middleware.AuthMiddleware(), taskHandler.GetTaskByID)
-	r.GET("/tasks", middleware.AuthMiddleware(), taskHandler.GetAllTask)
-	r.PUT("/tasks/:id", middleware.AuthMiddleware(), taskHandler.UpdateTask)
-	r.DELETE("tasks/:id", middleware.AuthMiddleware(), taskHandler.DeleteTask)
+	adminHandler := handlerHttp.NewAdminHandler(userService, taskService)
+	admin := r.Group("/admin", auth, middleware.RequireRole(domain.RoleAdmin))
+	admin.GET("/users", adminHandler.ListUsers)
+	admin.POST("/users/:id/disable", adminHandler.DisableUser)
+	admin.POST("/users/:id/enable", adminHandler.EnableUser)
+	admin.PUT("/users/:id/role", adminHandler.SetUserRole)
+	admin.GET("/users/:id/tasks", adminHandler.GetUserTasks)
+	admin.GET("/audit", adminHandler.GetAuditLog)
 
 	srv := &http.Server{
-		Addr:    ":8080",
+		Addr:    cfg.Server.Addr,
 		Handler: r,
 	}
 
@@ -52,7 +154,8 @@ func main() {
 	go func() {
 		<-quit
 		log.Println("Recibida señal de cierre, apagando servidor...")
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		stop()
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 
 		defer cancel()
 
@@ -62,10 +165,105 @@ func main() {
 		log.Println("Servidor apagado correctamente")
 	}()
 
-	log.Println("Servidor iniciado en el puerto 8080")
+	log.Printf("Servidor iniciado en %s", cfg.Server.Addr)
 	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Error en el servidor: %v", err)
 	}
 
 	log.Println("Salida limpia del programa")
 }
+
+// newJWTManager signs with the PEM keys of KeysDir when set, or with the HS256 secret otherwise.
+func newJWTManager(cfg config.AuthConfig) (*utils.JWTManager, error) {
+	if cfg.KeysDir == "" {
+		return utils.NewJWTManager(cfg.JWTSecret, cfg.TokenTTL), nil
+	}
+	keys, err := utils.LoadKeyRing(cfg.KeysDir, cfg.ActiveKey, cfg.RetiredKeys)
+	if err != nil {
+		return nil, err
+	}
+	return utils.NewJWTManagerWithKeys(keys, cfg.TokenTTL), nil
+}
+
+// newIndexedTasks wraps the task repository so every write updates the
+// search index, and indexes the tasks of every user already stored.
+func newIndexedTasks(repos *repositories, index *search.Index) (*search.IndexedTaskRepository, error) {
+	tasks := search.NewIndexedTaskRepository(repos.tasks, index)
+	users, err := repos.users.List()
+	if err != nil {
+		return nil, err
+	}
+	ownerIDs := make([]string, len(users))
+	for i, user := range users {
+		ownerIDs[i] = user.ID
+	}
+	return tasks, tasks.Rebuild(ownerIDs)
+}
+
+// repositories are the repositories of one storage driver; close releases them.
+type repositories struct {
+	tasks repository.TaskRepository
+	users repository.UserRepository
+	lists repository.ListRepository
+	audit repository.AuditRepository
+	close func()
+}
+
+// newRepositories builds the repositories for the configured storage driver:
+//   - memory: data lives only while the process runs.
+//   - file: write-ahead log and snapshots under DataDir.
+//   - sql: database/sql using DatabaseDriver and DatabaseDSN, migrated at startup.
+func newRepositories(cfg config.StorageConfig, appCrypto *utils.DefaultAppCrypto, jwt *utils.JWTManager) (*repositories, error) {
+	switch cfg.Driver {
+	case "memory":
+		taskRepo := memory.NewInMemoryTaskRepository()
+		return &repositories{
+			tasks: taskRepo,
+			users: memory.NewInMemoryUserRepository(appCrypto, jwt),
+			lists: memory.NewInMemoryListRepository(),
+			audit: taskRepo,
+			close: func() {},
+		}, nil
+	case "file":
+		taskRepo, err := file.NewFileTaskRepository(cfg.DataDir, cfg.CompactEvery)
+		if err != nil {
+			return nil, err
+		}
+		userRepo, err := file.NewFileUserRepository(cfg.DataDir, cfg.CompactEvery, appCrypto, jwt)
+		if err != nil {
+			_ = taskRepo.Close()
+			return nil, err
+		}
+		listRepo, err := file.NewFileListRepository(cfg.DataDir, cfg.CompactEvery)
+		if err != nil {
+			_ = taskRepo.Close()
+			_ = userRepo.Close()
+			return nil, err
+		}
+		return &repositories{
+			tasks: taskRepo,
+			users: userRepo,
+			lists: listRepo,
+			audit: taskRepo,
+			close: func() {
+				_ = taskRepo.Close()
+				_ = userRepo.Close()
+				_ = listRepo.Close()
+			},
+		}, nil
+	case "sql":
+		db, err := sqldb.Open(cfg.DatabaseDriver, cfg.DatabaseDSN)
+		if err != nil {
+			return nil, err
+		}
+		return &repositories{
+			tasks: sqldb.NewSQLTaskRepository(db),
+			users: sqldb.NewSQLUserRepository(db, appCrypto, jwt),
+			lists: sqldb.NewSQLListRepository(db),
+			audit: sqldb.NewSQLAuditRepository(db),
+			close: func() { _ = db.Close() },
+		}, nil
+	default:
+		return nil, fmt.Errorf("driver de almacenamiento desconocido: %q", cfg.Driver)
+	}
+}
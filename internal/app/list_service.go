@@ -0,0 +1,153 @@
+package app
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// ListService manages the lists of a user and who they are shared with.
+// Members can read a list; only its owner can rename, delete or share it.
+type ListService struct {
+	lists repository.ListRepository
+	tasks repository.TaskRepository
+	users repository.UserRepository
+}
+
+func NewListService(lists repository.ListRepository, tasks repository.TaskRepository, users repository.UserRepository) *ListService {
+	return &ListService{lists: lists, tasks: tasks, users: users}
+}
+
+func (l ListService) CreateList(userID string, request domain.ListRequest) (*domain.List, error) {
+	now := time.Now().UTC()
+	return l.lists.CreateList(&domain.List{
+		ID:        uuid.NewString(),
+		OwnerID:   userID,
+		Name:      request.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Members:   []domain.ListMember{},
+	})
+}
+
+// GetList returns a list the user owns or is a member of.
+func (l ListService) GetList(userID, id string) (*domain.List, error) {
+	list, err := l.lists.GetList(id)
+	if err != nil {
+		return nil, err
+	}
+	if list.RoleOf(userID) == "" {
+		return nil, domain.ErrListNotFound
+	}
+	return list, nil
+}
+
+// GetLists returns the lists the user owns or is a member of, oldest first.
+func (l ListService) GetLists(userID string) ([]*domain.List, error) {
+	lists, err := l.lists.GetListsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if lists == nil {
+		lists = []*domain.List{}
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		if !lists[i].CreatedAt.Equal(lists[j].CreatedAt) {
+			return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+		}
+		return lists[i].ID < lists[j].ID
+	})
+	return lists, nil
+}
+
+// ownedList returns a list the user owns. Members get ErrNotListOwner.
+func (l ListService) ownedList(userID, id string) (*domain.List, error) {
+	list, err := l.GetList(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if list.OwnerID != userID {
+		return nil, domain.ErrNotListOwner
+	}
+	return list, nil
+}
+
+func (l ListService) UpdateList(userID, id string, request domain.ListRequest) (*domain.List, error) {
+	list, err := l.ownedList(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	list.Name = request.Name
+	list.UpdatedAt = time.Now().UTC()
+	return l.lists.UpdateList(list)
+}
+
+// DeleteList deletes an empty list. Its tasks have to be deleted or moved
+// out first, so deleting a shared list never takes tasks away silently.
+func (l ListService) DeleteList(userID, id string) error {
+	list, err := l.ownedList(userID, id)
+	if err != nil {
+		return err
+	}
+
+	query := domain.TaskQuery{OwnerID: list.OwnerID, ListID: list.ID, Limit: 1}
+	if err := query.Normalize(); err != nil {
+		return err
+	}
+	page, err := l.tasks.QueryTasks(query)
+	if err != nil {
+		return err
+	}
+	if len(page.Tasks) > 0 {
+		return domain.ErrListNotEmpty
+	}
+	return l.lists.DeleteList(id)
+}
+
+// ShareList shares a list with the user with the given username, or
+// changes the role of an existing member.
+func (l ListService) ShareList(userID, id, username, role string) (*domain.List, error) {
+	list, err := l.ownedList(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := l.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if member.ID == list.OwnerID {
+		return nil, domain.NewError(domain.ErrValidation, "a list cannot be shared with its owner")
+	}
+
+	if err := l.lists.SetMember(id, domain.ListMember{UserID: member.ID, Username: member.Username, Role: role}); err != nil {
+		return nil, err
+	}
+	return l.lists.GetList(id)
+}
+
+// UnshareList removes the member with the given username from a list. The
+// owner can remove anyone; a member can only leave the list.
+func (l ListService) UnshareList(userID, id, username string) error {
+	list, err := l.GetList(userID, id)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range list.Members {
+		if member.Username != username {
+			continue
+		}
+		if list.OwnerID != userID && member.UserID != userID {
+			return domain.ErrNotListOwner
+		}
+		return l.lists.RemoveMember(id, member.UserID)
+	}
+	if list.OwnerID != userID {
+		return domain.ErrNotListOwner
+	}
+	return domain.ErrListMemberNotFound
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-list-task/internal/events"
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// outboxBatchSize is how many events the relay reads from the outbox at once.
+const outboxBatchSize = 100
+
+// OutboxRelay publishes the task events recorded in the outbox of the task
+// repository and acknowledges them once published. Delivery is at least
+// once: when the process stops between publishing and acknowledging, the
+// events are published again after the restart, with the same IDs, and
+// subscribers use those IDs to drop the repeats.
+type OutboxRelay struct {
+	outbox    repository.TaskOutbox
+	publisher events.Publisher
+	interval  time.Duration
+}
+
+func NewOutboxRelay(outbox repository.TaskOutbox, publisher events.Publisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, publisher: publisher, interval: interval}
+}
+
+// Run calls Drain every interval until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Drain(); err != nil {
+				log.Printf("Error al publicar los eventos de tareas: %v", err)
+			}
+		}
+	}
+}
+
+// Drain publishes the pending events, oldest first, acknowledging them a
+// batch at a time, until the outbox is empty. When a batch cannot be
+// acknowledged it stays in the outbox and is published again by the next
+// call.
+func (r *OutboxRelay) Drain() error {
+	for {
+		pending, err := r.outbox.PendingEvents(outboxBatchSize)
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(pending))
+		for i, event := range pending {
+			r.publisher.Publish(event)
+			ids[i] = event.ID
+		}
+		if err := r.outbox.AckEvents(ids); err != nil {
+			return err
+		}
+	}
+}
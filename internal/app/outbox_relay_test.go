@@ -0,0 +1,115 @@
+package app_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/events"
+	"todo-list-task/internal/infrastructure/file"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crashingOutbox stops the relay between publishing events and
+// acknowledging them, as a crash of the process would.
+type crashingOutbox struct {
+	repository.TaskOutbox
+}
+
+func (crashingOutbox) AckEvents([]string) error {
+	return errors.New("crashed")
+}
+
+func openTasks(t *testing.T, dir string) *file.FileTaskRepository {
+	repo, err := file.NewFileTaskRepository(dir, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestOutboxRelay_Drain(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	service := app.NewTaskService(repo, memory.NewInMemoryListRepository(), memory.NewInMemoryAuditRepository())
+	created, err := service.RegisterTask("user-1", &domain.TaskRequest{Title: "title", Description: "description"})
+	require.NoError(t, err)
+	_, err = service.UpdateTaskByID("user-1", created.ID, 0, domain.TaskRequest{Title: "title", Description: "description", Completed: true})
+	require.NoError(t, err)
+
+	bus := events.NewBus()
+	var published []string
+	bus.Subscribe(func(event domain.TaskEvent) { published = append(published, event.Type) })
+
+	require.NoError(t, app.NewOutboxRelay(repo, bus, time.Second).Drain())
+	assert.Equal(t, []string{domain.TaskCreated, domain.TaskCompleted}, published)
+
+	require.NoError(t, app.NewOutboxRelay(repo, bus, time.Second).Drain())
+	assert.Len(t, published, 2, "acknowledged events are not published again")
+}
+
+func TestOutboxRelay_CrashBeforePublishing(t *testing.T) {
+	dir := t.TempDir()
+	repo := openTasks(t, dir)
+	_, err := app.NewTaskService(repo, memory.NewInMemoryListRepository(), memory.NewInMemoryAuditRepository()).RegisterTask("user-1", &domain.TaskRequest{Title: "title", Description: "description"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	// The process stopped before the relay ran: the event is published
+	// after the restart.
+	restarted := openTasks(t, dir)
+	bus := events.NewBus()
+	var published []domain.TaskEvent
+	bus.Subscribe(func(event domain.TaskEvent) { published = append(published, event) })
+	require.NoError(t, app.NewOutboxRelay(restarted, bus, time.Second).Drain())
+
+	require.Len(t, published, 1)
+	assert.Equal(t, domain.TaskCreated, published[0].Type)
+	assert.Equal(t, "user-1", published[0].ActorID)
+}
+
+func TestOutboxRelay_CrashBeforeAcknowledging(t *testing.T) {
+	dir := t.TempDir()
+	lists := memory.NewInMemoryListRepository()
+	webhooks := memory.NewInMemoryWebhookRepository()
+	_, err := app.NewWebhookService(webhooks, nil, true).CreateWebhook("user-1", domain.WebhookRequest{URL: "https://example.com/hooks", Events: domain.TaskEventTypes})
+	require.NoError(t, err)
+	dispatcher := app.NewWebhookDispatcher(webhooks, http.DefaultClient, 3, time.Minute, 1)
+	stream := app.NewTaskStream(lists, 10)
+	subscription, _, _ := stream.Subscribe("user-1", "")
+
+	bus := events.NewBus()
+	bus.Subscribe(dispatcher.Handle)
+	bus.Subscribe(stream.Handle)
+	var published []string
+	bus.Subscribe(func(event domain.TaskEvent) { published = append(published, event.ID) })
+
+	repo := openTasks(t, dir)
+	service := app.NewTaskService(repo, lists, memory.NewInMemoryAuditRepository())
+	created, err := service.RegisterTask("user-1", &domain.TaskRequest{Title: "title", Description: "description"})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTaskByID("user-1", created.ID))
+
+	assert.EqualError(t, app.NewOutboxRelay(crashingOutbox{repo}, bus, time.Second).Drain(), "crashed")
+	require.NoError(t, repo.Close())
+
+	// After the restart the events are published again, with the same IDs,
+	// and the consumers drop the repeats.
+	restarted := openTasks(t, dir)
+	require.NoError(t, app.NewOutboxRelay(restarted, bus, time.Second).Drain())
+
+	ids := []string{domain.TaskEventID(created.ID, 1), domain.TaskEventID(created.ID, 2)}
+	assert.Equal(t, append(ids, ids...), published)
+	pending, err := restarted.PendingEvents(10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	deliveries, err := webhooks.GetDueDeliveries(time.Now().UTC())
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+	assert.Equal(t, []string{created.ID, created.ID}, receivedTasks(subscription))
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// ReminderScheduler periodically looks for task reminders whose time has
+// been reached and emits an event for each of them.
+type ReminderScheduler struct {
+	repo     repository.TaskRepository
+	interval time.Duration
+	emit     func(domain.ReminderEvent)
+	last     time.Time
+}
+
+func NewReminderScheduler(repo repository.TaskRepository, interval time.Duration, emit func(domain.ReminderEvent)) *ReminderScheduler {
+	return &ReminderScheduler{repo: repo, interval: interval, emit: emit}
+}
+
+// Run calls Tick every interval until ctx is cancelled.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.Tick(now.UTC()); err != nil {
+				log.Printf("Error al buscar recordatorios: %v", err)
+			}
+		}
+	}
+}
+
+// Tick emits, in time order, every reminder reached since the previous tick
+// and up to now. The first tick looks back one interval. When the tasks
+// cannot be read the window is kept, so the next tick catches up.
+func (s *ReminderScheduler) Tick(now time.Time) error {
+	from := s.last
+	if from.IsZero() {
+		from = now.Add(-s.interval)
+	}
+
+	// A reminder fires at most MaxReminderMinutes before the due date.
+	tasks, err := s.repo.GetTasksDueBetween(from, now.Add(domain.MaxReminderMinutes*time.Minute))
+	if err != nil {
+		return err
+	}
+
+	var events []domain.ReminderEvent
+	for _, task := range tasks {
+		events = append(events, task.RemindersBetween(from, now)...)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].RemindAt.Equal(events[j].RemindAt) {
+			return events[i].RemindAt.Before(events[j].RemindAt)
+		}
+		return events[i].TaskID < events[j].TaskID
+	})
+	for _, event := range events {
+		s.emit(event)
+	}
+
+	s.last = now
+	return nil
+}
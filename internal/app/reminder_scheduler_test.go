@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderScheduler_Tick(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	due := start.Add(30 * time.Minute)
+	horizon := domain.MaxReminderMinutes * time.Minute
+	task := &domain.Task{
+		ID:           "1",
+		OwnerID:      "user-1",
+		Title:        "Call the plumber",
+		TaskSchedule: domain.TaskSchedule{DueAt: &due, ReminderMinutes: []int{0, 29, 30, 60}},
+	}
+
+	repo := &mocks.TaskRepository{}
+	var events []domain.ReminderEvent
+	scheduler := app.NewReminderScheduler(repo, time.Minute, func(event domain.ReminderEvent) {
+		events = append(events, event)
+	})
+
+	repo.On("GetTasksDueBetween", start.Add(-time.Minute), start.Add(horizon)).Return([]*domain.Task{task}, nil).Once()
+	require.NoError(t, scheduler.Tick(start))
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.ReminderEvent{
+		TaskID:        "1",
+		OwnerID:       "user-1",
+		Title:         "Call the plumber",
+		DueAt:         due,
+		RemindAt:      start,
+		MinutesBefore: 30,
+	}, events[0])
+
+	// A failed tick keeps its window for the next one.
+	repo.On("GetTasksDueBetween", start, start.Add(time.Minute+horizon)).Return(nil, errors.New("database is down")).Once()
+	assert.Error(t, scheduler.Tick(start.Add(time.Minute)))
+
+	repo.On("GetTasksDueBetween", start, start.Add(2*time.Minute+horizon)).Return([]*domain.Task{task}, nil).Once()
+	require.NoError(t, scheduler.Tick(start.Add(2*time.Minute)))
+	require.Len(t, events, 2)
+	assert.Equal(t, 29, events[1].MinutesBefore)
+
+	repo.AssertExpectations(t)
+}
+
+func TestTask_RemindersBetween_SkipsCompletedTasks(t *testing.T) {
+	due := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	task := &domain.Task{Completed: true, TaskSchedule: domain.TaskSchedule{DueAt: &due, ReminderMinutes: []int{0}}}
+
+	assert.Empty(t, task.RemindersBetween(due.Add(-time.Hour), due))
+}
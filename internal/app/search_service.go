@@ -0,0 +1,32 @@
+package app
+
+import (
+	"strings"
+
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/search"
+)
+
+// SearchService runs full-text searches over the tasks of a user.
+type SearchService struct {
+	index *search.Index
+}
+
+func NewSearchService(index *search.Index) *SearchService {
+	return &SearchService{index: index}
+}
+
+// SearchTasks returns up to limit of the user's tasks matching q, most
+// relevant first. The limit defaults to and is capped like a task query.
+func (s SearchService) SearchTasks(userID, q string, limit int) ([]search.Result, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, domain.NewError(domain.ErrValidation, "q is required")
+	}
+	if limit <= 0 {
+		limit = domain.DefaultTaskLimit
+	}
+	if limit > domain.MaxTaskLimit {
+		limit = domain.MaxTaskLimit
+	}
+	return s.index.Search(userID, q, limit), nil
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"todo-list-task/internal/domain"
+)
+
+// StreamTicketTTL is how long a stream ticket can be redeemed after it is issued.
+const StreamTicketTTL = 30 * time.Second
+
+// StreamTickets issues and redeems stream tickets. A ticket travels in the
+// URL, where it may end up in logs, so it can be redeemed only once and
+// only shortly after it is issued; only its hash is kept.
+type StreamTickets struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	tickets map[string]issuedTicket
+}
+
+type issuedTicket struct {
+	userID    string
+	expiresAt time.Time
+}
+
+func NewStreamTickets(ttl time.Duration) *StreamTickets {
+	return &StreamTickets{ttl: ttl, tickets: make(map[string]issuedTicket)}
+}
+
+// Issue returns a new ticket for the user. Expired tickets are dropped.
+func (s *StreamTickets) Issue(userID string) (*domain.StreamTicket, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	ticket := &domain.StreamTicket{Ticket: base64.RawURLEncoding.EncodeToString(raw), ExpiresAt: time.Now().Add(s.ttl)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, issued := range s.tickets {
+		if !now.Before(issued.expiresAt) {
+			delete(s.tickets, hash)
+		}
+	}
+	s.tickets[hashToken(ticket.Ticket)] = issuedTicket{userID: userID, expiresAt: ticket.ExpiresAt}
+	return ticket, nil
+}
+
+// Redeem consumes a ticket and returns the ID of the user it was issued to.
+// It returns false for unknown, expired and already redeemed tickets.
+func (s *StreamTickets) Redeem(ticket string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := hashToken(ticket)
+	issued, ok := s.tickets[hash]
+	if !ok {
+		return "", false
+	}
+	delete(s.tickets, hash)
+	if !time.Now().Before(issued.expiresAt) {
+		return "", false
+	}
+	return issued.userID, true
+}
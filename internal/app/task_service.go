@@ -1,44 +1,776 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"todo-list-task/internal/domain"
 	"todo-list-task/internal/infrastructure/repository"
 )
 
+// maxPatchAttempts bounds how often an unconditional patch or completion is
+// retried when another update lands between reading and writing the task.
+const maxPatchAttempts = 3
+
+// TaskService manages tasks on behalf of a user. Users act on their own
+// tasks and, through lists shared with them, on the tasks of other users:
+// viewers can read them and editors can also change them. The repository
+// records the events of the changes, with the user as their actor, and
+// appends them to the audit log.
 type TaskService struct {
-	repo repository.TaskRepository
+	repo  repository.TaskRepository
+	lists repository.ListRepository
+	audit repository.AuditRepository
 }
 
-func NewTaskService(repo repository.TaskRepository) *TaskService {
-	return &TaskService{repo: repo}
+func NewTaskService(repo repository.TaskRepository, lists repository.ListRepository, audit repository.AuditRepository) *TaskService {
+	return &TaskService{repo: repo, lists: lists, audit: audit}
 }
 
-func (t TaskService) RegisterTask(task *domain.TaskRequest) (*domain.Task, error) {
+func (t TaskService) RegisterTask(userID string, task *domain.TaskRequest) (*domain.Task, error) {
+	now := time.Now().UTC()
 	taskSave := &domain.Task{
-		Title:       task.Title,
-		Description: task.Description,
-		ID:          uuid.NewString(),
+		Title:        task.Title,
+		Description:  task.Description,
+		ID:           uuid.NewString(),
+		OwnerID:      userID,
+		ListID:       task.ListID,
+		Tags:         task.Tags,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Version:      1,
+		UpdatedBy:    userID,
+		TaskSchedule: task.TaskSchedule,
+		TaskLinks:    task.TaskLinks,
+	}
+	if err := normalizeTask(taskSave); err != nil {
+		return nil, err
+	}
+	if task.ListID != "" {
+		list, err := t.writableList(userID, task.ListID)
+		if err != nil {
+			return nil, err
+		}
+		taskSave.OwnerID = list.OwnerID
+	}
+	if err := t.checkLinks(userID, taskSave); err != nil {
+		return nil, err
 	}
 	return t.repo.CreateTask(taskSave)
 }
 
-func (t TaskService) GetTask(id string) (*domain.Task, error) {
-	return t.repo.GetTask(id)
+func (t TaskService) GetTask(userID, id string) (*domain.Task, error) {
+	return t.authorizeTask(userID, id, domain.ListViewer)
+}
+
+// authorizeTask returns a task the user owns, or a task of a list shared
+// with the user with at least role. Tasks the user cannot see are reported
+// as not found.
+func (t TaskService) authorizeTask(userID, id, role string) (*domain.Task, error) {
+	task, err := t.repo.GetTask(userID, id)
+	if !errors.Is(err, domain.ErrTaskNotFound) {
+		return task, err
+	}
+
+	lists, err := t.lists.GetListsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		if list.OwnerID == userID {
+			continue
+		}
+		task, err := t.repo.GetTask(list.OwnerID, id)
+		if errors.Is(err, domain.ErrTaskNotFound) || err == nil && task.ListID != list.ID {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !list.Allows(userID, role) {
+			return nil, domain.ErrListReadOnly
+		}
+		return task, nil
+	}
+	return nil, domain.ErrTaskNotFound
+}
+
+// visibleList returns a list the user owns or is a member of.
+func (t TaskService) visibleList(userID, listID string) (*domain.List, error) {
+	list, err := t.lists.GetList(listID)
+	if err != nil {
+		return nil, err
+	}
+	if list.RoleOf(userID) == "" {
+		return nil, domain.ErrListNotFound
+	}
+	return list, nil
+}
+
+// writableList returns the list a user puts a task in, which the user must
+// own or edit. Lists the user cannot see are reported as unknown.
+func (t TaskService) writableList(userID, listID string) (*domain.List, error) {
+	list, err := t.visibleList(userID, listID)
+	if errors.Is(err, domain.ErrListNotFound) {
+		return nil, domain.NewError(domain.ErrValidation, fmt.Sprintf("unknown list %q", listID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !list.Allows(userID, domain.ListEditor) {
+		return nil, domain.ErrListReadOnly
+	}
+	return list, nil
+}
+
+// checkMove validates the list a task moves to. Tasks never change owner, so
+// the list must belong to the task owner, and members cannot move a task out
+// of the lists shared with them.
+func (t TaskService) checkMove(userID string, task *domain.Task) error {
+	if task.ListID == "" {
+		if userID != task.OwnerID {
+			return domain.ErrNotListOwner
+		}
+		return nil
+	}
+	list, err := t.writableList(userID, task.ListID)
+	if err != nil {
+		return err
+	}
+	if list.OwnerID != task.OwnerID {
+		return domain.NewError(domain.ErrValidation, "a task can only move to a list of its owner")
+	}
+	return nil
+}
+func (t TaskService) GetTasks(userID string) ([]*domain.Task, error) {
+	return t.repo.GetTasks(userID)
+}
+
+// GetSubtree returns a task with its subtasks, recursively.
+func (t TaskService) GetSubtree(userID, id string) (*domain.TaskTree, error) {
+	task, err := t.authorizeTask(userID, id, domain.ListViewer)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := t.visibleGraph(userID, task)
+	if err != nil {
+		return nil, err
+	}
+	return graph.Subtree(id), nil
+}
+
+// GetBlockers returns the tasks that directly block a task.
+func (t TaskService) GetBlockers(userID, id string) ([]*domain.Task, error) {
+	task, err := t.authorizeTask(userID, id, domain.ListViewer)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := t.visibleGraph(userID, task)
+	if err != nil {
+		return nil, err
+	}
+	return graph.Blockers(id), nil
+}
+
+// taskGraph loads the links between the tasks of an owner.
+func (t TaskService) taskGraph(ownerID string) (*domain.TaskGraph, error) {
+	tasks, err := t.repo.GetTasks(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTaskGraph(tasks), nil
+}
+
+// visibleGraph loads the links between the tasks the user can see next to
+// task: all the tasks of its owner, or only those of its list for a member.
+func (t TaskService) visibleGraph(userID string, task *domain.Task) (*domain.TaskGraph, error) {
+	tasks, err := t.repo.GetTasks(task.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != task.OwnerID {
+		tasks = slices.DeleteFunc(tasks, func(other *domain.Task) bool { return other.ListID != task.ListID })
+	}
+	return domain.NewTaskGraph(tasks), nil
+}
+
+// checkLinks validates the parent and blockers of task against the other
+// tasks of its owner, so cycles and blocked completions never reach the
+// repository. Members of a shared list can only link tasks of that list.
+// Tasks without links need no lookup.
+func (t TaskService) checkLinks(userID string, task *domain.Task) error {
+	if !task.HasLinks() {
+		return nil
+	}
+	graph, err := t.taskGraph(task.OwnerID)
+	if err != nil {
+		return err
+	}
+	if err := graph.Check(task); err != nil {
+		return err
+	}
+	if userID == task.OwnerID {
+		return nil
+	}
+	for _, id := range append([]string{task.ParentID}, task.BlockedBy...) {
+		if linked := graph.Get(id); linked != nil && linked.ListID != task.ListID {
+			return domain.NewError(domain.ErrValidation, fmt.Sprintf("unknown task %q", id))
+		}
+	}
+	return nil
+}
+
+// QueryTasks returns one page of the user's tasks matching query. A query
+// for a list shared with the user returns the tasks of that list.
+func (t TaskService) QueryTasks(userID string, query domain.TaskQuery) (*domain.TaskPage, error) {
+	query.OwnerID = userID
+	if query.ListID != "" {
+		list, err := t.visibleList(userID, query.ListID)
+		if err != nil {
+			return nil, err
+		}
+		query.OwnerID = list.OwnerID
+	}
+	tags, err := domain.NormalizeTags(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+	query.Tags = tags
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	return t.repo.QueryTasks(query)
 }
-func (t TaskService) GetTasks() ([]*domain.Task, error) {
-	return t.repo.GetTasks()
+
+// GetTags returns the tags of the user's tasks with the number of tasks
+// carrying each one, most used first.
+func (t TaskService) GetTags(userID string) ([]domain.TagCount, error) {
+	return t.repo.GetTagCounts(userID)
 }
 
-func (t TaskService) UpdateTaskByID(id string, task domain.TaskRequest) (*domain.Task, error) {
+// UpdateTaskByID replaces a task. A non-zero version makes the update
+// conditional on the task still being at that version. Completing a
+// recurring task also creates its next occurrence.
+func (t TaskService) UpdateTaskByID(userID, id string, version int64, task domain.TaskRequest) (*domain.Task, error) {
 	taskSave := &domain.Task{
-		Title:       task.Title,
-		Description: task.Description,
-		Completed:   true,
+		Title:        task.Title,
+		Description:  task.Description,
+		Completed:    task.Completed,
+		OwnerID:      userID,
+		ListID:       task.ListID,
+		Tags:         task.Tags,
+		UpdatedAt:    time.Now().UTC(),
+		Version:      version,
+		UpdatedBy:    userID,
+		TaskSchedule: task.TaskSchedule,
+		TaskLinks:    task.TaskLinks,
 	}
-	return t.repo.UpdateTask(id, taskSave)
+	if err := normalizeTask(taskSave); err != nil {
+		return nil, err
+	}
+	current, err := t.authorizeTask(userID, id, domain.ListEditor)
+	if err != nil {
+		return nil, err
+	}
+	if (!task.Completed || task.Recurrence == "") && !taskSave.HasLinks() && taskSave.ListID == current.ListID {
+		taskSave.OwnerID = current.OwnerID
+		return t.repo.UpdateTask(current.OwnerID, id, taskSave)
+	}
+
+	return t.updateTask(userID, id, version, "", func(current *domain.Task) (*domain.Task, error) {
+		next := *taskSave
+		next.ID = current.ID
+		next.CreatedAt = current.CreatedAt
+		return &next, nil
+	})
 }
 
-func (t TaskService) DeleteTaskByID(id string) error {
-	return t.repo.DeleteTask(id)
+// PatchTaskByID applies a partial update to a task, leaving the fields the
+// patch does not mention untouched. A non-zero version makes the patch
+// conditional on the task still being at that version; without one the
+// patch is applied to the latest version. Completing a recurring task also
+// creates its next occurrence.
+func (t TaskService) PatchTaskByID(userID, id string, version int64, patch domain.TaskPatch) (*domain.Task, error) {
+	return t.updateTask(userID, id, version, "", patch.Apply)
+}
+
+// updateTask reads a task, computes its new state with change and stores it
+// on condition that nobody updated it in between. A non-zero version must
+// match the task read; without one a conflicting write is retried on the
+// latest version. The list and links of the new state are checked before
+// saving. The change is audited as action, or as the action derived from it
+// when empty.
+func (t TaskService) updateTask(userID, id string, version int64, action string, change func(current *domain.Task) (*domain.Task, error)) (*domain.Task, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := t.authorizeTask(userID, id, domain.ListEditor)
+		if err != nil {
+			return nil, err
+		}
+		if version != 0 && current.Version != version {
+			return nil, domain.ErrVersionConflict
+		}
+
+		next, err := change(current)
+		if err != nil {
+			return nil, err
+		}
+		if next.Tags, err = domain.NormalizeTags(next.Tags); err != nil {
+			return nil, err
+		}
+		next.OwnerID = current.OwnerID
+		next.Version = current.Version
+		next.UpdatedAt = time.Now().UTC()
+		next.UpdatedBy = userID
+		if next.ListID != current.ListID {
+			if err := t.checkMove(userID, next); err != nil {
+				return nil, err
+			}
+		}
+		if err := t.checkLinks(userID, next); err != nil {
+			return nil, err
+		}
+
+		updated, err := t.saveTask(userID, action, current, next)
+		if errors.Is(err, domain.ErrVersionConflict) && version == 0 && attempt < maxPatchAttempts {
+			continue
+		}
+		return updated, err
+	}
+}
+
+// saveTask stores next as the new state of current, at the version of
+// current, on behalf of the user. When next completes a recurring task its
+// next occurrence is created in the same atomic change, so a series never
+// loses or duplicates an occurrence. A change with an explicit audit action
+// goes through a batch, the write that carries it.
+func (t TaskService) saveTask(userID, action string, current, next *domain.Task) (*domain.Task, error) {
+	if _, recurs := next.NextOccurrence(); action == "" && (current.Completed || !next.Completed || !recurs) {
+		return t.repo.UpdateTask(current.OwnerID, current.ID, next)
+	}
+
+	results, err := t.repo.ApplyTaskChanges(current.OwnerID, []domain.TaskChange{{
+		Op: domain.BatchUpdate, ID: current.ID, Version: current.Version, Task: next, At: next.UpdatedAt, ActorID: userID, OccurrenceID: uuid.NewString(), Action: action,
+	}}, true)
+	if err != nil {
+		var batchErr *domain.BatchError
+		if errors.As(err, &batchErr) {
+			return nil, batchErr.Err
+		}
+		return nil, err
+	}
+	return results[0].Task, nil
+}
+
+// ExecuteBatch runs a batch of task operations for the user in a single
+// repository call. In atomic mode the batch fails as a whole with a
+// *domain.BatchError; otherwise every operation gets its own result. Links
+// are checked in order, so an operation sees the links and completions of
+// the operations before it. A batch only works on the user's own tasks and
+// lists.
+func (t TaskService) ExecuteBatch(userID string, request domain.TaskBatchRequest) ([]domain.TaskChangeResult, error) {
+	now := time.Now().UTC()
+	changes := make([]domain.TaskChange, len(request.Operations))
+	invalid := make(map[int]error)
+
+	var graph *domain.TaskGraph
+	if batchNeedsGraph(request.Operations) {
+		var err error
+		if graph, err = t.taskGraph(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, operation := range request.Operations {
+		change, err := batchChange(userID, operation, now)
+		if err == nil && operation.Task != nil {
+			err = t.checkOwnList(userID, operation.Task.ListID)
+		}
+		if err == nil && graph != nil {
+			err = stageLinks(graph, userID, change)
+		}
+		if err != nil {
+			if request.Atomic {
+				return nil, &domain.BatchError{Index: i, Err: err}
+			}
+			invalid[i] = err
+		}
+		changes[i] = change
+	}
+
+	if len(invalid) == 0 {
+		return t.repo.ApplyTaskChanges(userID, changes, request.Atomic)
+	}
+
+	valid := make([]domain.TaskChange, 0, len(changes)-len(invalid))
+	for i, change := range changes {
+		if _, ok := invalid[i]; !ok {
+			valid = append(valid, change)
+		}
+	}
+	applied, err := t.repo.ApplyTaskChanges(userID, valid, false)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.TaskChangeResult, len(changes))
+	for i := range results {
+		if err, ok := invalid[i]; ok {
+			results[i].Err = err
+			continue
+		}
+		results[i], applied = applied[0], applied[1:]
+	}
+	return results, nil
+}
+
+// checkOwnList checks that a batch operation only puts tasks in lists of
+// the user.
+func (t TaskService) checkOwnList(userID, listID string) error {
+	if listID == "" {
+		return nil
+	}
+	list, err := t.visibleList(userID, listID)
+	if errors.Is(err, domain.ErrListNotFound) {
+		return domain.NewError(domain.ErrValidation, fmt.Sprintf("unknown list %q", listID))
+	}
+	if err != nil {
+		return err
+	}
+	if list.OwnerID != userID {
+		return domain.NewError(domain.ErrValidation, "batch operations only work on the user's own lists")
+	}
+	return nil
+}
+
+// batchNeedsGraph reports whether any operation sets links or completes a
+// task, which may have blockers.
+func batchNeedsGraph(operations []domain.TaskBatchOperation) bool {
+	for _, operation := range operations {
+		if operation.Op == domain.BatchComplete || operation.Task != nil && operation.Task.HasLinks() {
+			return true
+		}
+	}
+	return false
+}
+
+// stageLinks checks the links of change against graph and records its
+// outcome there. Missing tasks and version conflicts are left for the
+// repository to report.
+func stageLinks(graph *domain.TaskGraph, userID string, change domain.TaskChange) error {
+	next, err := change.Apply(userID, graph.Get(change.ID))
+	if err != nil {
+		return nil
+	}
+	if next == nil {
+		graph.Remove(change.ID)
+		return nil
+	}
+	if err := graph.Check(next); err != nil {
+		return err
+	}
+	graph.Put(next)
+	return nil
+}
+
+// batchChange validates a batch operation and turns it into a repository change.
+func batchChange(userID string, operation domain.TaskBatchOperation, now time.Time) (domain.TaskChange, error) {
+	change := domain.TaskChange{Op: operation.Op, ID: operation.ID, Version: operation.Version, At: now, ActorID: userID, OccurrenceID: uuid.NewString()}
+
+	if operation.Op == domain.BatchCreate {
+		if operation.Task == nil {
+			return change, domain.NewError(domain.ErrValidation, "create requires a task")
+		}
+		change.ID = uuid.NewString()
+		change.Version = 0
+		change.Task = &domain.Task{
+			ID:           change.ID,
+			OwnerID:      userID,
+			Title:        operation.Task.Title,
+			Description:  operation.Task.Description,
+			Completed:    operation.Task.Completed,
+			ListID:       operation.Task.ListID,
+			Tags:         operation.Task.Tags,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Version:      1,
+			UpdatedBy:    userID,
+			TaskSchedule: operation.Task.TaskSchedule,
+			TaskLinks:    operation.Task.TaskLinks,
+		}
+		return change, normalizeTask(change.Task)
+	}
+
+	if operation.ID == "" {
+		return change, domain.NewError(domain.ErrValidation, operation.Op+" requires an id")
+	}
+	if operation.Op == domain.BatchUpdate {
+		if operation.Task == nil {
+			return change, domain.NewError(domain.ErrValidation, "update requires a task")
+		}
+		change.Task = &domain.Task{
+			Title:        operation.Task.Title,
+			Description:  operation.Task.Description,
+			Completed:    operation.Task.Completed,
+			ListID:       operation.Task.ListID,
+			Tags:         operation.Task.Tags,
+			TaskSchedule: operation.Task.TaskSchedule,
+			TaskLinks:    operation.Task.TaskLinks,
+		}
+		return change, normalizeTask(change.Task)
+	}
+	return change, nil
+}
+
+// normalizeTask normalizes the tags, schedule and links of a new task state.
+func normalizeTask(task *domain.Task) error {
+	tags, err := domain.NormalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	task.Tags = tags
+	if err := task.TaskSchedule.Normalize(); err != nil {
+		return err
+	}
+	return task.TaskLinks.Normalize()
+}
+
+// ExportTasks writes every task of the user to enc, oldest first, as the
+// repository scans them. begin is called once the first task has been read,
+// or the scan has ended, and before anything is written, so an error reading
+// the tasks can still be reported in place of the export.
+func (t TaskService) ExportTasks(userID string, enc domain.TaskEncoder, begin func()) error {
+	begun := false
+	err := t.repo.ScanTasks(userID, func(task *domain.Task) error {
+		if !begun {
+			begin()
+			begun = true
+		}
+		return enc.Encode(task)
+	})
+	if err != nil {
+		return err
+	}
+	if !begun {
+		begin()
+	}
+	return enc.Close()
+}
+
+// ImportTasks creates a task for the user from every valid record of dec.
+// Imported tasks get new IDs and stay out of lists, so their list, parent
+// and blockers are dropped; their creation time is kept when present.
+// Records are stored in batches as they are read; in a dry run they are only
+// validated. Invalid records are reported and skipped.
+func (t TaskService) ImportTasks(userID string, dec domain.TaskDecoder, dryRun bool) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{DryRun: dryRun, Errors: []domain.ImportError{}}
+	now := time.Now().UTC()
+
+	var (
+		changes []domain.TaskChange
+		records []int
+	)
+	flush := func() error {
+		if len(changes) == 0 {
+			return nil
+		}
+		results, err := t.repo.ApplyTaskChanges(userID, changes, false)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				report.Reject(records[i], result.Err)
+				continue
+			}
+			report.Imported++
+		}
+		changes, records = changes[:0], records[:0]
+		return nil
+	}
+
+	for {
+		task, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, domain.ErrInvalidRecord) {
+			return nil, err
+		}
+		report.Total++
+		if err == nil {
+			err = validateImportedTask(task)
+		}
+		if err != nil {
+			report.Reject(report.Total, err)
+			continue
+		}
+		if dryRun {
+			report.Imported++
+			continue
+		}
+
+		imported := &domain.Task{
+			ID:           uuid.NewString(),
+			OwnerID:      userID,
+			Title:        task.Title,
+			Description:  task.Description,
+			Completed:    task.Completed,
+			Tags:         task.Tags,
+			CreatedAt:    task.CreatedAt,
+			UpdatedAt:    now,
+			Version:      1,
+			UpdatedBy:    userID,
+			TaskSchedule: task.TaskSchedule,
+		}
+		if imported.CreatedAt.IsZero() {
+			imported.CreatedAt = now
+		}
+		changes = append(changes, domain.TaskChange{Op: domain.BatchCreate, ID: imported.ID, Task: imported, ActorID: userID})
+		records = append(records, report.Total)
+		if len(changes) == domain.MaxBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// validateImportedTask applies the rules of TaskRequest to an imported record.
+func validateImportedTask(task *domain.Task) error {
+	if strings.TrimSpace(task.Title) == "" {
+		return domain.NewError(domain.ErrValidation, "title is required")
+	}
+	if strings.TrimSpace(task.Description) == "" {
+		return domain.NewError(domain.ErrValidation, "description is required")
+	}
+	tags, err := domain.NormalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	task.Tags = tags
+	return task.TaskSchedule.Normalize()
+}
+
+func (t TaskService) DeleteTaskByID(userID, id string) error {
+	task, err := t.authorizeTask(userID, id, domain.ListEditor)
+	if err != nil {
+		return err
+	}
+	return t.repo.DeleteTask(task.OwnerID, id, userID)
+}
+
+// GetTrash returns the user's deleted tasks, most recently deleted first.
+// Tasks of the user deleted by members of the user's lists are included.
+func (t TaskService) GetTrash(userID string) ([]*domain.Task, error) {
+	return t.repo.GetTrash(userID)
+}
+
+// RestoreTask brings one of the user's tasks back from the trash. A task
+// whose list was deleted in the meantime comes back out of any list.
+func (t TaskService) RestoreTask(userID, id string) (*domain.Task, error) {
+	trash, err := t.repo.GetTrash(userID)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(trash, func(task *domain.Task) bool { return task.ID == id })
+	if index < 0 {
+		return nil, domain.ErrTaskNotFound
+	}
+	restored, err := t.repo.RestoreTask(userID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if restored.ListID == "" {
+		return restored, nil
+	}
+	if _, err := t.lists.GetList(restored.ListID); !errors.Is(err, domain.ErrListNotFound) {
+		if err != nil {
+			return nil, err
+		}
+		return restored, nil
+	}
+	return t.updateTask(userID, id, restored.Version, "", func(current *domain.Task) (*domain.Task, error) {
+		next := *current
+		next.ListID = ""
+		return &next, nil
+	})
+}
+
+// GetTaskHistory returns one page of the audit entries of a task the user
+// can see matching query, newest first. The history of a deleted task stays
+// available to its owner.
+func (t TaskService) GetTaskHistory(userID, id string, query domain.AuditQuery) (*domain.AuditPage, error) {
+	query.TaskID, query.OwnerID = id, ""
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	_, err := t.authorizeTask(userID, id, domain.ListViewer)
+	if !errors.Is(err, domain.ErrTaskNotFound) {
+		if err != nil {
+			return nil, err
+		}
+		return t.audit.QueryAudit(query)
+	}
+
+	// A deleted task is only known by the entries of its owner, one of
+	// which is its deletion.
+	deleted, err := t.audit.QueryAudit(domain.AuditQuery{TaskID: id, OwnerID: userID, Action: domain.AuditDelete, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted.Entries) == 0 {
+		return nil, domain.ErrTaskNotFound
+	}
+	query.OwnerID = userID
+	return t.audit.QueryAudit(query)
+}
+
+// RestoreTaskRevision brings a task back to its state at revision, as a new
+// revision made by the user. The restored state goes through the checks of
+// any update, and revisions that deleted the task cannot be restored.
+func (t TaskService) RestoreTaskRevision(userID, id string, revision int64) (*domain.Task, error) {
+	if _, err := t.authorizeTask(userID, id, domain.ListEditor); err != nil {
+		return nil, err
+	}
+	if revision <= 0 {
+		return nil, domain.ErrRevisionNotFound
+	}
+	page, err := t.audit.QueryAudit(domain.AuditQuery{TaskID: id, Revision: revision, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Entries) == 0 {
+		return nil, domain.ErrRevisionNotFound
+	}
+	restored := page.Entries[0].After
+	if restored == nil {
+		return nil, domain.NewError(domain.ErrValidation, fmt.Sprintf("revision %d deleted the task", revision))
+	}
+
+	return t.updateTask(userID, id, 0, domain.AuditRestore, func(current *domain.Task) (*domain.Task, error) {
+		next := *restored
+		next.ID = current.ID
+		next.CreatedAt = current.CreatedAt
+		return &next, nil
+	})
+}
+
+// GetAuditLog returns one page of the audit entries of every user matching
+// query, newest first.
+func (t TaskService) GetAuditLog(query domain.AuditQuery) (*domain.AuditPage, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	return t.audit.QueryAudit(query)
 }
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// streamSubscriberBuffer is how many events a subscriber may fall behind
+// before it is dropped.
+const streamSubscriberBuffer = 64
+
+// StreamEvent is a task event with its ID in the change stream.
+type StreamEvent struct {
+	ID    string
+	Event domain.TaskEvent
+}
+
+// TaskStream fans task events out, as they are published, to the users who
+// can see the task: its owner, the user who changed it and the members of
+// its list. The latest events are kept in a bounded replay buffer, so a
+// client that reconnects can resume after the last event it received.
+//
+// Event IDs are "<epoch>-<sequence>", where the epoch identifies the process;
+// IDs of another process or events that left the buffer cannot be resumed.
+// A task event published again while still in the buffer is dropped.
+type TaskStream struct {
+	lists repository.ListRepository
+	epoch string
+
+	mu sync.Mutex
+	// seq is the sequence number of the latest event.
+	seq uint64
+	// buffer is a ring of the latest events; next is where the next one goes.
+	buffer      []streamEntry
+	next        int
+	buffered    map[string]struct{}
+	subscribers map[*TaskSubscription]struct{}
+}
+
+type streamEntry struct {
+	seq      uint64
+	audience []string
+	event    domain.TaskEvent
+}
+
+func NewTaskStream(lists repository.ListRepository, bufferSize int) *TaskStream {
+	return &TaskStream{
+		lists:       lists,
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		buffer:      make([]streamEntry, 0, bufferSize),
+		buffered:    make(map[string]struct{}, bufferSize),
+		subscribers: make(map[*TaskSubscription]struct{}),
+	}
+}
+
+// TaskSubscription receives the task events visible to one user. Its channel
+// is closed when the subscription is closed or when the subscriber fell more
+// than streamSubscriberBuffer events behind; such a subscriber should
+// subscribe again from its last event.
+type TaskSubscription struct {
+	stream *TaskStream
+	userID string
+	events chan StreamEvent
+}
+
+// Events returns the channel the events are delivered on.
+func (s *TaskSubscription) Events() <-chan StreamEvent {
+	return s.events
+}
+
+// Close ends the subscription. It may be called more than once.
+func (s *TaskSubscription) Close() {
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+
+	s.stream.drop(s)
+}
+
+// Handle records an event in the replay buffer and delivers it to the
+// subscribers who can see it. It is subscribed to the event bus.
+func (s *TaskStream) Handle(event domain.TaskEvent) {
+	audience := s.audience(event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.buffered[event.ID]; ok {
+		return
+	}
+	s.seq++
+	entry := streamEntry{seq: s.seq, audience: audience, event: event}
+	if len(s.buffer) < cap(s.buffer) {
+		s.buffer = append(s.buffer, entry)
+		s.buffered[event.ID] = struct{}{}
+	} else if cap(s.buffer) > 0 {
+		delete(s.buffered, s.buffer[s.next].event.ID)
+		s.buffer[s.next] = entry
+		s.buffered[event.ID] = struct{}{}
+		s.next = (s.next + 1) % cap(s.buffer)
+	}
+
+	streamEvent := StreamEvent{ID: s.eventID(entry.seq), Event: event}
+	for subscriber := range s.subscribers {
+		if !slices.Contains(audience, subscriber.userID) {
+			continue
+		}
+		select {
+		case subscriber.events <- streamEvent:
+		default:
+			s.drop(subscriber)
+		}
+	}
+}
+
+// audience returns the users who can see the task of an event. When the
+// list cannot be read, only the owner and the actor get the event.
+func (s *TaskStream) audience(event domain.TaskEvent) []string {
+	audience := []string{event.OwnerID}
+	if event.ActorID != event.OwnerID {
+		audience = append(audience, event.ActorID)
+	}
+	if event.ListID == "" {
+		return audience
+	}
+	list, err := s.lists.GetList(event.ListID)
+	if err != nil {
+		log.Printf("Error al leer la lista %s de la tarea %s: %v", event.ListID, event.TaskID, err)
+		return audience
+	}
+	for _, member := range list.Members {
+		if !slices.Contains(audience, member.UserID) {
+			audience = append(audience, member.UserID)
+		}
+	}
+	return audience
+}
+
+// Subscribe subscribes the user to the stream. With a lastEventID, the
+// buffered events visible to the user that followed it are returned to be
+// replayed before the live ones, with no gap between them. Access to the
+// replayed events is checked again, so the events of a list the user has
+// left since they were published are not replayed. resumed is false when
+// lastEventID is unknown or too old for the buffer, so events may have been
+// missed and the client should reload its tasks.
+func (s *TaskStream) Subscribe(userID, lastEventID string) (subscription *TaskSubscription, replay []StreamEvent, resumed bool) {
+	subscription, entries, resumed := s.subscribe(userID, lastEventID)
+
+	lists := make(map[string]*domain.List)
+	for _, entry := range entries {
+		if s.canSee(userID, entry.event, lists) {
+			replay = append(replay, StreamEvent{ID: s.eventID(entry.seq), Event: entry.event})
+		}
+	}
+	return subscription, replay, resumed
+}
+
+// subscribe registers the subscription and returns the buffered entries
+// after lastEventID whose audience included the user when they were published.
+func (s *TaskStream) subscribe(userID, lastEventID string) (*TaskSubscription, []streamEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription := &TaskSubscription{stream: s, userID: userID, events: make(chan StreamEvent, streamSubscriberBuffer)}
+	s.subscribers[subscription] = struct{}{}
+	if lastEventID == "" {
+		return subscription, nil, true
+	}
+
+	last, ok := s.parseEventID(lastEventID)
+	oldest := s.seq - uint64(len(s.buffer)) + 1
+	if !ok || last > s.seq || last+1 < oldest {
+		return subscription, nil, false
+	}
+	var entries []streamEntry
+	for i := range s.buffer {
+		entry := s.buffer[(s.next+i)%len(s.buffer)]
+		if entry.seq > last && slices.Contains(entry.audience, userID) {
+			entries = append(entries, entry)
+		}
+	}
+	return subscription, entries, true
+}
+
+// canSee reports whether the user can still see the task of an event: the
+// owner and the actor always can, the members of its list while they remain
+// members. Lists are read once through the lists cache; a list that cannot
+// be read grants nothing.
+func (s *TaskStream) canSee(userID string, event domain.TaskEvent, lists map[string]*domain.List) bool {
+	if userID == event.OwnerID || userID == event.ActorID {
+		return true
+	}
+	if event.ListID == "" {
+		return false
+	}
+	list, ok := lists[event.ListID]
+	if !ok {
+		var err error
+		if list, err = s.lists.GetList(event.ListID); err != nil {
+			if !errors.Is(err, domain.ErrListNotFound) {
+				log.Printf("Error al leer la lista %s de la tarea %s: %v", event.ListID, event.TaskID, err)
+			}
+			list = nil
+		}
+		lists[event.ListID] = list
+	}
+	return list != nil && slices.ContainsFunc(list.Members, func(member domain.ListMember) bool { return member.UserID == userID })
+}
+
+// drop removes a subscriber and closes its channel. The caller holds mu.
+func (s *TaskStream) drop(subscription *TaskSubscription) {
+	if _, ok := s.subscribers[subscription]; !ok {
+		return
+	}
+	delete(s.subscribers, subscription)
+	close(subscription.events)
+}
+
+func (s *TaskStream) eventID(seq uint64) string {
+	return fmt.Sprintf("%s-%d", s.epoch, seq)
+}
+
+// parseEventID returns the sequence number of an event ID of this process.
+func (s *TaskStream) parseEventID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != s.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
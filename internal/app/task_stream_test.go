@@ -0,0 +1,116 @@
+package app_test
+
+import (
+	"testing"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func taskEvent(eventType, ownerID, actorID, listID, taskID string) domain.TaskEvent {
+	return domain.TaskEvent{ID: "event-" + taskID, Type: eventType, OwnerID: ownerID, ActorID: actorID, ListID: listID, TaskID: taskID}
+}
+
+func receivedTasks(subscription *app.TaskSubscription) []string {
+	var tasks []string
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return append(tasks, "closed")
+			}
+			tasks = append(tasks, event.Event.TaskID)
+		default:
+			return tasks
+		}
+	}
+}
+
+func TestTaskStream_Audience(t *testing.T) {
+	lists := memory.NewInMemoryListRepository()
+	_, err := lists.CreateList(&domain.List{ID: "list-1", OwnerID: "ana", Members: []domain.ListMember{{UserID: "luis", Role: domain.ListViewer}}})
+	require.NoError(t, err)
+	stream := app.NewTaskStream(lists, 10)
+
+	ana, _, _ := stream.Subscribe("ana", "")
+	luis, _, _ := stream.Subscribe("luis", "")
+	eva, _, _ := stream.Subscribe("eva", "")
+
+	stream.Handle(taskEvent(domain.TaskCreated, "ana", "ana", "", "private"))
+	stream.Handle(taskEvent(domain.TaskUpdated, "ana", "eva", "list-1", "shared"))
+	stream.Handle(taskEvent(domain.TaskDeleted, "eva", "eva", "", "other"))
+
+	assert.Equal(t, []string{"private", "shared"}, receivedTasks(ana))
+	assert.Equal(t, []string{"shared"}, receivedTasks(luis))
+	assert.Equal(t, []string{"shared", "other"}, receivedTasks(eva), "the actor sees the changes it made")
+
+	luis.Close()
+	luis.Close()
+	stream.Handle(taskEvent(domain.TaskUpdated, "ana", "ana", "list-1", "shared"))
+	assert.Equal(t, []string{"closed"}, receivedTasks(luis))
+}
+
+func TestTaskStream_Resume(t *testing.T) {
+	stream := app.NewTaskStream(memory.NewInMemoryListRepository(), 3)
+	first, _, _ := stream.Subscribe("ana", "")
+	for _, id := range []string{"1", "2", "3", "4"} {
+		stream.Handle(taskEvent(domain.TaskCreated, "ana", "ana", "", id))
+	}
+	stream.Handle(taskEvent(domain.TaskCreated, "eva", "eva", "", "5"))
+	var ids []string
+	for range 4 {
+		ids = append(ids, (<-first.Events()).ID)
+	}
+
+	_, replay, resumed := stream.Subscribe("ana", ids[1])
+	assert.True(t, resumed)
+	require.Len(t, replay, 2, "events of other users are not replayed")
+	assert.Equal(t, ids[2], replay[0].ID)
+	assert.Equal(t, "4", replay[1].Event.TaskID)
+
+	_, replay, resumed = stream.Subscribe("ana", ids[3])
+	assert.True(t, resumed)
+	assert.Empty(t, replay)
+
+	_, _, resumed = stream.Subscribe("ana", ids[0])
+	assert.False(t, resumed, "the event after it left the buffer")
+
+	_, _, resumed = stream.Subscribe("ana", "0-1")
+	assert.False(t, resumed, "an ID of another process")
+}
+
+func TestTaskStream_ResumeRechecksAccess(t *testing.T) {
+	lists := memory.NewInMemoryListRepository()
+	_, err := lists.CreateList(&domain.List{ID: "list-1", OwnerID: "ana", Members: []domain.ListMember{{UserID: "luis", Role: domain.ListViewer}}})
+	require.NoError(t, err)
+	stream := app.NewTaskStream(lists, 10)
+
+	luis, _, _ := stream.Subscribe("luis", "")
+	stream.Handle(taskEvent(domain.TaskCreated, "ana", "ana", "list-1", "first"))
+	last := (<-luis.Events()).ID
+	luis.Close()
+	stream.Handle(taskEvent(domain.TaskUpdated, "ana", "ana", "list-1", "second"))
+	stream.Handle(taskEvent(domain.TaskUpdated, "ana", "luis", "list-1", "third"))
+
+	require.NoError(t, lists.RemoveMember("list-1", "luis"))
+	_, replay, resumed := stream.Subscribe("luis", last)
+	assert.True(t, resumed)
+	require.Len(t, replay, 1, "only the change luis made is replayed after leaving the list")
+	assert.Equal(t, "third", replay[0].Event.TaskID)
+}
+
+func TestTaskStream_DropsSlowSubscribers(t *testing.T) {
+	stream := app.NewTaskStream(memory.NewInMemoryListRepository(), 0)
+	slow, _, _ := stream.Subscribe("ana", "")
+
+	for range 100 {
+		stream.Handle(taskEvent(domain.TaskUpdated, "ana", "ana", "", "1"))
+	}
+
+	received := receivedTasks(slow)
+	assert.Equal(t, "closed", received[len(received)-1])
+	assert.Less(t, len(received), 100)
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/repository"
+	"todo-list-task/internal/utils"
+)
+
+// TokenService issues, rotates and revokes refresh tokens.
+type TokenService struct {
+	repo       repository.RefreshTokenRepository
+	jwt        *utils.JWTManager
+	refreshTTL time.Duration
+}
+
+func NewTokenService(repo repository.RefreshTokenRepository, jwt *utils.JWTManager, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		repo:       repo,
+		jwt:        jwt,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// IssueRefreshToken starts a new refresh token family for the subject of accessToken.
+func (s TokenService) IssueRefreshToken(accessToken string) (string, error) {
+	parsed, err := s.jwt.ValidateJWT(accessToken)
+	if err != nil {
+		return "", err
+	}
+	claims := parsed.(*utils.Claims)
+
+	return s.issue(uuid.NewString(), claims.UserID, claims.Username, claims.Role)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token of the same family. Presenting a token that was already rotated is
+// treated as theft: the whole family is revoked.
+func (s TokenService) Refresh(refreshToken string) (*domain.UserResponse, error) {
+	stored, err := s.repo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	if err := s.repo.MarkRotated(stored.ID); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			if revokeErr := s.repo.RevokeFamily(stored.FamilyID); revokeErr != nil {
+				return nil, revokeErr
+			}
+		}
+		return nil, err
+	}
+
+	accessToken, err := s.jwt.GenerateJWT(stored.UserID, stored.Username, stored.Role)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.issue(stored.FamilyID, stored.UserID, stored.Username, stored.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserResponse{Token: accessToken, RefreshToken: rotated}, nil
+}
+
+// Revoke invalidates a refresh token and every token rotated from the same login.
+func (s TokenService) Revoke(refreshToken string) error {
+	stored, err := s.repo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	return s.repo.RevokeFamily(stored.FamilyID)
+}
+
+// RevokeUser invalidates every refresh token of a user.
+func (s TokenService) RevokeUser(userID string) error {
+	return s.repo.RevokeUser(userID)
+}
+
+func (s TokenService) issue(familyID, userID, username, role string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err := s.repo.Create(&domain.RefreshToken{
+		ID:        uuid.NewString(),
+		FamilyID:  familyID,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// TrashPurger periodically removes for good the tasks that have been in the
+// trash for longer than the retention window.
+type TrashPurger struct {
+	trash     repository.TaskTrash
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashPurger(trash repository.TaskTrash, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{trash: trash, retention: retention, interval: interval}
+}
+
+// Run calls Purge every interval until ctx is cancelled.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			purged, err := p.Purge(now.UTC())
+			if err != nil {
+				log.Printf("Error al vaciar la papelera: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Papelera: %d tareas eliminadas definitivamente", purged)
+			}
+		}
+	}
+}
+
+// Purge removes the tasks deleted more than the retention window before
+// now and returns how many it removed.
+func (p *TrashPurger) Purge(now time.Time) (int, error) {
+	return p.trash.PurgeTrash(now.Add(-p.retention))
+}
@@ -0,0 +1,53 @@
+package app_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashPurger_Purge(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	trash := &mocks.TaskTrash{}
+	purger := app.NewTrashPurger(trash, 72*time.Hour, time.Hour)
+
+	trash.On("PurgeTrash", now.Add(-72*time.Hour)).Return(2, nil).Once()
+	purged, err := purger.Purge(now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	trash.On("PurgeTrash", now.Add(-71*time.Hour)).Return(0, errors.New("disk full")).Once()
+	_, err = purger.Purge(now.Add(time.Hour))
+	assert.EqualError(t, err, "disk full")
+	trash.AssertExpectations(t)
+}
+
+func TestTrashPurger_KeepsTasksWithinRetention(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	service := app.NewTaskService(repo, memory.NewInMemoryListRepository(), memory.NewInMemoryAuditRepository())
+	created, err := service.RegisterTask("user-1", &domain.TaskRequest{Title: "title", Description: "description"})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTaskByID("user-1", created.ID))
+
+	purger := app.NewTrashPurger(repo, time.Hour, time.Minute)
+	purged, err := purger.Purge(time.Now().UTC())
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	restored, err := service.RestoreTask("user-1", created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "title", restored.Title)
+
+	require.NoError(t, service.DeleteTaskByID("user-1", created.ID))
+	purged, err = purger.Purge(time.Now().UTC().Add(time.Hour + time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = service.RestoreTask("user-1", created.ID)
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+}
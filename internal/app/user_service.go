@@ -1,31 +1,128 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/google/uuid"
 	"todo-list-task/internal/domain"
 	"todo-list-task/internal/infrastructure/repository"
 )
 
 type UserService struct {
-	repo repository.UserRepository
+	repo   repository.UserRepository
+	tokens *TokenService
 }
 
-func NewUserService(repo repository.UserRepository) *UserService {
+// NewUserService builds a UserService.
+func NewUserService(repo repository.UserRepository, tokens *TokenService) *UserService {
 	return &UserService{
-		repo: repo,
+		repo:   repo,
+		tokens: tokens,
 	}
 }
 
+// Register creates an account with the user role. Admins are only created
+// by BootstrapAdmin or promoted by another admin.
 func (u UserService) Register(user *domain.UserRequest) (string, error) {
 	saveUser := &domain.User{
 		Username: user.Username,
 		Password: user.Password,
 		ID:       uuid.NewString(),
+		Role:     domain.RoleUser,
 	}
 	return u.repo.Create(saveUser)
 }
 
-func (u UserService) Login(user domain.User) (string, error) {
+// BootstrapAdmin creates the admin account configured at startup. It is a
+// no-op when the account already exists as an admin, and fails when the
+// username is held by an account without that role, which is never
+// promoted implicitly.
+func (u UserService) BootstrapAdmin(username, password string) error {
+	_, err := u.repo.Create(&domain.User{
+		Username: username,
+		Password: password,
+		ID:       uuid.NewString(),
+		Role:     domain.RoleAdmin,
+	})
+	if !errors.Is(err, domain.ErrUsernameTaken) {
+		return err
+	}
+
+	existing, err := u.repo.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	if existing.Role == domain.RoleAdmin {
+		return nil
+	}
+	return fmt.Errorf("username %q is taken by an account without the admin role", username)
+}
+
+// Login authenticates the user and returns an access token plus a refresh token.
+func (u UserService) Login(user domain.User) (*domain.UserResponse, error) {
+	token, err := u.repo.Login(user)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := u.tokens.IssueRefreshToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// Refresh rotates a refresh token.
+func (u UserService) Refresh(refreshToken string) (*domain.UserResponse, error) {
+	return u.tokens.Refresh(refreshToken)
+}
+
+// Logout revokes a refresh token.
+func (u UserService) Logout(refreshToken string) error {
+	return u.tokens.Revoke(refreshToken)
+}
+
+// ListUsers returns every registered user without password hashes.
+func (u UserService) ListUsers() ([]*domain.UserInfo, error) {
+	users, err := u.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*domain.UserInfo, 0, len(users))
+	for _, user := range users {
+		infos = append(infos, &domain.UserInfo{
+			ID:       user.ID,
+			Username: user.Username,
+			Role:     user.Role,
+			Disabled: user.Disabled,
+		})
+	}
+	return infos, nil
+}
 
-	return u.repo.Login(user)
+// SetUserRole changes the role of an account and revokes its refresh
+// tokens, so no token issued with the previous role can be renewed.
+func (u UserService) SetUserRole(id, role string) error {
+	if !domain.IsRole(role) {
+		return domain.ErrInvalidRole
+	}
+	if err := u.repo.SetRole(id, role); err != nil {
+		return err
+	}
+	return u.tokens.RevokeUser(id)
+}
+
+// SetUserDisabled enables or disables an account. Disabling also revokes
+// its refresh tokens so no new access tokens can be obtained.
+func (u UserService) SetUserDisabled(id string, disabled bool) error {
+	if err := u.repo.SetDisabled(id, disabled); err != nil {
+		return err
+	}
+	if disabled {
+		return u.tokens.RevokeUser(id)
+	}
+	return nil
 }
@@ -0,0 +1,49 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newUserService(t *testing.T) (*app.UserService, *memory.InMemoryUserRepository) {
+	jwt := utils.NewJWTManager("test-secret", time.Hour)
+	repo := memory.NewInMemoryUserRepository(utils.NewHashPassword(app.BcryptCrypto{}, bcrypt.MinCost), jwt)
+	tokens := app.NewTokenService(memory.NewInMemoryRefreshTokenRepository(), jwt, time.Hour)
+	return app.NewUserService(repo, tokens), repo
+}
+
+func TestUserService_BootstrapAdmin(t *testing.T) {
+	service, repo := newUserService(t)
+
+	require.NoError(t, service.BootstrapAdmin("root", "password"))
+	require.NoError(t, service.BootstrapAdmin("root", "password"), "restarts keep the existing admin")
+
+	_, err := service.Register(&domain.UserRequest{Username: "root", Password: "attacker1"})
+	assert.ErrorIs(t, err, domain.ErrUsernameTaken)
+
+	users, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, domain.RoleAdmin, users[0].Role)
+}
+
+func TestUserService_BootstrapAdminRefusesSquattedUsername(t *testing.T) {
+	service, repo := newUserService(t)
+	_, err := service.Register(&domain.UserRequest{Username: "root", Password: "attacker1"})
+	require.NoError(t, err)
+
+	assert.Error(t, service.BootstrapAdmin("root", "password"))
+
+	users, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, domain.RoleUser, users[0].Role)
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// Headers of a webhook delivery request.
+const (
+	// WebhookSignatureHeader carries the signature of the body and the time
+	// it was sent, see SignWebhookPayload.
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookEventHeader carries the type of the event.
+	WebhookEventHeader = "X-Webhook-Event"
+	// WebhookDeliveryHeader carries the delivery ID, which stays the same across retries.
+	WebhookDeliveryHeader = "X-Webhook-Delivery"
+)
+
+// webhookPollInterval is how often due retries are looked for.
+const webhookPollInterval = time.Second
+
+// WebhookDispatcher turns task events into deliveries to the webhooks of the
+// task owner and posts them. A delivery succeeds on a 2xx response; failed
+// attempts are retried with exponential backoff, and a delivery that fails
+// maxAttempts times is dead. Up to workers webhooks are sent to at once.
+type WebhookDispatcher struct {
+	repo        repository.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	workers     int
+	wake        chan struct{}
+}
+
+func NewWebhookDispatcher(repo repository.WebhookRepository, client *http.Client, maxAttempts int, backoff time.Duration, workers int) *WebhookDispatcher {
+	return &WebhookDispatcher{repo: repo, client: client, maxAttempts: maxAttempts, backoff: backoff, workers: max(workers, 1), wake: make(chan struct{}, 1)}
+}
+
+// Handle queues a delivery of event to every webhook of its owner subscribed
+// to its type, unless the event was already queued for the webhook. It is
+// subscribed to the event bus, so it only stores the deliveries and leaves
+// sending them to Run.
+func (d *WebhookDispatcher) Handle(event domain.TaskEvent) {
+	webhooks, err := d.repo.GetWebhooks(event.OwnerID)
+	if err != nil {
+		log.Printf("Error al leer los webhooks de %s: %v", event.OwnerID, err)
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error al serializar el evento %s: %v", event.ID, err)
+		return
+	}
+
+	queued := false
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event.Type) {
+			continue
+		}
+		err := d.repo.CreateDelivery(&domain.WebhookDelivery{
+			ID:        uuid.NewString(),
+			WebhookID: webhook.ID,
+			OwnerID:   webhook.OwnerID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+			Status:    domain.DeliveryPending,
+			CreatedAt: time.Now().UTC(),
+		})
+		if errors.Is(err, domain.ErrDeliveryExists) {
+			continue
+		}
+		if err != nil && !errors.Is(err, domain.ErrWebhookNotFound) {
+			log.Printf("Error al encolar la entrega del webhook %s: %v", webhook.ID, err)
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run sends deliveries as soon as they are queued, and retries failed ones
+// once their backoff has passed, until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+		if err := d.Tick(ctx, time.Now().UTC()); err != nil {
+			log.Printf("Error al entregar los webhooks: %v", err)
+		}
+	}
+}
+
+// Tick attempts every delivery due at now. The deliveries of a webhook are
+// sent one at a time, oldest first, while different webhooks are sent to
+// concurrently by a bounded pool of workers, so a slow receiver only delays
+// its own deliveries. Deliveries of webhooks deleted meanwhile are skipped,
+// and an attempt cut short by the cancellation of ctx is not counted.
+func (d *WebhookDispatcher) Tick(ctx context.Context, now time.Time) error {
+	deliveries, err := d.repo.GetDueDeliveries(now)
+	if err != nil {
+		return err
+	}
+
+	var order []string
+	byWebhook := make(map[string][]*domain.WebhookDelivery)
+	for _, delivery := range deliveries {
+		if _, ok := byWebhook[delivery.WebhookID]; !ok {
+			order = append(order, delivery.WebhookID)
+		}
+		byWebhook[delivery.WebhookID] = append(byWebhook[delivery.WebhookID], delivery)
+	}
+
+	jobs := make(chan []*domain.WebhookDelivery)
+	errs := make([]error, min(d.workers, len(order)))
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for queue := range jobs {
+				if err := d.send(ctx, queue, now); err != nil && errs[i] == nil {
+					errs[i] = err
+				}
+			}
+		}()
+	}
+	for _, webhookID := range order {
+		jobs <- byWebhook[webhookID]
+	}
+	close(jobs)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// send attempts the due deliveries of one webhook in order and stores the
+// outcome of each.
+func (d *WebhookDispatcher) send(ctx context.Context, deliveries []*domain.WebhookDelivery, now time.Time) error {
+	webhook, err := d.repo.GetWebhook(deliveries[0].OwnerID, deliveries[0].WebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		d.attempt(ctx, webhook, delivery, now)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := d.repo.UpdateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt posts a delivery once and records the outcome in it.
+func (d *WebhookDispatcher) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery, now time.Time) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+
+	status, err := d.post(ctx, webhook, delivery, now)
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status = domain.DeliverySucceeded
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = domain.DeliveryDead
+		return
+	}
+	next := now.Add(d.backoff << (delivery.Attempts - 1))
+	delivery.NextAttemptAt = &next
+}
+
+// post sends the payload of a delivery to its webhook and returns the status
+// of the response.
+func (d *WebhookDispatcher) post(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the signature header of a delivery body sent at
+// the given time: "t=<unix seconds>,sha256=<hex HMAC-SHA256>", where the HMAC
+// is computed over the timestamp, a dot and the body, keyed with the webhook
+// secret. Receivers recompute it to authenticate deliveries and reject old
+// timestamps to stop captured requests from being replayed.
+func SignWebhookPayload(secret string, at time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,228 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a webhook endpoint that answers with the given statuses in
+// turn, repeating the last one, and records the requests it got.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := r.statuses[0]
+	if len(r.statuses) > 1 {
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func webhookEvent(eventType string) domain.TaskEvent {
+	task := &domain.Task{ID: "task-1", OwnerID: "user-1", Title: "Call the plumber", Description: "Kitchen sink", Completed: true, Version: 2}
+	return domain.TaskEvent{ID: "event-" + eventType, Type: eventType, OwnerID: "user-1", ActorID: "user-1", TaskID: "task-1", Task: task}
+}
+
+func TestWebhookDispatcher_RetriesWithBackoff(t *testing.T) {
+	receiver := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	repo := memory.NewInMemoryWebhookRepository()
+	webhook, err := app.NewWebhookService(repo, nil, true).CreateWebhook("user-1", domain.WebhookRequest{URL: server.URL, Events: []string{domain.TaskCompleted}})
+	require.NoError(t, err)
+	dispatcher := app.NewWebhookDispatcher(repo, server.Client(), 3, time.Minute, 1)
+
+	dispatcher.Handle(webhookEvent(domain.TaskUpdated))
+	dispatcher.Handle(webhookEvent(domain.TaskCompleted))
+
+	start := time.Now().UTC()
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Tick(ctx, start))
+	deliveries, err := repo.GetDeliveries("user-1", webhook.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1, "only the subscribed event type is delivered")
+	assert.Equal(t, domain.DeliveryPending, deliveries[0].Status)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].ResponseStatus)
+	assert.Equal(t, start.Add(time.Minute), *deliveries[0].NextAttemptAt)
+
+	// Nothing is retried before the backoff has passed, which doubles.
+	require.NoError(t, dispatcher.Tick(ctx, start.Add(30*time.Second)))
+	require.NoError(t, dispatcher.Tick(ctx, start.Add(time.Minute)))
+	deliveries, _ = repo.GetDeliveries("user-1", webhook.ID)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, start.Add(3*time.Minute), *deliveries[0].NextAttemptAt)
+
+	require.NoError(t, dispatcher.Tick(ctx, start.Add(3*time.Minute)))
+	deliveries, _ = repo.GetDeliveries("user-1", webhook.ID)
+	assert.Equal(t, domain.DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+	assert.Nil(t, deliveries[0].NextAttemptAt)
+	assert.Empty(t, deliveries[0].LastError)
+
+	require.Len(t, receiver.requests, 3)
+	for i, req := range receiver.requests {
+		assert.Equal(t, domain.TaskCompleted, req.Header.Get(app.WebhookEventHeader))
+		assert.Equal(t, deliveries[0].ID, req.Header.Get(app.WebhookDeliveryHeader), "retries keep the delivery ID")
+		sentAt := start.Add([]time.Duration{0, time.Minute, 3 * time.Minute}[i])
+		assert.Equal(t, app.SignWebhookPayload(webhook.Secret, sentAt, receiver.bodies[i]), req.Header.Get(app.WebhookSignatureHeader), "every attempt is signed with its own timestamp")
+	}
+	assert.JSONEq(t, `{
+		"id": "event-task.completed",
+		"type": "task.completed",
+		"owner_id": "user-1",
+		"actor_id": "user-1",
+		"task_id": "task-1",
+		"task": {"id": "task-1", "owner_id": "user-1", "title": "Call the plumber", "description": "Kitchen sink", "completed": true, "priority": 0, "created_at": "0001-01-01T00:00:00Z", "updated_at": "0001-01-01T00:00:00Z", "version": 2},
+		"occurred_at": "0001-01-01T00:00:00Z"
+	}`, string(receiver.bodies[0]))
+}
+
+func TestWebhookDispatcher_DeadLetters(t *testing.T) {
+	receiver := &receiver{statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	repo := memory.NewInMemoryWebhookRepository()
+	service := app.NewWebhookService(repo, nil, true)
+	webhook, err := service.CreateWebhook("user-1", domain.WebhookRequest{URL: server.URL, Events: domain.TaskEventTypes})
+	require.NoError(t, err)
+	dispatcher := app.NewWebhookDispatcher(repo, server.Client(), 2, time.Second, 1)
+
+	dispatcher.Handle(webhookEvent(domain.TaskDeleted))
+	start := time.Now().UTC()
+	require.NoError(t, dispatcher.Tick(context.Background(), start))
+	require.NoError(t, dispatcher.Tick(context.Background(), start.Add(time.Second)))
+	require.NoError(t, dispatcher.Tick(context.Background(), start.Add(time.Hour)))
+
+	assert.Len(t, receiver.requests, 2)
+	dead, err := service.GetDeadLetters("user-1")
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, webhook.ID, dead[0].WebhookID)
+	assert.Equal(t, domain.DeliveryDead, dead[0].Status)
+	assert.Equal(t, "unexpected status 503", dead[0].LastError)
+
+	others, err := service.GetDeadLetters("user-2")
+	require.NoError(t, err)
+	assert.Empty(t, others)
+}
+
+func TestWebhookDispatcher_SendsToWebhooksConcurrently(t *testing.T) {
+	// The slow receiver only answers once the fast one got its request, which
+	// cannot happen if the webhooks are sent to one after the other.
+	fastCalled := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		select {
+		case <-fastCalled:
+			w.WriteHeader(http.StatusOK)
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(fastCalled)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fast.Close()
+
+	repo := memory.NewInMemoryWebhookRepository()
+	service := app.NewWebhookService(repo, nil, true)
+	for _, url := range []string{slow.URL, fast.URL} {
+		_, err := service.CreateWebhook("user-1", domain.WebhookRequest{URL: url, Events: domain.TaskEventTypes})
+		require.NoError(t, err)
+	}
+	dispatcher := app.NewWebhookDispatcher(repo, http.DefaultClient, 1, time.Minute, 2)
+	dispatcher.Handle(webhookEvent(domain.TaskCreated))
+
+	require.NoError(t, dispatcher.Tick(context.Background(), time.Now().UTC()))
+	webhooks, err := service.GetWebhooks("user-1")
+	require.NoError(t, err)
+	for _, webhook := range webhooks {
+		deliveries, err := repo.GetDeliveries("user-1", webhook.ID)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, domain.DeliverySucceeded, deliveries[0].Status, webhook.URL)
+	}
+}
+
+func TestWebhookClient_RefusesPrivateAddresses(t *testing.T) {
+	receiver := &receiver{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	_, err := app.NewWebhookClient(time.Second, false).Get(server.URL)
+	assert.ErrorContains(t, err, "is not public")
+	assert.Empty(t, receiver.requests)
+
+	resp, err := app.NewWebhookClient(time.Second, true).Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Len(t, receiver.requests, 1)
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	resolver := new(mocks.HostResolver)
+	resolver.On("LookupNetIP", mock.Anything, "ip", "example.com").Return([]netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil)
+	resolver.On("LookupNetIP", mock.Anything, "ip", "internal.example.com").Return([]netip.Addr{netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("10.0.0.7")}, nil)
+	resolver.On("LookupNetIP", mock.Anything, "ip", "loopback.example.com").Return([]netip.Addr{netip.MustParseAddr("127.0.0.1")}, nil)
+	resolver.On("LookupNetIP", mock.Anything, "ip", "missing.example.com").Return(nil, errors.New("no such host"))
+	service := app.NewWebhookService(memory.NewInMemoryWebhookRepository(), resolver, false)
+
+	webhook, err := service.CreateWebhook("user-1", domain.WebhookRequest{
+		URL:    "https://example.com/hooks",
+		Events: []string{domain.TaskDeleted, domain.TaskCreated, domain.TaskDeleted},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.TaskCreated, domain.TaskDeleted}, webhook.Events)
+	assert.Regexp(t, "^whsec_[0-9a-f]{64}$", webhook.Secret)
+
+	webhooks, err := service.GetWebhooks("user-1")
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret, "the secret is only shown on creation")
+
+	_, err = service.CreateWebhook("user-1", domain.WebhookRequest{URL: "ftp://example.com/hooks", Events: []string{domain.TaskCreated}})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://loopback.example.com/hooks",
+		"http://[::1]/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://192.168.1.10/hooks",
+		"http://100.64.0.1/hooks",
+		"http://[::ffff:10.0.0.1]/hooks",
+		"https://internal.example.com/hooks",
+		"https://missing.example.com/hooks",
+	} {
+		_, err = service.CreateWebhook("user-1", domain.WebhookRequest{URL: url, Events: []string{domain.TaskCreated}})
+		assert.ErrorIs(t, err, domain.ErrValidation, url)
+	}
+}
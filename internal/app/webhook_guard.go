@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"todo-list-task/internal/domain"
+)
+
+// ErrWebhookTargetNotPublic is returned when a webhook URL points at a
+// loopback, link-local, private or otherwise non-public address.
+var ErrWebhookTargetNotPublic = domain.NewError(domain.ErrValidation, "url must resolve to public addresses")
+
+// HostResolver looks up the addresses of a host name; *net.Resolver implements it.
+type HostResolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// reservedPrefixes are ranges IsGlobalUnicast accepts that are not reachable
+// on the public internet: "this network", carrier-grade NAT, IETF protocol
+// assignments, benchmarking, the reserved class E and NAT64.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// publicAddress reports whether addr is a public unicast address, so a
+// webhook cannot be used to reach the host itself or its internal network.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookHost resolves host, a host name or an IP literal, and fails
+// unless every address it resolves to is public.
+func checkWebhookHost(ctx context.Context, resolver HostResolver, host string) error {
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		if addrs, err = resolver.LookupNetIP(ctx, "ip", host); err != nil || len(addrs) == 0 {
+			return domain.NewError(domain.ErrValidation, fmt.Sprintf("url host %q cannot be resolved", host))
+		}
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return ErrWebhookTargetNotPublic
+		}
+	}
+	return nil
+}
+
+// NewWebhookClient returns the HTTP client of webhook deliveries. Unless
+// allowPrivate is set, its dialer refuses every connection to a non-public
+// address. The check runs on the address being dialed, so a host name that
+// resolves differently after registration, or a redirect, cannot reach
+// internal services either. Proxies are not used, since they would hide the
+// destination from the check.
+func NewWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddress(addrPort.Addr()) {
+				return fmt.Errorf("webhook address %s is not public", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
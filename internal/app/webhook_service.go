@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// webhookLookupTimeout bounds the resolution of the host of a new webhook.
+const webhookLookupTimeout = 5 * time.Second
+
+// WebhookService manages the webhooks of a user and their delivery log.
+// Webhook URLs must resolve to public addresses unless allowPrivate is set.
+type WebhookService struct {
+	repo         repository.WebhookRepository
+	resolver     HostResolver
+	allowPrivate bool
+}
+
+func NewWebhookService(repo repository.WebhookRepository, resolver HostResolver, allowPrivate bool) *WebhookService {
+	return &WebhookService{repo: repo, resolver: resolver, allowPrivate: allowPrivate}
+}
+
+// CreateWebhook registers an http or https URL for the user's task events of
+// the requested types. The returned webhook carries its signing secret,
+// which is never shown again.
+func (w WebhookService) CreateWebhook(userID string, request domain.WebhookRequest) (*domain.Webhook, error) {
+	target, err := url.Parse(request.URL)
+	if err != nil || target.Scheme != "http" && target.Scheme != "https" || target.Hostname() == "" {
+		return nil, domain.NewError(domain.ErrValidation, "url must be an absolute http or https URL")
+	}
+	if !w.allowPrivate {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookLookupTimeout)
+		defer cancel()
+		if err := checkWebhookHost(ctx, w.resolver, target.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &domain.Webhook{
+		ID:        uuid.NewString(),
+		OwnerID:   userID,
+		URL:       target.String(),
+		Events:    uniqueEvents(request.Events),
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := w.repo.CreateWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// GetWebhooks returns the user's webhooks, oldest first, without their secrets.
+func (w WebhookService) GetWebhooks(userID string) ([]*domain.Webhook, error) {
+	webhooks, err := w.repo.GetWebhooks(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes a webhook of the user. Its pending deliveries are dropped.
+func (w WebhookService) DeleteWebhook(userID, id string) error {
+	return w.repo.DeleteWebhook(userID, id)
+}
+
+// GetDeliveries returns the delivery log of a webhook of the user, newest first.
+func (w WebhookService) GetDeliveries(userID, webhookID string) ([]*domain.WebhookDelivery, error) {
+	return w.repo.GetDeliveries(userID, webhookID)
+}
+
+// GetDeadLetters returns the deliveries to the user's webhooks that failed
+// every attempt, newest first.
+func (w WebhookService) GetDeadLetters(userID string) ([]*domain.WebhookDelivery, error) {
+	return w.repo.GetDeadDeliveries(userID)
+}
+
+// newWebhookSecret returns a random secret for signing deliveries.
+func newWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+// uniqueEvents returns the event types in the order of domain.TaskEventTypes,
+// without repetitions.
+func uniqueEvents(requested []string) []string {
+	events := []string{}
+	for _, eventType := range domain.TaskEventTypes {
+		if slices.Contains(requested, eventType) {
+			events = append(events, eventType)
+		}
+	}
+	return events
+}
@@ -0,0 +1,443 @@
+// Package config loads the typed service configuration.
+//
+// Values are resolved with the following precedence, from lowest to highest:
+//
+//  1. built-in defaults
+//  2. the YAML file given by -config or CONFIG_FILE
+//  3. environment variables
+//  4. command-line flags
+//
+// Tokens are signed with the PEM private keys found in the JWT keys directory
+// (RS256, ES256 or EdDSA depending on the key type). Without a keys directory
+// they fall back to HS256 with the JWT secret, which then has no default and
+// must be provided; the service refuses to start with an empty secret or with
+// the well-known placeholder "secret".
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// minSecretLength is the shortest jwt secret accepted, 256 bits as HS256 expects.
+const minSecretLength = 32
+
+// placeholderSecrets are jwt secrets found in examples and docs, refused
+// with their own error so the cause is obvious.
+var placeholderSecrets = []string{"secret", "change-me", "changeme", "change-me-too"}
+
+// maxWebhookAttempts bounds the webhook attempts, so the doubling backoff
+// stays within days.
+const maxWebhookAttempts = 15
+
+// minAdminPassword matches the minimum password length of registrations.
+const minAdminPassword = 8
+
+// Config is the complete service configuration.
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Tasks    TasksConfig    `yaml:"tasks"`
+	Webhooks WebhooksConfig `yaml:"webhooks"`
+	Stream   StreamConfig   `yaml:"stream"`
+}
+
+// ServerConfig configures the HTTP server.
+type ServerConfig struct {
+	Addr            string        `yaml:"addr"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// AuthConfig configures token signing and password hashing.
+type AuthConfig struct {
+	JWTSecret       string        `yaml:"jwt_secret"`
+	KeysDir         string        `yaml:"keys_dir"`
+	ActiveKey       string        `yaml:"active_key"`
+	RetiredKeys     []string      `yaml:"retired_keys"`
+	TokenTTL        time.Duration `yaml:"token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	BcryptCost      int           `yaml:"bcrypt_cost"`
+	// AdminUsername and AdminPassword create the first admin account at
+	// startup; later admins are promoted by an existing one.
+	AdminUsername string `yaml:"admin_username"`
+	AdminPassword string `yaml:"admin_password"`
+}
+
+// StorageConfig selects and configures the repository backend.
+type StorageConfig struct {
+	Driver         string `yaml:"driver"`
+	DataDir        string `yaml:"data_dir"`
+	CompactEvery   int    `yaml:"compact_every"`
+	DatabaseDriver string `yaml:"database_driver"`
+	DatabaseDSN    string `yaml:"database_dsn"`
+}
+
+// TasksConfig configures background work on tasks.
+type TasksConfig struct {
+	// ReminderInterval is how often due reminders are looked for.
+	ReminderInterval time.Duration `yaml:"reminder_interval"`
+	// OutboxInterval is how often the task events recorded by the
+	// repository are published.
+	OutboxInterval time.Duration `yaml:"outbox_interval"`
+	// TrashRetention is how long deleted tasks stay in the trash before
+	// they are purged.
+	TrashRetention time.Duration `yaml:"trash_retention"`
+	// PurgeInterval is how often expired tasks are purged from the trash.
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// WebhooksConfig configures the delivery of task events to webhooks.
+type WebhooksConfig struct {
+	// MaxAttempts is how often a delivery is tried before it is dead.
+	MaxAttempts int `yaml:"max_attempts"`
+	// Backoff is the wait before the first retry; it doubles on every retry.
+	Backoff time.Duration `yaml:"backoff"`
+	// Timeout bounds each delivery request.
+	Timeout time.Duration `yaml:"timeout"`
+	// Workers is how many webhooks are sent to at the same time.
+	Workers int `yaml:"workers"`
+	// AllowPrivate lets webhooks target loopback, link-local and private
+	// addresses, for receivers on the same host or network.
+	AllowPrivate bool `yaml:"allow_private"`
+}
+
+// StreamConfig configures the real-time stream of task changes.
+type StreamConfig struct {
+	// BufferSize is how many of the latest events are kept for clients
+	// resuming after a reconnection.
+	BufferSize int `yaml:"buffer_size"`
+	// Heartbeat is how often idle connections get a keep-alive message.
+	Heartbeat time.Duration `yaml:"heartbeat"`
+}
+
+// Default returns the configuration used when nothing overrides it.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Auth: AuthConfig{
+			TokenTTL:        time.Hour,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+			BcryptCost:      bcrypt.DefaultCost,
+		},
+		Storage: StorageConfig{
+			Driver:         "memory",
+			DataDir:        "data",
+			CompactEvery:   1000,
+			DatabaseDriver: "sqlite",
+			DatabaseDSN:    "todo.db?_pragma=busy_timeout(5000)",
+		},
+		Tasks: TasksConfig{
+			ReminderInterval: 30 * time.Second,
+			OutboxInterval:   200 * time.Millisecond,
+			TrashRetention:   30 * 24 * time.Hour,
+			PurgeInterval:    time.Hour,
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts: 5,
+			Backoff:     10 * time.Second,
+			Timeout:     10 * time.Second,
+			Workers:     4,
+		},
+		Stream: StreamConfig{
+			BufferSize: 1000,
+			Heartbeat:  15 * time.Second,
+		},
+	}
+}
+
+// setting binds one configuration value to its environment variable and flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"SERVER_ADDR", "addr", "HTTP listen address", func(c *Config, v string) error {
+		c.Server.Addr = v
+		return nil
+	}},
+	{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "graceful shutdown timeout", func(c *Config, v string) error {
+		return parseDuration(v, &c.Server.ShutdownTimeout)
+	}},
+	{"JWT_SECRET", "jwt-secret", "secret used to sign access tokens", func(c *Config, v string) error {
+		c.Auth.JWTSecret = v
+		return nil
+	}},
+	{"JWT_KEYS_DIR", "jwt-keys-dir", "directory of PEM private keys used to sign access tokens", func(c *Config, v string) error {
+		c.Auth.KeysDir = v
+		return nil
+	}},
+	{"JWT_ACTIVE_KEY", "jwt-active-key", "id (file name without .pem) of the key that signs new tokens", func(c *Config, v string) error {
+		c.Auth.ActiveKey = v
+		return nil
+	}},
+	{"JWT_RETIRED_KEYS", "jwt-retired-keys", "comma-separated ids of keys no longer accepted", func(c *Config, v string) error {
+		c.Auth.RetiredKeys = splitList(v)
+		return nil
+	}},
+	{"TOKEN_TTL", "token-ttl", "access token lifetime", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.TokenTTL)
+	}},
+	{"REFRESH_TOKEN_TTL", "refresh-token-ttl", "refresh token lifetime", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.RefreshTokenTTL)
+	}},
+	{"BCRYPT_COST", "bcrypt-cost", "bcrypt cost used to hash passwords", func(c *Config, v string) error {
+		return parseInt(v, &c.Auth.BcryptCost)
+	}},
+	{"ADMIN_USERNAME", "admin-username", "username of the admin account created at startup", func(c *Config, v string) error {
+		c.Auth.AdminUsername = v
+		return nil
+	}},
+	{"ADMIN_PASSWORD", "admin-password", "password of the admin account created at startup", func(c *Config, v string) error {
+		c.Auth.AdminPassword = v
+		return nil
+	}},
+	{"STORAGE_DRIVER", "storage-driver", "storage backend: memory, file or sql", func(c *Config, v string) error {
+		c.Storage.Driver = v
+		return nil
+	}},
+	{"DATA_DIR", "data-dir", "directory of the file storage backend", func(c *Config, v string) error {
+		c.Storage.DataDir = v
+		return nil
+	}},
+	{"COMPACT_EVERY", "compact-every", "log records between snapshots of the file storage backend", func(c *Config, v string) error {
+		return parseInt(v, &c.Storage.CompactEvery)
+	}},
+	{"DATABASE_DRIVER", "database-driver", "database/sql driver of the sql storage backend", func(c *Config, v string) error {
+		c.Storage.DatabaseDriver = v
+		return nil
+	}},
+	{"DATABASE_DSN", "database-dsn", "data source name of the sql storage backend", func(c *Config, v string) error {
+		c.Storage.DatabaseDSN = v
+		return nil
+	}},
+	{"REMINDER_INTERVAL", "reminder-interval", "how often task reminders are checked", func(c *Config, v string) error {
+		return parseDuration(v, &c.Tasks.ReminderInterval)
+	}},
+	{"OUTBOX_INTERVAL", "outbox-interval", "how often recorded task events are published", func(c *Config, v string) error {
+		return parseDuration(v, &c.Tasks.OutboxInterval)
+	}},
+	{"TRASH_RETENTION", "trash-retention", "how long deleted tasks stay in the trash", func(c *Config, v string) error {
+		return parseDuration(v, &c.Tasks.TrashRetention)
+	}},
+	{"PURGE_INTERVAL", "purge-interval", "how often expired tasks are purged from the trash", func(c *Config, v string) error {
+		return parseDuration(v, &c.Tasks.PurgeInterval)
+	}},
+	{"WEBHOOK_MAX_ATTEMPTS", "webhook-max-attempts", "attempts of a webhook delivery before it is dead", func(c *Config, v string) error {
+		return parseInt(v, &c.Webhooks.MaxAttempts)
+	}},
+	{"WEBHOOK_BACKOFF", "webhook-backoff", "wait before the first retry of a webhook delivery, doubled on every retry", func(c *Config, v string) error {
+		return parseDuration(v, &c.Webhooks.Backoff)
+	}},
+	{"WEBHOOK_TIMEOUT", "webhook-timeout", "timeout of a webhook delivery request", func(c *Config, v string) error {
+		return parseDuration(v, &c.Webhooks.Timeout)
+	}},
+	{"WEBHOOK_WORKERS", "webhook-workers", "webhooks sent to at the same time", func(c *Config, v string) error {
+		return parseInt(v, &c.Webhooks.Workers)
+	}},
+	{"WEBHOOK_ALLOW_PRIVATE", "webhook-allow-private", "allow webhooks to target loopback and private addresses", func(c *Config, v string) error {
+		return parseBool(v, &c.Webhooks.AllowPrivate)
+	}},
+	{"STREAM_BUFFER_SIZE", "stream-buffer-size", "task events kept to resume the change stream", func(c *Config, v string) error {
+		return parseInt(v, &c.Stream.BufferSize)
+	}},
+	{"STREAM_HEARTBEAT", "stream-heartbeat", "interval of the keep-alive messages of the change stream", func(c *Config, v string) error {
+		return parseDuration(v, &c.Stream.Heartbeat)
+	}},
+}
+
+// Load resolves the configuration from the command-line arguments (without
+// the program name) and the environment looked up through lookupEnv.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("todo-list-task", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML configuration file (env CONFIG_FILE)")
+	for _, s := range settings {
+		fs.String(s.flag, "", fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok && value != "" {
+			if err := s.set(&cfg, value); err != nil {
+				return cfg, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				if err := s.set(&cfg, f.Value.String()); err != nil {
+					flagErr = errors.Join(flagErr, fmt.Errorf("-%s: %w", s.flag, err))
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return cfg, flagErr
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid or missing value.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server address is required"))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown timeout must be positive"))
+	}
+	if c.Auth.KeysDir != "" {
+		if c.Auth.ActiveKey == "" {
+			errs = append(errs, errors.New("active key is required when a jwt keys dir is set"))
+		}
+	} else if c.Auth.JWTSecret == "" {
+		errs = append(errs, errors.New("jwt secret or jwt keys dir is required"))
+	} else if slices.Contains(placeholderSecrets, strings.ToLower(c.Auth.JWTSecret)) {
+		errs = append(errs, errors.New("jwt secret must not be a placeholder"))
+	} else if len(c.Auth.JWTSecret) < minSecretLength {
+		errs = append(errs, fmt.Errorf("jwt secret must be at least %d bytes", minSecretLength))
+	}
+	if c.Auth.TokenTTL <= 0 {
+		errs = append(errs, errors.New("token ttl must be positive"))
+	}
+	if c.Auth.RefreshTokenTTL <= 0 {
+		errs = append(errs, errors.New("refresh token ttl must be positive"))
+	}
+	if c.Auth.BcryptCost < bcrypt.MinCost || c.Auth.BcryptCost > bcrypt.MaxCost {
+		errs = append(errs, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+	if (c.Auth.AdminUsername == "") != (c.Auth.AdminPassword == "") {
+		errs = append(errs, errors.New("admin username and admin password must be set together"))
+	} else if c.Auth.AdminPassword != "" && len(c.Auth.AdminPassword) < minAdminPassword {
+		errs = append(errs, fmt.Errorf("admin password must have at least %d characters", minAdminPassword))
+	}
+
+	switch c.Storage.Driver {
+	case "memory":
+	case "file":
+		if c.Storage.DataDir == "" {
+			errs = append(errs, errors.New("data dir is required for the file storage driver"))
+		}
+	case "sql":
+		if c.Storage.DatabaseDriver == "" || c.Storage.DatabaseDSN == "" {
+			errs = append(errs, errors.New("database driver and dsn are required for the sql storage driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown storage driver %q", c.Storage.Driver))
+	}
+
+	if c.Tasks.ReminderInterval <= 0 {
+		errs = append(errs, errors.New("reminder interval must be positive"))
+	}
+	if c.Tasks.OutboxInterval <= 0 {
+		errs = append(errs, errors.New("outbox interval must be positive"))
+	}
+	if c.Tasks.TrashRetention <= 0 {
+		errs = append(errs, errors.New("trash retention must be positive"))
+	}
+	if c.Tasks.PurgeInterval <= 0 {
+		errs = append(errs, errors.New("purge interval must be positive"))
+	}
+	if c.Webhooks.MaxAttempts < 1 || c.Webhooks.MaxAttempts > maxWebhookAttempts {
+		errs = append(errs, fmt.Errorf("webhook max attempts must be between 1 and %d", maxWebhookAttempts))
+	}
+	if c.Webhooks.Backoff <= 0 {
+		errs = append(errs, errors.New("webhook backoff must be positive"))
+	}
+	if c.Webhooks.Timeout <= 0 {
+		errs = append(errs, errors.New("webhook timeout must be positive"))
+	}
+	if c.Webhooks.Workers < 1 {
+		errs = append(errs, errors.New("webhook workers must be positive"))
+	}
+	if c.Stream.BufferSize < 0 {
+		errs = append(errs, errors.New("stream buffer size must not be negative"))
+	}
+	if c.Stream.Heartbeat <= 0 {
+		errs = append(errs, errors.New("stream heartbeat must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func parseDuration(value string, target *time.Duration) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*target = d
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseInt(value string, target *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*target = n
+	return nil
+}
+
+func parseBool(value string, target *bool) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*target = b
+	return nil
+}
@@ -0,0 +1,191 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-list-task/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSecret is a jwt secret long enough to pass validation.
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := config.Load(nil, env(map[string]string{"JWT_SECRET": testSecret}))
+
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.Equal(t, time.Hour, cfg.Auth.TokenTTL)
+	assert.Equal(t, 10, cfg.Auth.BcryptCost)
+	assert.Equal(t, "memory", cfg.Storage.Driver)
+	assert.Equal(t, 30*time.Second, cfg.Tasks.ReminderInterval)
+	assert.Equal(t, 200*time.Millisecond, cfg.Tasks.OutboxInterval)
+	assert.Equal(t, 30*24*time.Hour, cfg.Tasks.TrashRetention)
+	assert.Equal(t, time.Hour, cfg.Tasks.PurgeInterval)
+	assert.Equal(t, 5, cfg.Webhooks.MaxAttempts)
+	assert.Equal(t, 10*time.Second, cfg.Webhooks.Backoff)
+	assert.Equal(t, 4, cfg.Webhooks.Workers)
+	assert.False(t, cfg.Webhooks.AllowPrivate)
+	assert.Equal(t, 1000, cfg.Stream.BufferSize)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  addr: ":7000"
+auth:
+  jwt_secret: from-file-0123456789abcdef0123456789
+  token_ttl: 30m
+  bcrypt_cost: 12
+storage:
+  driver: file
+  data_dir: /var/lib/todo
+`)
+
+	cfg, err := config.Load(
+		[]string{"-config", path, "-addr", ":9000"},
+		env(map[string]string{"SERVER_ADDR": ":8000", "TOKEN_TTL": "2h"}),
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, ":9000", cfg.Server.Addr, "flags override env and file")
+	assert.Equal(t, 2*time.Hour, cfg.Auth.TokenTTL, "env overrides file")
+	assert.Equal(t, "from-file-0123456789abcdef0123456789", cfg.Auth.JWTSecret)
+	assert.Equal(t, 12, cfg.Auth.BcryptCost)
+	assert.Equal(t, "file", cfg.Storage.Driver)
+	assert.Equal(t, "/var/lib/todo", cfg.Storage.DataDir)
+}
+
+func TestLoad_ConfigFileFromEnv(t *testing.T) {
+	path := writeConfigFile(t, "auth:\n  jwt_secret: from-file-0123456789abcdef0123456789\n")
+
+	cfg, err := config.Load(nil, env(map[string]string{"CONFIG_FILE": path}))
+
+	require.NoError(t, err)
+	assert.Equal(t, "from-file-0123456789abcdef0123456789", cfg.Auth.JWTSecret)
+}
+
+func TestLoad_KeysDirReplacesSecret(t *testing.T) {
+	cfg, err := config.Load(
+		[]string{"-jwt-keys-dir", "/etc/todo/keys", "-jwt-active-key", "2026-10"},
+		env(map[string]string{"JWT_RETIRED_KEYS": "2025-01, 2025-06"}),
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/todo/keys", cfg.Auth.KeysDir)
+	assert.Equal(t, "2026-10", cfg.Auth.ActiveKey)
+	assert.Equal(t, []string{"2025-01", "2025-06"}, cfg.Auth.RetiredKeys)
+}
+
+func TestLoad_Validation(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{
+			name: "should refuse an empty secret",
+		},
+		{
+			name: "should refuse the default secret",
+			env:  map[string]string{"JWT_SECRET": "secret"},
+		},
+		{
+			name: "should refuse the example secret",
+			env:  map[string]string{"JWT_SECRET": "Change-Me"},
+		},
+		{
+			name: "should refuse a short secret",
+			env:  map[string]string{"JWT_SECRET": "s3cr3t"},
+		},
+		{
+			name: "should refuse a keys dir without active key",
+			env:  map[string]string{"JWT_KEYS_DIR": "/etc/todo/keys"},
+		},
+		{
+			name: "should refuse an out of range bcrypt cost",
+			env:  map[string]string{"JWT_SECRET": testSecret, "BCRYPT_COST": "99"},
+		},
+		{
+			name: "should refuse an unknown storage driver",
+			args: []string{"-storage-driver", "mongo"},
+			env:  map[string]string{"JWT_SECRET": testSecret},
+		},
+		{
+			name: "should refuse a malformed duration",
+			env:  map[string]string{"JWT_SECRET": testSecret, "TOKEN_TTL": "forever"},
+		},
+		{
+			name: "should refuse a non positive token ttl",
+			args: []string{"-token-ttl", "0s"},
+			env:  map[string]string{"JWT_SECRET": testSecret},
+		},
+		{
+			name: "should refuse a non positive reminder interval",
+			env:  map[string]string{"JWT_SECRET": testSecret, "REMINDER_INTERVAL": "-1s"},
+		},
+		{
+			name: "should refuse a non positive outbox interval",
+			env:  map[string]string{"JWT_SECRET": testSecret, "OUTBOX_INTERVAL": "0s"},
+		},
+		{
+			name: "should refuse an admin username without password",
+			env:  map[string]string{"JWT_SECRET": testSecret, "ADMIN_USERNAME": "root"},
+		},
+		{
+			name: "should refuse a short admin password",
+			env:  map[string]string{"JWT_SECRET": testSecret, "ADMIN_USERNAME": "root", "ADMIN_PASSWORD": "short"},
+		},
+		{
+			name: "should refuse a non positive trash retention",
+			args: []string{"-trash-retention", "0s"},
+			env:  map[string]string{"JWT_SECRET": testSecret},
+		},
+		{
+			name: "should refuse a non positive purge interval",
+			env:  map[string]string{"JWT_SECRET": testSecret, "PURGE_INTERVAL": "-1m"},
+		},
+		{
+			name: "should refuse webhook deliveries without attempts",
+			args: []string{"-webhook-max-attempts", "0"},
+			env:  map[string]string{"JWT_SECRET": testSecret},
+		},
+		{
+			name: "should refuse webhook deliveries without workers",
+			env:  map[string]string{"JWT_SECRET": testSecret, "WEBHOOK_WORKERS": "0"},
+		},
+		{
+			name: "should refuse a malformed webhook private address switch",
+			env:  map[string]string{"JWT_SECRET": testSecret, "WEBHOOK_ALLOW_PRIVATE": "sometimes"},
+		},
+		{
+			name: "should refuse a non positive stream heartbeat",
+			env:  map[string]string{"JWT_SECRET": testSecret, "STREAM_HEARTBEAT": "0s"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := config.Load(tc.args, env(tc.env))
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ErrRevisionNotFound is returned when a task has no revision with the requested number.
+var ErrRevisionNotFound = NewError(ErrNotFound, "revision not found")
+
+// ErrInvalidAuditQuery is returned for malformed audit queries or cursors.
+var ErrInvalidAuditQuery = NewError(ErrValidation, "invalid audit query")
+
+// Actions of audit entries.
+const (
+	AuditCreate   = "create"
+	AuditUpdate   = "update"
+	AuditComplete = "complete"
+	AuditDelete   = "delete"
+	// AuditRestore brings a task back from the trash or to the state of an
+	// earlier revision.
+	AuditRestore = "restore"
+	// AuditPurge removes a task from the trash for good. Purges have no actor.
+	AuditPurge = "purge"
+
+	// DefaultAuditLimit is the page size used when the query does not set one.
+	DefaultAuditLimit = 50
+	// MaxAuditLimit is the largest page size a query may request.
+	MaxAuditLimit = 500
+)
+
+// AuditActions are every action of audit entries.
+var AuditActions = []string{AuditCreate, AuditUpdate, AuditComplete, AuditDelete, AuditRestore, AuditPurge}
+
+// auditIgnoredFields are the task fields every change touches, left out of
+// the changes of an entry.
+var auditIgnoredFields = []string{"id", "created_at", "updated_at", "updated_by", "version"}
+
+// AuditEntry records one change to a task: who made it, when, and the task
+// before and after it. Revision is the version the change gave the task; a
+// deletion counts as the version after the deleted one. Seq orders the
+// entries of the audit log and is assigned when the entry is stored.
+type AuditEntry struct {
+	Seq      int64         `json:"seq"`
+	ID       string        `json:"id"`
+	TaskID   string        `json:"task_id"`
+	OwnerID  string        `json:"owner_id"`
+	ActorID  string        `json:"actor_id"`
+	Action   string        `json:"action"`
+	Revision int64         `json:"revision"`
+	Changes  []FieldChange `json:"changes"`
+	Before   *Task         `json:"before,omitempty"`
+	After    *Task         `json:"after,omitempty"`
+	At       time.Time     `json:"at"`
+}
+
+// FieldChange is a task field that differs between two states of a task,
+// with its JSON values; a missing value means the field was unset.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// NewAuditEntry returns the entry of a change of a task from before to
+// after, either of which may be nil, made by actorID at the given time. An
+// empty action is derived from the change.
+func NewAuditEntry(id, actorID, action string, before, after *Task, at time.Time) AuditEntry {
+	entry := AuditEntry{ID: id, ActorID: actorID, Action: action, Changes: DiffTasks(before, after), At: at}
+	if before != nil {
+		copied := *before
+		entry.Before = &copied
+		entry.TaskID, entry.OwnerID, entry.Revision = before.ID, before.OwnerID, before.Version+1
+	}
+	if after != nil {
+		copied := *after
+		entry.After = &copied
+		entry.TaskID, entry.OwnerID, entry.Revision = after.ID, after.OwnerID, after.Version
+	}
+	if entry.Action == "" {
+		entry.Action = auditAction(before, after)
+	}
+	return entry
+}
+
+// NewTaskChangeAudit returns the entry of a stored change of a task from
+// previous to next made by actorID at the given time, as recorded by task
+// repositories along with the change. It has the ID of the event of the
+// change. An empty action is derived from the change.
+func NewTaskChangeAudit(actorID, action string, previous, next *Task, at time.Time) *AuditEntry {
+	entry := NewAuditEntry("", actorID, action, previous, next, at)
+	entry.ID = TaskEventID(entry.TaskID, entry.Revision)
+	return &entry
+}
+
+func auditAction(before, after *Task) string {
+	switch {
+	case before == nil:
+		return AuditCreate
+	case after == nil:
+		return AuditDelete
+	case before.DeletedAt != nil:
+		return AuditRestore
+	case after.Completed && !before.Completed:
+		return AuditComplete
+	default:
+		return AuditUpdate
+	}
+}
+
+// DiffTasks returns the fields that differ between two states of a task,
+// sorted by name, comparing their JSON values. Either state may be nil.
+func DiffTasks(before, after *Task) []FieldChange {
+	beforeFields, afterFields := taskFields(before), taskFields(after)
+	var names []string
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		if slices.Contains(auditIgnoredFields, name) || bytes.Equal(beforeFields[name], afterFields[name]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: beforeFields[name], After: afterFields[name]})
+	}
+	return changes
+}
+
+func taskFields(task *Task) map[string]json.RawMessage {
+	if task == nil {
+		return nil
+	}
+	data, _ := json.Marshal(task)
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+// AuditQuery selects one page of the audit log, newest first. Every set
+// filter must match; Since and Until bound the time of the change
+// (inclusive and exclusive). Cursor is the NextCursor of the previous page.
+type AuditQuery struct {
+	TaskID   string
+	OwnerID  string
+	ActorID  string
+	Action   string
+	Revision int64
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+	Cursor   string
+}
+
+// AuditPage is one page of an audit query.
+type AuditPage struct {
+	Entries    []*AuditEntry `json:"entries"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// Normalize applies the default limit and validates the query.
+func (q *AuditQuery) Normalize() error {
+	if q.Action != "" && !slices.Contains(AuditActions, q.Action) {
+		return ErrInvalidAuditQuery
+	}
+	if _, err := q.DecodeCursor(); err != nil {
+		return err
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultAuditLimit
+	}
+	if q.Limit > MaxAuditLimit {
+		q.Limit = MaxAuditLimit
+	}
+	return nil
+}
+
+// Matches reports whether an entry satisfies the filters of the query.
+func (q AuditQuery) Matches(entry *AuditEntry) bool {
+	switch {
+	case q.TaskID != "" && entry.TaskID != q.TaskID,
+		q.OwnerID != "" && entry.OwnerID != q.OwnerID,
+		q.ActorID != "" && entry.ActorID != q.ActorID,
+		q.Action != "" && entry.Action != q.Action,
+		q.Revision != 0 && entry.Revision != q.Revision,
+		q.Since != nil && entry.At.Before(*q.Since),
+		q.Until != nil && !entry.At.Before(*q.Until):
+		return false
+	}
+	return true
+}
+
+// DecodeCursor returns the Seq the page starts before, or zero without a cursor.
+func (q AuditQuery) DecodeCursor() (int64, error) {
+	if q.Cursor == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseInt(q.Cursor, 10, 64)
+	if err != nil || seq <= 0 {
+		return 0, ErrInvalidAuditQuery
+	}
+	return seq, nil
+}
+
+// PageAudit returns the page of entries, given oldest first by Seq, selected
+// by a normalized query. The page lists the newest entries first.
+func PageAudit(entries []*AuditEntry, query AuditQuery) *AuditPage {
+	before, _ := query.DecodeCursor()
+	end := len(entries)
+	if before != 0 {
+		end, _ = slices.BinarySearchFunc(entries, before, func(entry *AuditEntry, seq int64) int {
+			return cmp.Compare(entry.Seq, seq)
+		})
+	}
+	page := &AuditPage{Entries: []*AuditEntry{}}
+	for i := end - 1; i >= 0; i-- {
+		entry := entries[i]
+		if !query.Matches(entry) {
+			continue
+		}
+		if len(page.Entries) == query.Limit {
+			page.NextCursor = strconv.FormatInt(page.Entries[query.Limit-1].Seq, 10)
+			break
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	return page
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "errors"
+
+// Error kinds. Every domain error wraps exactly one of them, so transports can
+// map a whole family of failures with errors.Is instead of matching each
+// specific error.
+var (
+	// ErrNotFound is the kind of errors for resources that do not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is the kind of errors for requests that clash with the current state.
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed is the kind of errors for failed conditional requests.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnauthorized is the kind of errors for missing or invalid credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is the kind of errors for authenticated callers that may not perform an action.
+	ErrForbidden = errors.New("forbidden")
+	// ErrValidation is the kind of errors for malformed or invalid input.
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error is a domain error of a given kind.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+// NewError returns an error of the given kind with a message for the caller.
+func NewError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap exposes the kind, so errors.Is(err, ErrNotFound) matches every
+// not-found error.
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
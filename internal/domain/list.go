@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+var (
+	// ErrListNotFound is returned when a list does not exist or is not shared with the user.
+	ErrListNotFound = NewError(ErrNotFound, "list not found")
+	// ErrListMemberNotFound is returned when removing a user who is not a member of a list.
+	ErrListMemberNotFound = NewError(ErrNotFound, "list member not found")
+	// ErrListNotEmpty is returned when deleting a list that still has tasks.
+	ErrListNotEmpty = NewError(ErrConflict, "list still has tasks")
+	// ErrNotListOwner is returned when a member tries to manage a list.
+	ErrNotListOwner = NewError(ErrForbidden, "only the list owner can do this")
+	// ErrListReadOnly is returned when a viewer tries to change the tasks of a list.
+	ErrListReadOnly = NewError(ErrForbidden, "list is shared read-only")
+)
+
+const (
+	// ListViewer members can read the tasks of a list.
+	ListViewer = "viewer"
+	// ListEditor members can also create, change and delete its tasks.
+	ListEditor = "editor"
+	// ListOwner is the role of the user who created the list.
+	ListOwner = "owner"
+)
+
+// listRoleRank orders the roles by the access they grant.
+var listRoleRank = map[string]int{ListViewer: 1, ListEditor: 2, ListOwner: 3}
+
+// List groups tasks. Its tasks belong to the owner of the list, who can
+// share it with other users as viewers or editors.
+type List struct {
+	ID        string       `json:"id"`
+	OwnerID   string       `json:"owner_id"`
+	Name      string       `json:"name"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Members   []ListMember `json:"members"`
+}
+
+// ListMember is a user a list is shared with.
+type ListMember struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// ListRequest represents the incoming data structure for creating or renaming a list.
+type ListRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ListMemberRequest represents the incoming data structure for sharing a list.
+type ListMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer editor"`
+}
+
+// RoleOf returns the role of the user in the list, or "" when the list is
+// not shared with the user.
+func (l *List) RoleOf(userID string) string {
+	if l.OwnerID == userID {
+		return ListOwner
+	}
+	for _, member := range l.Members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+// Allows reports whether the user has at least the given role in the list.
+func (l *List) Allows(userID, role string) bool {
+	return listRoleRank[l.RoleOf(userID)] >= listRoleRank[role]
+}
+
+// SetMember adds a member or changes the role of an existing one. Members
+// are copied, so lists sharing a members slice are not affected.
+func (l *List) SetMember(member ListMember) {
+	l.Members = slices.Clone(l.Members)
+	for i := range l.Members {
+		if l.Members[i].UserID == member.UserID {
+			l.Members[i] = member
+			return
+		}
+	}
+	l.Members = append(l.Members, member)
+}
+
+// RemoveMember removes a member, copying the members like SetMember.
+func (l *List) RemoveMember(userID string) error {
+	i := slices.IndexFunc(l.Members, func(member ListMember) bool { return member.UserID == userID })
+	if i < 0 {
+		return ErrListMemberNotFound
+	}
+	l.Members = slices.Delete(slices.Clone(l.Members), i, i+1)
+	return nil
+}
@@ -1,17 +1,41 @@
 package domain
 
-// Task represents a to-do item in the system.
+import "time"
+
+// ErrTaskNotFound is returned when a task does not exist or belongs to another user.
+var ErrTaskNotFound = NewError(ErrNotFound, "task not found")
+
+// ErrVersionConflict is returned when a conditional update targets a stale task version.
+var ErrVersionConflict = NewError(ErrPreconditionFailed, "task version conflict")
+
+// Task represents a to-do item in the system. Version starts at 1 and is
+// incremented by every update. A task in a list belongs to the list owner.
+// UpdatedBy is the user who made the latest change.
 type Task struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Completed   bool   `json:"completed"`
+	ID          string    `json:"id"`
+	OwnerID     string    `json:"owner_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Completed   bool      `json:"completed"`
+	ListID      string    `json:"list_id,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int64     `json:"version"`
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+	// DeletedAt is set while the task is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	TaskSchedule
+	TaskLinks
 }
 
 // TaskRequest represents the incoming data structure for creating or updating a task.
 type TaskRequest struct {
-	Title       string `json:"title" binding:"required" validate:"required"`
-	Description string `json:"description" binding:"required" validate:"required"`
-	Completed   bool   `json:"completed"`
+	Title       string   `json:"title" binding:"required" validate:"required"`
+	Description string   `json:"description" binding:"required" validate:"required"`
+	Completed   bool     `json:"completed"`
+	ListID      string   `json:"list_id,omitempty"`
+	Tags        []string `json:"tags,omitempty" binding:"max=20"`
+	TaskSchedule
+	TaskLinks
 }
-
@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrTaskExists is returned when a batch creates a task whose ID is already taken.
+var ErrTaskExists = NewError(ErrConflict, "task already exists")
+
+const (
+	// BatchCreate creates a task from Task.
+	BatchCreate = "create"
+	// BatchUpdate replaces the title, description, completed flag, list, tags, schedule and links of a task.
+	BatchUpdate = "update"
+	// BatchComplete marks a task as completed.
+	BatchComplete = "complete"
+	// BatchDelete deletes a task.
+	BatchDelete = "delete"
+
+	// MaxBatchSize is the largest number of operations a batch may contain.
+	MaxBatchSize = 100
+)
+
+// TaskBatchRequest represents the incoming data structure for a batch of task
+// operations. When Atomic is set either every operation is applied or none.
+type TaskBatchRequest struct {
+	Atomic     bool                 `json:"atomic"`
+	Operations []TaskBatchOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// TaskBatchOperation is a single operation of a batch. ID is required by every
+// operation but create; a non-zero Version makes it conditional like If-Match.
+type TaskBatchOperation struct {
+	Op      string       `json:"op" binding:"required,oneof=create update complete delete"`
+	ID      string       `json:"id,omitempty"`
+	Version int64        `json:"version,omitempty"`
+	Task    *TaskRequest `json:"task,omitempty"`
+}
+
+// TaskChange is one write of a batch as seen by a repository.
+type TaskChange struct {
+	Op string
+	ID string
+	// Version is the expected current version; zero skips the check.
+	Version int64
+	// Task is the new task for create and carries the new fields for update.
+	Task *Task
+	// At is the modification time for update and complete, and the time of
+	// the event of a delete.
+	At time.Time
+	// ActorID is the user who makes the change.
+	ActorID string
+	// OccurrenceID is the ID given to the next occurrence of a recurring
+	// task the change completes; without one no occurrence is created.
+	OccurrenceID string
+	// Action is the audit action of the change; empty derives it from the change.
+	Action string
+}
+
+// TaskChangeResult is the outcome of one change: the resulting task (nil
+// after a delete) or the error that prevented it. Previous is the task
+// before the change, nil for a create. Occurrence is the next occurrence
+// created when the change completed a recurring task.
+type TaskChangeResult struct {
+	Task       *Task
+	Previous   *Task
+	Occurrence *Task
+	Err        error
+}
+
+// BatchError reports the operation that made an atomic batch fail.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Apply computes the result of the change given current, the stored task
+// with the change's ID or nil when there is none. It returns nil for a delete.
+func (ch TaskChange) Apply(ownerID string, current *Task) (*Task, error) {
+	if ch.Op == BatchCreate {
+		if current != nil {
+			return nil, ErrTaskExists
+		}
+		return ch.Task, nil
+	}
+
+	if current == nil || current.OwnerID != ownerID {
+		return nil, ErrTaskNotFound
+	}
+	if ch.Version != 0 && ch.Version != current.Version {
+		return nil, ErrVersionConflict
+	}
+
+	next := *current
+	switch ch.Op {
+	case BatchUpdate:
+		next.Title = ch.Task.Title
+		next.Description = ch.Task.Description
+		next.Completed = ch.Task.Completed
+		next.ListID = ch.Task.ListID
+		next.Tags = ch.Task.Tags
+		next.TaskSchedule = ch.Task.TaskSchedule
+		next.TaskLinks = ch.Task.TaskLinks
+	case BatchComplete:
+		next.Completed = true
+	case BatchDelete:
+		return nil, nil
+	default:
+		return nil, NewError(ErrValidation, fmt.Sprintf("unknown batch operation %q", ch.Op))
+	}
+	next.UpdatedAt = ch.At
+	next.UpdatedBy = ch.ActorID
+	next.Version = current.Version + 1
+	return &next, nil
+}
+
+// Event returns the event of the change once applied to previous, the task
+// before it, with next as the result.
+func (ch TaskChange) Event(previous, next *Task) TaskEvent {
+	if next != nil {
+		return NewTaskChangeEvent(ch.ActorID, previous, next, next.UpdatedAt)
+	}
+	return NewTaskChangeEvent(ch.ActorID, previous, nil, ch.At)
+}
+
+// Audit returns the audit entry of the change once applied to previous, the
+// task before it, with next as the result.
+func (ch TaskChange) Audit(previous, next *Task) *AuditEntry {
+	if next != nil {
+		return NewTaskChangeAudit(ch.ActorID, ch.Action, previous, next, next.UpdatedAt)
+	}
+	return NewTaskChangeAudit(ch.ActorID, ch.Action, previous, nil, ch.At)
+}
+
+// AuditTaskChanges returns the audit entries of the changes of a batch that
+// succeeded, and of the next occurrences they created, given their results.
+func AuditTaskChanges(changes []TaskChange, results []TaskChangeResult) []*AuditEntry {
+	var entries []*AuditEntry
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		entries = append(entries, changes[i].Audit(result.Previous, result.Task))
+		if result.Occurrence != nil {
+			entries = append(entries, NewTaskChangeAudit(changes[i].ActorID, AuditCreate, nil, result.Occurrence, result.Occurrence.UpdatedAt))
+		}
+	}
+	return entries
+}
+
+// NextOccurrence returns the create of the next occurrence of a recurring
+// task when the change completes it, going from previous to next. The
+// occurrence belongs to the same batch as the change, so a series never
+// loses or duplicates an occurrence.
+func (ch TaskChange) NextOccurrence(previous, next *Task) (TaskChange, bool) {
+	if ch.OccurrenceID == "" || previous == nil || next == nil || previous.Completed || !next.Completed {
+		return TaskChange{}, false
+	}
+	schedule, recurs := next.NextOccurrence()
+	if !recurs {
+		return TaskChange{}, false
+	}
+	occurrence := &Task{
+		ID:           ch.OccurrenceID,
+		OwnerID:      next.OwnerID,
+		Title:        next.Title,
+		Description:  next.Description,
+		ListID:       next.ListID,
+		Tags:         next.Tags,
+		CreatedAt:    next.UpdatedAt,
+		UpdatedAt:    next.UpdatedAt,
+		Version:      1,
+		UpdatedBy:    ch.ActorID,
+		TaskSchedule: schedule,
+		TaskLinks:    TaskLinks{ParentID: next.ParentID},
+	}
+	return TaskChange{Op: BatchCreate, ID: occurrence.ID, Task: occurrence, At: next.UpdatedAt, ActorID: ch.ActorID}, true
+}
+
+// ApplyTaskChanges runs changes in order on top of the tasks returned by
+// lookup, without modifying them. It returns the staged state of every task
+// it touched (nil for deleted ones) for the caller to persist, with the
+// events of the changes that succeeded, in order. In atomic mode the first
+// failure aborts the batch with a *BatchError; otherwise failed changes are
+// skipped and reported in their result. A change completing a recurring
+// task also stages its next occurrence.
+func ApplyTaskChanges(ownerID string, changes []TaskChange, atomic bool, lookup func(id string) *Task) (map[string]*Task, []TaskChangeResult, []TaskEvent, error) {
+	staged := make(map[string]*Task)
+	results := make([]TaskChangeResult, len(changes))
+	var events []TaskEvent
+
+	for i, change := range changes {
+		current, ok := staged[change.ID]
+		if !ok {
+			current = lookup(change.ID)
+		}
+
+		next, err := change.Apply(ownerID, current)
+		if err != nil {
+			if atomic {
+				return nil, nil, nil, &BatchError{Index: i, Err: err}
+			}
+			results[i].Err = err
+			continue
+		}
+		results[i] = TaskChangeResult{Task: next, Previous: current}
+		changeEvents := []TaskEvent{change.Event(current, next)}
+
+		if create, ok := change.NextOccurrence(current, next); ok {
+			existing, ok := staged[create.ID]
+			if !ok {
+				existing = lookup(create.ID)
+			}
+			occurrence, err := create.Apply(ownerID, existing)
+			if err != nil {
+				if atomic {
+					return nil, nil, nil, &BatchError{Index: i, Err: err}
+				}
+				results[i] = TaskChangeResult{Err: err}
+				continue
+			}
+			staged[create.ID] = occurrence
+			results[i].Occurrence = occurrence
+			changeEvents = append(changeEvents, create.Event(nil, occurrence))
+		}
+		staged[change.ID] = next
+		events = append(events, changeEvents...)
+	}
+	return staged, results, events, nil
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Types of task events.
+const (
+	// TaskCreated is emitted when a task is created, including the next
+	// occurrence of a recurring task and imported tasks.
+	TaskCreated = "task.created"
+	// TaskUpdated is emitted when a task changes without being completed.
+	TaskUpdated = "task.updated"
+	// TaskCompleted is emitted when an open task is completed.
+	TaskCompleted = "task.completed"
+	// TaskDeleted is emitted when a task is deleted, which moves it to the trash.
+	TaskDeleted = "task.deleted"
+	// TaskRestored is emitted when a task is restored from the trash.
+	TaskRestored = "task.restored"
+	// TaskPurged is emitted when a task is removed from the trash for good.
+	TaskPurged = "task.purged"
+)
+
+// TaskEventTypes are every type of task event.
+var TaskEventTypes = []string{TaskCreated, TaskUpdated, TaskCompleted, TaskDeleted, TaskRestored, TaskPurged}
+
+// TaskEvent is emitted after a change to a task is stored. ID identifies the
+// change, so it is the same every time the event is delivered and consumers
+// use it to drop repeats. OwnerID is the owner of the task and ActorID the
+// user who changed it, who differ for the tasks of shared lists. ListID is
+// the list of the task. Task is the new state of the task; it is absent for
+// deletions and purges. Purges are made by the service itself, so they are
+// the only events without an actor.
+type TaskEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OwnerID    string    `json:"owner_id"`
+	ActorID    string    `json:"actor_id"`
+	TaskID     string    `json:"task_id"`
+	ListID     string    `json:"list_id,omitempty"`
+	Task       *Task     `json:"task,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// UpdateEventType returns the type of the event of a change from previous to
+// next: TaskCompleted when it completes an open task, TaskUpdated otherwise.
+func UpdateEventType(previous, next *Task) string {
+	if next.Completed && (previous == nil || !previous.Completed) {
+		return TaskCompleted
+	}
+	return TaskUpdated
+}
+
+// TaskEventID returns the ID of the event of the change that gave a task the
+// given version. A deletion counts as the version after the deleted one.
+func TaskEventID(taskID string, version int64) string {
+	return fmt.Sprintf("%s@%d", taskID, version)
+}
+
+// NewTaskChangeEvent returns the event of a stored change of a task from
+// previous to next made by actorID at the given time: a creation when
+// previous is nil, a deletion when next is nil, a restoration when previous
+// is in the trash and an update or a completion otherwise.
+func NewTaskChangeEvent(actorID string, previous, next *Task, at time.Time) TaskEvent {
+	event := TaskEvent{ActorID: actorID, OccurredAt: at}
+	switch {
+	case next == nil:
+		event.Type = TaskDeleted
+		event.ID = TaskEventID(previous.ID, previous.Version+1)
+		event.OwnerID, event.TaskID, event.ListID = previous.OwnerID, previous.ID, previous.ListID
+		return event
+	case previous == nil:
+		event.Type = TaskCreated
+	case previous.DeletedAt != nil:
+		event.Type = TaskRestored
+	default:
+		event.Type = UpdateEventType(previous, next)
+	}
+	task := *next
+	event.ID = TaskEventID(next.ID, next.Version)
+	event.OwnerID, event.TaskID, event.ListID = next.OwnerID, next.ID, next.ListID
+	event.Task = &task
+	return event
+}
+
+// NewTaskPurgeEvent returns the event of the removal for good of a trashed
+// task at the given time, which counts as the version after the trashed one.
+func NewTaskPurgeEvent(trashed *Task, at time.Time) TaskEvent {
+	return TaskEvent{
+		ID:         TaskEventID(trashed.ID, trashed.Version+1),
+		Type:       TaskPurged,
+		OwnerID:    trashed.OwnerID,
+		TaskID:     trashed.ID,
+		ListID:     trashed.ListID,
+		OccurredAt: at,
+	}
+}
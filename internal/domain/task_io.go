@@ -0,0 +1,56 @@
+package domain
+
+// ErrInvalidRecord is the kind of errors for single records of an import that
+// cannot be parsed; the rest of the stream can still be read.
+var ErrInvalidRecord = NewError(ErrValidation, "invalid record")
+
+const (
+	// FormatJSONLines is one JSON task per line.
+	FormatJSONLines = "jsonl"
+	// FormatCSV is a CSV file with a header row.
+	FormatCSV = "csv"
+	// FormatICalendar is an iCalendar file with one VTODO per task.
+	FormatICalendar = "ics"
+
+	// MaxImportErrors is the number of rejected records detailed in an import report.
+	MaxImportErrors = 100
+)
+
+// TaskEncoder writes tasks to a stream one at a time.
+type TaskEncoder interface {
+	Encode(task *Task) error
+	// Close writes any trailer of the format and flushes buffered output.
+	Close() error
+}
+
+// TaskDecoder reads tasks from a stream one at a time. Decode returns io.EOF
+// after the last task. A record that cannot be parsed is reported with an
+// ErrInvalidRecord error and decoding can continue with the next one; any
+// other error means the stream cannot be read any further.
+type TaskDecoder interface {
+	Decode() (*Task, error)
+}
+
+// ImportReport summarizes an import. In a dry run nothing is stored and
+// Imported counts the records that would have been.
+type ImportReport struct {
+	DryRun   bool          `json:"dry_run"`
+	Total    int           `json:"total"`
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors"`
+}
+
+// ImportError describes a rejected record, numbered from 1.
+type ImportError struct {
+	Record int    `json:"record"`
+	Error  string `json:"error"`
+}
+
+// Reject records a failed record, keeping at most MaxImportErrors details.
+func (r *ImportReport) Reject(record int, err error) {
+	r.Failed++
+	if len(r.Errors) < MaxImportErrors {
+		r.Errors = append(r.Errors, ImportError{Record: record, Error: err.Error()})
+	}
+}
@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+var (
+	// ErrDependencyCycle is returned when a task would end up blocked by itself.
+	ErrDependencyCycle = NewError(ErrConflict, "task dependency cycle")
+	// ErrParentCycle is returned when a task would end up as its own ancestor.
+	ErrParentCycle = NewError(ErrConflict, "task parent cycle")
+	// ErrTaskBlocked is returned when a task is completed while one of its blockers is open.
+	ErrTaskBlocked = NewError(ErrConflict, "task is blocked by open tasks")
+)
+
+// MaxBlockers is the number of tasks a task may be blocked by.
+const MaxBlockers = 50
+
+// TaskLinks relates a task to other tasks of the same owner. ParentID makes
+// it a subtask; BlockedBy lists the tasks that must be completed first.
+type TaskLinks struct {
+	ParentID  string   `json:"parent_id,omitempty"`
+	BlockedBy []string `json:"blocked_by,omitempty" binding:"max=50,dive,required"`
+}
+
+// HasLinks reports whether the task has a parent or blockers.
+func (l TaskLinks) HasLinks() bool {
+	return l.ParentID != "" || len(l.BlockedBy) > 0
+}
+
+// Normalize checks the links and removes duplicate blockers, keeping the
+// first occurrence of each.
+func (l *TaskLinks) Normalize() error {
+	if len(l.BlockedBy) > MaxBlockers {
+		return NewError(ErrValidation, fmt.Sprintf("a task can be blocked by at most %d tasks", MaxBlockers))
+	}
+	if len(l.BlockedBy) == 0 {
+		l.BlockedBy = nil
+		return nil
+	}
+	blockers := make([]string, 0, len(l.BlockedBy))
+	for _, id := range l.BlockedBy {
+		if id == "" {
+			return NewError(ErrValidation, "blocked_by cannot contain empty ids")
+		}
+		if !slices.Contains(blockers, id) {
+			blockers = append(blockers, id)
+		}
+	}
+	l.BlockedBy = blockers
+	return nil
+}
+
+// TaskTree is a task with its subtasks, recursively.
+type TaskTree struct {
+	Task     *Task       `json:"task"`
+	Subtasks []*TaskTree `json:"subtasks"`
+}
+
+// TaskGraph is an in-memory view of the links between the tasks of one owner.
+// Links to tasks that no longer exist are ignored: a deleted blocker does not
+// block and a subtask of a deleted task has no parent in the graph.
+type TaskGraph struct {
+	tasks map[string]*Task
+}
+
+func NewTaskGraph(tasks []*Task) *TaskGraph {
+	graph := &TaskGraph{tasks: make(map[string]*Task, len(tasks))}
+	for _, task := range tasks {
+		graph.tasks[task.ID] = task
+	}
+	return graph
+}
+
+// Get returns the task with the given ID, or nil.
+func (g *TaskGraph) Get(id string) *Task {
+	return g.tasks[id]
+}
+
+// Put adds or replaces a task, typically after Check accepted it.
+func (g *TaskGraph) Put(task *Task) {
+	g.tasks[task.ID] = task
+}
+
+// Remove deletes a task from the graph.
+func (g *TaskGraph) Remove(id string) {
+	delete(g.tasks, id)
+}
+
+// Check validates the links of task as the new state of the task with its
+// ID. New references must point to existing tasks, there must be no parent
+// or dependency cycle, and the task cannot become completed while one of its
+// blockers is open. Links to deleted tasks the task already had are kept.
+func (g *TaskGraph) Check(task *Task) error {
+	current := g.tasks[task.ID]
+
+	if task.ParentID != "" {
+		if task.ParentID == task.ID {
+			return NewError(ErrValidation, "a task cannot be its own parent")
+		}
+		if g.tasks[task.ParentID] == nil && (current == nil || current.ParentID != task.ParentID) {
+			return NewError(ErrValidation, fmt.Sprintf("unknown parent task %q", task.ParentID))
+		}
+		visited := map[string]bool{}
+		for id := task.ParentID; id != ""; {
+			if id == task.ID {
+				return ErrParentCycle
+			}
+			parent := g.tasks[id]
+			if parent == nil || visited[id] {
+				break
+			}
+			visited[id] = true
+			id = parent.ParentID
+		}
+	}
+
+	for _, id := range task.BlockedBy {
+		if id == task.ID {
+			return NewError(ErrValidation, "a task cannot block itself")
+		}
+		if g.tasks[id] == nil && (current == nil || !slices.Contains(current.BlockedBy, id)) {
+			return NewError(ErrValidation, fmt.Sprintf("unknown blocking task %q", id))
+		}
+		if g.reaches(id, task.ID, map[string]bool{}) {
+			return ErrDependencyCycle
+		}
+	}
+
+	if task.Completed && (current == nil || !current.Completed) {
+		for _, id := range task.BlockedBy {
+			if blocker := g.tasks[id]; blocker != nil && !blocker.Completed {
+				return ErrTaskBlocked
+			}
+		}
+	}
+	return nil
+}
+
+// reaches reports whether target can be reached from id by following
+// blocked-by edges.
+func (g *TaskGraph) reaches(id, target string, visited map[string]bool) bool {
+	if id == target {
+		return true
+	}
+	if visited[id] {
+		return false
+	}
+	visited[id] = true
+	task := g.tasks[id]
+	if task == nil {
+		return false
+	}
+	for _, next := range task.BlockedBy {
+		if g.reaches(next, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subtree returns the task with its subtasks, oldest first, or nil when the
+// task does not exist.
+func (g *TaskGraph) Subtree(id string) *TaskTree {
+	children := make(map[string][]*Task)
+	for _, task := range g.tasks {
+		if task.ParentID != "" {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+
+	visited := map[string]bool{}
+	var build func(task *Task) *TaskTree
+	build = func(task *Task) *TaskTree {
+		visited[task.ID] = true
+		tree := &TaskTree{Task: task, Subtasks: []*TaskTree{}}
+		subtasks := children[task.ID]
+		sort.Slice(subtasks, func(i, j int) bool {
+			if !subtasks[i].CreatedAt.Equal(subtasks[j].CreatedAt) {
+				return subtasks[i].CreatedAt.Before(subtasks[j].CreatedAt)
+			}
+			return subtasks[i].ID < subtasks[j].ID
+		})
+		for _, subtask := range subtasks {
+			if !visited[subtask.ID] {
+				tree.Subtasks = append(tree.Subtasks, build(subtask))
+			}
+		}
+		return tree
+	}
+
+	task := g.tasks[id]
+	if task == nil {
+		return nil
+	}
+	return build(task)
+}
+
+// Blockers returns the existing tasks that directly block the task, in the
+// order of its BlockedBy list.
+func (g *TaskGraph) Blockers(id string) []*Task {
+	blockers := []*Task{}
+	task := g.tasks[id]
+	if task == nil {
+		return blockers
+	}
+	for _, blockerID := range task.BlockedBy {
+		if blocker := g.tasks[blockerID]; blocker != nil {
+			blockers = append(blockers, blocker)
+		}
+	}
+	return blockers
+}
@@ -0,0 +1,374 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch is returned for malformed patch documents and for
+	// patches that would leave the task invalid or touch read-only fields.
+	ErrInvalidPatch = NewError(ErrValidation, "invalid task patch")
+	// ErrPatchTestFailed is returned when a JSON Patch "test" operation fails.
+	ErrPatchTestFailed = NewError(ErrConflict, "task patch test failed")
+)
+
+const (
+	// MergePatchFormat is the media type of an RFC 7396 JSON Merge Patch.
+	MergePatchFormat = "application/merge-patch+json"
+	// JSONPatchFormat is the media type of an RFC 6902 JSON Patch.
+	JSONPatchFormat = "application/json-patch+json"
+)
+
+// readOnlyTaskFields are the fields of a task a patch may test but not change.
+var readOnlyTaskFields = []string{"id", "owner_id", "created_at", "updated_at", "version"}
+
+// optionalTaskFields are the fields a patch may add to or remove from a task.
+var optionalTaskFields = []string{"list_id", "tags", "due_at", "time_zone", "reminder_minutes", "recurrence", "parent_id", "blocked_by"}
+
+// TaskPatch is a partial update of a task in one of the supported formats.
+type TaskPatch struct {
+	Format string
+	Body   []byte
+}
+
+// PatchOperation is a single RFC 6902 operation.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply returns a copy of task with the patch applied. The patch works on
+// the JSON representation of the task, so paths and keys use the JSON field
+// names. Only the list and the optional schedule and link fields can be
+// added or removed, the read-only fields cannot be changed, title and
+// description must stay non-empty and the schedule and links must stay valid.
+func (p TaskPatch) Apply(task *Task) (*Task, error) {
+	original, err := taskDocument(task)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := taskDocument(task)
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.Format {
+	case MergePatchFormat:
+		err = applyMergePatch(doc, p.Body)
+	case JSONPatchFormat:
+		err = applyJSONPatch(doc, p.Body)
+	default:
+		err = ErrInvalidPatch
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range doc {
+		_, known := original[key]
+		optional := slices.Contains(optionalTaskFields, key)
+		if !known && !optional {
+			return nil, ErrInvalidPatch
+		}
+		if value == nil {
+			if !optional {
+				return nil, ErrInvalidPatch
+			}
+			delete(doc, key)
+		}
+	}
+	for key := range original {
+		if _, ok := doc[key]; !ok && !slices.Contains(optionalTaskFields, key) {
+			return nil, ErrInvalidPatch
+		}
+	}
+	for _, key := range readOnlyTaskFields {
+		if !reflect.DeepEqual(doc[key], original[key]) {
+			return nil, ErrInvalidPatch
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var patched Task
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	if strings.TrimSpace(patched.Title) == "" || strings.TrimSpace(patched.Description) == "" {
+		return nil, ErrInvalidPatch
+	}
+	if err := patched.TaskSchedule.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := patched.TaskLinks.Normalize(); err != nil {
+		return nil, err
+	}
+	return &patched, nil
+}
+
+// taskDocument decodes the JSON representation of task into a generic object.
+func taskDocument(task *Task) (map[string]any, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// applyMergePatch applies an RFC 7396 merge patch. No field of a task is an
+// object, so members are replaced, or removed when the patch sets them to null.
+func applyMergePatch(doc map[string]any, body []byte) error {
+	var patch map[string]any
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return ErrInvalidPatch
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = value
+	}
+	return nil
+}
+
+// applyJSONPatch applies an RFC 6902 patch. Operations are applied in order
+// and the whole patch fails if any of them does. Paths may point into the
+// array fields of the task by index, or past their end with "-".
+func applyJSONPatch(doc map[string]any, body []byte) error {
+	var operations []PatchOperation
+	if err := json.Unmarshal(body, &operations); err != nil || operations == nil {
+		return ErrInvalidPatch
+	}
+
+	for _, operation := range operations {
+		path, err := patchPointer(operation.Path)
+		if err != nil {
+			return err
+		}
+
+		switch operation.Op {
+		case "add", "replace":
+			value, err := patchValue(operation.Value)
+			if err != nil {
+				return err
+			}
+			if _, err := patchSet(doc, path, value, operation.Op == "add"); err != nil {
+				return err
+			}
+		case "remove":
+			if _, _, err := patchRemove(doc, path); err != nil {
+				return err
+			}
+		case "test":
+			value, err := patchValue(operation.Value)
+			if err != nil {
+				return err
+			}
+			current, ok := patchGet(doc, path)
+			if !ok || !reflect.DeepEqual(current, value) {
+				return ErrPatchTestFailed
+			}
+		case "move", "copy":
+			from, err := patchPointer(operation.From)
+			if err != nil {
+				return err
+			}
+			var value any
+			if operation.Op == "move" {
+				// A value cannot be moved into one of its own children.
+				if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+					return ErrInvalidPatch
+				}
+				if _, value, err = patchRemove(doc, from); err != nil {
+					return err
+				}
+			} else {
+				current, ok := patchGet(doc, from)
+				if !ok {
+					return ErrInvalidPatch
+				}
+				// Copy arrays so later operations on one do not show in the other.
+				if value, err = patchValue(mustMarshal(current)); err != nil {
+					return err
+				}
+			}
+			if _, err := patchSet(doc, path, value, true); err != nil {
+				return err
+			}
+		default:
+			return ErrInvalidPatch
+		}
+	}
+	return nil
+}
+
+// patchPointer splits an RFC 6901 JSON Pointer into its unescaped reference
+// tokens. The whole task cannot be replaced, so the root pointer is invalid.
+func patchPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, ErrInvalidPatch
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for i, token := range tokens {
+		tokens[i] = unescape.Replace(token)
+	}
+	return tokens, nil
+}
+
+// patchIndex resolves an array index token against an array of length n.
+// "-" and n itself are only accepted when end is set, for adding an element.
+func patchIndex(token string, n int, end bool) (int, error) {
+	if token == "-" && end {
+		return n, nil
+	}
+	if token == "" || len(token) > 1 && token[0] == '0' || strings.Trim(token, "0123456789") != "" {
+		return 0, ErrInvalidPatch
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index > n || index == n && !end {
+		return 0, ErrInvalidPatch
+	}
+	return index, nil
+}
+
+// patchGet returns the value path points to in node.
+func patchGet(node any, path []string) (any, bool) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, false
+			}
+			node = value
+		case []any:
+			index, err := patchIndex(token, len(container), false)
+			if err != nil {
+				return nil, false
+			}
+			node = container[index]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// patchSet stores value at path in node and returns node, which is a new
+// slice when an element is inserted into an array. With insert set, as for
+// "add", array elements are inserted and object members may be new; without
+// it, as for "replace", the target must already exist.
+func patchSet(node any, path []string, value any, insert bool) (any, error) {
+	token, last := path[0], len(path) == 1
+	switch container := node.(type) {
+	case map[string]any:
+		current, ok := container[token]
+		if last {
+			if !ok && !insert {
+				return nil, ErrInvalidPatch
+			}
+			container[token] = value
+			return container, nil
+		}
+		if !ok {
+			return nil, ErrInvalidPatch
+		}
+		child, err := patchSet(current, path[1:], value, insert)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = child
+		return container, nil
+	case []any:
+		index, err := patchIndex(token, len(container), last && insert)
+		if err != nil {
+			return nil, err
+		}
+		if last && insert {
+			return slices.Insert(container, index, value), nil
+		}
+		if last {
+			container[index] = value
+			return container, nil
+		}
+		child, err := patchSet(container[index], path[1:], value, insert)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = child
+		return container, nil
+	default:
+		return nil, ErrInvalidPatch
+	}
+}
+
+// patchRemove removes the value at path from node and returns node, a new
+// slice when an array element is removed, along with the removed value.
+func patchRemove(node any, path []string) (any, any, error) {
+	token, last := path[0], len(path) == 1
+	switch container := node.(type) {
+	case map[string]any:
+		current, ok := container[token]
+		if !ok {
+			return nil, nil, ErrInvalidPatch
+		}
+		if last {
+			delete(container, token)
+			return container, current, nil
+		}
+		child, removed, err := patchRemove(current, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = child
+		return container, removed, nil
+	case []any:
+		index, err := patchIndex(token, len(container), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := container[index]
+			return slices.Delete(container, index, index+1), removed, nil
+		}
+		child, removed, err := patchRemove(container[index], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[index] = child
+		return container, removed, nil
+	default:
+		return nil, nil, ErrInvalidPatch
+	}
+}
+
+func mustMarshal(value any) json.RawMessage {
+	data, _ := json.Marshal(value)
+	return data
+}
+
+func patchValue(raw json.RawMessage) (any, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, ErrInvalidPatch
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	return value, nil
+}
@@ -0,0 +1,229 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery is returned for malformed task queries or cursors.
+var ErrInvalidQuery = NewError(ErrValidation, "invalid task query")
+
+const (
+	// SortCreatedAt orders tasks by creation time.
+	SortCreatedAt = "created_at"
+	// SortUpdatedAt orders tasks by last modification time.
+	SortUpdatedAt = "updated_at"
+
+	// DefaultTaskLimit is the page size used when the query does not set one.
+	DefaultTaskLimit = 20
+	// MaxTaskLimit is the largest page size a query may request.
+	MaxTaskLimit = 100
+)
+
+// TaskQuery selects one page of a user's tasks. Pagination is keyset based:
+// Cursor is the opaque NextCursor of the previous page and is only valid for
+// the same sort order. Overdue selects open tasks whose due date is before
+// Now; DueBefore selects tasks due before that instant. ListID selects the
+// tasks of one list. Tags selects the tasks carrying every normalized tag,
+// or any of them when AnyTag is set.
+type TaskQuery struct {
+	OwnerID     string
+	ListID      string
+	Tags        []string
+	AnyTag      bool
+	Completed   *bool
+	Title       string
+	Description string
+	Overdue     bool
+	DueBefore   *time.Time
+	Now         time.Time
+	SortBy      string
+	Descending  bool
+	Limit       int
+	Cursor      string
+}
+
+// TaskPage is one page of a task query.
+type TaskPage struct {
+	Tasks      []*Task `json:"tasks"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// TaskCursor is the decoded position of the last task of a page.
+type TaskCursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	Value      int64  `json:"v"`
+	ID         string `json:"id"`
+}
+
+// Normalize applies the default sort, limit and reference time and validates the query.
+func (q *TaskQuery) Normalize() error {
+	if q.SortBy == "" {
+		q.SortBy = SortCreatedAt
+	}
+	if q.SortBy != SortCreatedAt && q.SortBy != SortUpdatedAt {
+		return ErrInvalidQuery
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultTaskLimit
+	}
+	if q.Limit > MaxTaskLimit {
+		q.Limit = MaxTaskLimit
+	}
+	if q.Overdue && q.Now.IsZero() {
+		q.Now = time.Now().UTC()
+	}
+	return nil
+}
+
+// Matches reports whether a task satisfies the filters of the query.
+func (q TaskQuery) Matches(task *Task) bool {
+	if task.OwnerID != q.OwnerID {
+		return false
+	}
+	if q.ListID != "" && task.ListID != q.ListID {
+		return false
+	}
+	if len(q.Tags) > 0 && !task.HasTags(q.Tags, q.AnyTag) {
+		return false
+	}
+	if q.Completed != nil && task.Completed != *q.Completed {
+		return false
+	}
+	if q.Title != "" && !containsFold(task.Title, q.Title) {
+		return false
+	}
+	if q.Description != "" && !containsFold(task.Description, q.Description) {
+		return false
+	}
+	if q.Overdue && !task.IsOverdue(q.Now) {
+		return false
+	}
+	if q.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*q.DueBefore)) {
+		return false
+	}
+	return true
+}
+
+// SortValue returns the value of the sort field of a task as Unix nanoseconds.
+func (q TaskQuery) SortValue(task *Task) int64 {
+	if q.SortBy == SortUpdatedAt {
+		return UnixNanos(task.UpdatedAt)
+	}
+	return UnixNanos(task.CreatedAt)
+}
+
+// DecodeCursor parses the query cursor, returning nil when there is none.
+func (q TaskQuery) DecodeCursor() (*TaskCursor, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidQuery
+	}
+	var cursor TaskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidQuery
+	}
+	if cursor.SortBy != q.SortBy || cursor.Descending != q.Descending {
+		return nil, ErrInvalidQuery
+	}
+	return &cursor, nil
+}
+
+// EncodeCursor returns the cursor pointing right after task.
+func (q TaskQuery) EncodeCursor(task *Task) string {
+	data, _ := json.Marshal(TaskCursor{
+		SortBy:     q.SortBy,
+		Descending: q.Descending,
+		Value:      q.SortValue(task),
+		ID:         task.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// SortTasks sorts tasks in the order of query, by its sort field and then by
+// ID, as PageTasks does.
+func SortTasks(tasks []*Task, query TaskQuery) {
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if query.Descending {
+			a, b = b, a
+		}
+		va, vb := query.SortValue(a), query.SortValue(b)
+		if va != vb {
+			return va < vb
+		}
+		return a.ID < b.ID
+	})
+}
+
+// PageTasks filters, sorts and paginates tasks in memory. Backends without
+// native query support use it to implement TaskRepository.QueryTasks.
+func PageTasks(tasks []*Task, query TaskQuery) (*TaskPage, error) {
+	cursor, err := query.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	after := func(task *Task) bool {
+		if cursor == nil {
+			return true
+		}
+		value := query.SortValue(task)
+		if query.Descending {
+			return value < cursor.Value || (value == cursor.Value && task.ID < cursor.ID)
+		}
+		return value > cursor.Value || (value == cursor.Value && task.ID > cursor.ID)
+	}
+
+	var selected []*Task
+	for _, task := range tasks {
+		if query.Matches(task) && after(task) {
+			selected = append(selected, task)
+		}
+	}
+	SortTasks(selected, query)
+
+	page := &TaskPage{Tasks: selected}
+	if len(selected) > query.Limit {
+		page.Tasks = selected[:query.Limit]
+		page.NextCursor = query.EncodeCursor(page.Tasks[query.Limit-1])
+	}
+	if page.Tasks == nil {
+		page.Tasks = []*Task{}
+	}
+	return page, nil
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(FoldText(s), FoldText(substr))
+}
+
+// FoldText returns the case-folded form of s used by the title and
+// description filters. Backends that match in the database store it next to
+// the original text so every backend applies the same Unicode rule.
+func FoldText(s string) string {
+	return strings.ToLower(s)
+}
+
+// UnixNanos converts t to Unix nanoseconds, mapping the zero time to 0.
+func UnixNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// FromUnixNanos is the inverse of UnixNanos.
+func FromUnixNanos(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
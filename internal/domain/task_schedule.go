@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"todo-list-task/internal/rrule"
+)
+
+// Priorities of a task, from lowest to highest.
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+const (
+	// MaxReminders is the number of reminders a task may have.
+	MaxReminders = 5
+	// MaxReminderMinutes is the earliest a reminder may fire before the due date.
+	MaxReminderMinutes = 7 * 24 * 60
+)
+
+// TaskSchedule holds the optional planning fields of a task. DueAt is an
+// instant and is stored in UTC; TimeZone is the IANA zone the user works in
+// and is used when the due date is shown as a local time, for example in an
+// iCalendar export. ReminderMinutes lists how many minutes before DueAt a
+// reminder is sent. Recurrence is an RFC 5545 RRULE expanded from DueAt in
+// TimeZone; completing the task creates its next occurrence.
+type TaskSchedule struct {
+	DueAt           *time.Time `json:"due_at,omitempty"`
+	TimeZone        string     `json:"time_zone,omitempty" binding:"omitempty,timezone"`
+	Priority        int        `json:"priority" binding:"min=0,max=3"`
+	ReminderMinutes []int      `json:"reminder_minutes,omitempty" binding:"max=5,dive,min=0,max=10080"`
+	Recurrence      string     `json:"recurrence,omitempty"`
+}
+
+// Normalize validates the schedule, converts the due date to UTC and sorts
+// the reminders, dropping duplicates.
+func (s *TaskSchedule) Normalize() error {
+	if s.Priority < PriorityNone || s.Priority > PriorityHigh {
+		return NewError(ErrValidation, fmt.Sprintf("priority must be between %d and %d", PriorityNone, PriorityHigh))
+	}
+	if s.TimeZone != "" {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			return NewError(ErrValidation, fmt.Sprintf("unknown time zone %q", s.TimeZone))
+		}
+	}
+	if s.DueAt == nil && (s.TimeZone != "" || len(s.ReminderMinutes) > 0 || s.Recurrence != "") {
+		return NewError(ErrValidation, "time zone, reminders and recurrence require a due date")
+	}
+	if len(s.ReminderMinutes) > MaxReminders {
+		return NewError(ErrValidation, fmt.Sprintf("a task may have at most %d reminders", MaxReminders))
+	}
+	for _, minutes := range s.ReminderMinutes {
+		if minutes < 0 || minutes > MaxReminderMinutes {
+			return NewError(ErrValidation, fmt.Sprintf("reminders must be between 0 and %d minutes before the due date", MaxReminderMinutes))
+		}
+	}
+
+	if s.Recurrence != "" {
+		rule, err := rrule.Parse(s.Recurrence)
+		if err != nil {
+			return NewError(ErrValidation, err.Error())
+		}
+		s.Recurrence = rule.String()
+	}
+
+	if s.DueAt != nil {
+		due := s.DueAt.UTC()
+		s.DueAt = &due
+	}
+	if len(s.ReminderMinutes) == 0 {
+		s.ReminderMinutes = nil
+	} else {
+		reminders := slices.Clone(s.ReminderMinutes)
+		slices.Sort(reminders)
+		s.ReminderMinutes = slices.Compact(reminders)
+	}
+	return nil
+}
+
+// Location returns the time zone of the schedule, or UTC when it has none.
+func (s TaskSchedule) Location() *time.Location {
+	if s.TimeZone != "" {
+		if location, err := time.LoadLocation(s.TimeZone); err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+// NextOccurrence returns the schedule of the occurrence that follows this one
+// in a recurring series, or false when the task does not recur or its rule is
+// exhausted. The rule of the next occurrence has its COUNT reduced by one, so
+// COUNT is always the number of occurrences left including the current one.
+func (s TaskSchedule) NextOccurrence() (TaskSchedule, bool) {
+	if s.Recurrence == "" || s.DueAt == nil {
+		return TaskSchedule{}, false
+	}
+	rule, err := rrule.Parse(s.Recurrence)
+	if err != nil || rule.Count == 1 {
+		return TaskSchedule{}, false
+	}
+	due := s.DueAt.In(s.Location())
+	next, ok := rule.After(due, due)
+	if !ok {
+		return TaskSchedule{}, false
+	}
+	if rule.Count > 1 {
+		rule.Count--
+	}
+
+	nextDue := next.UTC()
+	schedule := s
+	schedule.DueAt = &nextDue
+	schedule.ReminderMinutes = slices.Clone(s.ReminderMinutes)
+	schedule.Recurrence = rule.String()
+	return schedule, true
+}
+
+// IsOverdue reports whether the task is still open after its due date.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// IsDueBetween reports whether task is open, has reminders and is due in
+// (from, to]. Backends without native query support use it to implement
+// TaskRepository.GetTasksDueBetween.
+func IsDueBetween(task *Task, from, to time.Time) bool {
+	return !task.Completed && len(task.ReminderMinutes) > 0 && task.DueAt != nil &&
+		task.DueAt.After(from) && !task.DueAt.After(to)
+}
+
+// ReminderEvent is emitted when a reminder of a task is reached.
+type ReminderEvent struct {
+	TaskID        string    `json:"task_id"`
+	OwnerID       string    `json:"owner_id"`
+	Title         string    `json:"title"`
+	DueAt         time.Time `json:"due_at"`
+	RemindAt      time.Time `json:"remind_at"`
+	MinutesBefore int       `json:"minutes_before"`
+}
+
+// RemindersBetween returns the reminders of an open task whose time falls in
+// (from, to], earliest first.
+func (t *Task) RemindersBetween(from, to time.Time) []ReminderEvent {
+	if t.Completed || t.DueAt == nil {
+		return nil
+	}
+	var events []ReminderEvent
+	for i := len(t.ReminderMinutes) - 1; i >= 0; i-- {
+		minutes := t.ReminderMinutes[i]
+		remindAt := t.DueAt.Add(-time.Duration(minutes) * time.Minute)
+		if remindAt.After(from) && !remindAt.After(to) {
+			events = append(events, ReminderEvent{
+				TaskID:        t.ID,
+				OwnerID:       t.OwnerID,
+				Title:         t.Title,
+				DueAt:         *t.DueAt,
+				RemindAt:      remindAt,
+				MinutesBefore: minutes,
+			})
+		}
+	}
+	return events
+}
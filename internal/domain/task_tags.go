@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTags is the number of tags a task may carry.
+	MaxTags = 20
+	// MaxTagLength is the longest tag, in characters.
+	MaxTagLength = 50
+)
+
+// TagCount is an entry of the tag catalog of a user: a tag and the number
+// of tasks carrying it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTags lowercases and trims tags, removes duplicates and sorts them.
+// Tags cannot be empty or contain spaces or commas, so they can be listed in
+// a query parameter or a CSV cell.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.ContainsFunc(tag, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			return nil, NewError(ErrValidation, fmt.Sprintf("invalid tag %q", tag))
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, NewError(ErrValidation, fmt.Sprintf("tag %q is longer than %d characters", tag, MaxTagLength))
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxTags {
+		return nil, NewError(ErrValidation, fmt.Sprintf("a task can have at most %d tags", MaxTags))
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// HasTags reports whether the task carries every tag, or any of them when
+// any is set. Tags must be normalized.
+func (t *Task) HasTags(tags []string, any bool) bool {
+	for _, tag := range tags {
+		_, found := sort.Find(len(t.Tags), func(i int) int { return strings.Compare(tag, t.Tags[i]) })
+		if found && any {
+			return true
+		}
+		if !found && !any {
+			return false
+		}
+	}
+	return !any || len(tags) == 0
+}
+
+// CountTags builds the tag catalog of tasks, sorted by SortTagCounts.
+func CountTags(tasks []*Task) []TagCount {
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		for _, tag := range task.Tags {
+			counts[tag]++
+		}
+	}
+	catalog := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		catalog = append(catalog, TagCount{Tag: tag, Count: count})
+	}
+	SortTagCounts(catalog)
+	return catalog
+}
+
+// SortTagCounts orders a tag catalog from the most used tag to the least
+// used one, and alphabetically between tags used as often.
+func SortTagCounts(catalog []TagCount) {
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Count != catalog[j].Count {
+			return catalog[i].Count > catalog[j].Count
+		}
+		return catalog[i].Tag < catalog[j].Tag
+	})
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// TrashTask returns the copy of a task kept in the trash once actorID
+// deletes it at the given time. The deletion counts as a new version, so
+// the version of the copy matches the ID of the event of the deletion.
+func TrashTask(task *Task, actorID string, at time.Time) *Task {
+	trashed := *task
+	trashed.Version = task.Version + 1
+	trashed.UpdatedAt = at
+	trashed.UpdatedBy = actorID
+	trashed.DeletedAt = &at
+	return &trashed
+}
+
+// RestoreTask returns the task brought back from the trash by actorID at
+// the given time, as a new version of the trashed copy.
+func RestoreTask(trashed *Task, actorID string, at time.Time) *Task {
+	restored := *trashed
+	restored.Version = trashed.Version + 1
+	restored.UpdatedAt = at
+	restored.UpdatedBy = actorID
+	restored.DeletedAt = nil
+	return &restored
+}
+
+// TrashedTasks returns the trashed copies of the tasks deleted by the
+// changes of a batch that succeeded, given their results.
+func TrashedTasks(changes []TaskChange, results []TaskChangeResult) []*Task {
+	var trashed []*Task
+	for i, result := range results {
+		if result.Err == nil && result.Task == nil && result.Previous != nil {
+			trashed = append(trashed, TrashTask(result.Previous, changes[i].ActorID, changes[i].At))
+		}
+	}
+	return trashed
+}
+
+// SortTrash sorts trashed tasks, most recently deleted first.
+func SortTrash(tasks []*Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
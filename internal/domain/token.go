@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens.
+	ErrInvalidRefreshToken = NewError(ErrUnauthorized, "invalid refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token that was already rotated is presented again.
+	ErrRefreshTokenReused = NewError(ErrUnauthorized, "refresh token reused")
+)
+
+// RefreshToken is the server-side record of a long-lived refresh token. Only
+// the hash of the token is stored. Tokens rotated from the same login share a
+// FamilyID so the whole chain can be revoked at once.
+type RefreshToken struct {
+	ID        string     `json:"id"`
+	FamilyID  string     `json:"family_id"`
+	UserID    string     `json:"user_id"`
+	Username  string     `json:"username"`
+	Role      string     `json:"role"`
+	TokenHash string     `json:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// RefreshRequest represents the incoming data structure for refreshing or revoking a token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" validate:"required"`
+}
+
+// StreamTicket is a short-lived, single-use credential for opening a task
+// stream. Browsers cannot set the Authorization header of EventSource and
+// WebSocket requests, so they pass the ticket as a query parameter instead.
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
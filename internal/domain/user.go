@@ -1,10 +1,38 @@
 package domain
 
+var (
+	// ErrUsernameTaken is returned when registering a username that already exists.
+	ErrUsernameTaken = NewError(ErrConflict, "username already exists")
+	// ErrUserNotFound is returned when a user does not exist.
+	ErrUserNotFound = NewError(ErrNotFound, "user not found")
+	// ErrUserDisabled is returned when a disabled account tries to log in.
+	ErrUserDisabled = NewError(ErrForbidden, "user is disabled")
+	// ErrInvalidCredentials is returned when a login uses an unknown username or a wrong password.
+	ErrInvalidCredentials = NewError(ErrUnauthorized, "username or password incorrect")
+	// ErrInvalidRole is returned when assigning a role that does not exist.
+	ErrInvalidRole = NewError(ErrValidation, "invalid role")
+)
+
+const (
+	// RoleUser is the role of every registered user.
+	RoleUser = "user"
+	// RoleAdmin grants access to the administration endpoints. It is only
+	// given to the bootstrap admin and by other admins.
+	RoleAdmin = "admin"
+)
+
+// IsRole reports whether role is one of the known roles.
+func IsRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
 // User represents a user entity in the system.
 type User struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Role     string `json:"role"`
+	Disabled bool   `json:"disabled"`
 }
 
 // UserRequest represents the incoming data structure for user registration and login.
@@ -15,5 +43,19 @@ type UserRequest struct {
 
 // UserResponse represents the outgoing data structure after successful authentication.
 type UserResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RoleRequest represents the incoming data structure for changing the role of a user.
+type RoleRequest struct {
+	Role string `json:"role" binding:"required" validate:"required"`
+}
+
+// UserInfo represents a user as exposed to administrators, without its password hash.
+type UserInfo struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Disabled bool   `json:"disabled"`
 }
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// ErrWebhookNotFound is returned when a webhook does not exist or belongs to another user.
+var ErrWebhookNotFound = NewError(ErrNotFound, "webhook not found")
+
+// ErrDeliveryExists is returned when an event was already queued for a webhook.
+var ErrDeliveryExists = NewError(ErrConflict, "delivery already exists")
+
+// Statuses of a webhook delivery.
+const (
+	// DeliveryPending deliveries wait for their first attempt or a retry.
+	DeliveryPending = "pending"
+	// DeliverySucceeded deliveries were accepted by the receiver with a 2xx response.
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead deliveries failed every attempt and are no longer retried.
+	DeliveryDead = "dead"
+)
+
+// Webhook is a URL a user registered to receive the task events of some
+// types, for the tasks the user owns. Deliveries are signed with Secret,
+// which is only shown when the webhook is created.
+type Webhook struct {
+	ID        string    `json:"id"`
+	OwnerID   string    `json:"owner_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRequest represents the incoming data structure for registering a webhook.
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=task.created task.updated task.completed task.deleted task.restored"`
+}
+
+// Accepts reports whether the webhook is subscribed to events of the type.
+func (w *Webhook) Accepts(eventType string) bool {
+	return slices.Contains(w.Events, eventType)
+}
+
+// WebhookDelivery is one task event sent, or to be sent, to a webhook.
+// Payload is the JSON body posted to the webhook. Failed attempts leave the
+// delivery pending until NextAttemptAt, or dead after the last attempt.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	OwnerID        string          `json:"owner_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
@@ -0,0 +1,70 @@
+// Package events carries task events from the services that emit them to the
+// parts of the process that react to them.
+package events
+
+import (
+	"sync"
+	"todo-list-task/internal/domain"
+)
+
+// Publisher emits task events.
+type Publisher interface {
+	Publish(event domain.TaskEvent)
+}
+
+// Bus is an in-process Publisher that hands every event to its subscribers.
+// It is safe for concurrent use.
+type Bus struct {
+	mu          sync.RWMutex
+	next        int
+	subscribers map[int]func(domain.TaskEvent)
+	order       []int
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[int]func(domain.TaskEvent))}
+}
+
+// Subscribe registers handler for every event published from now on and
+// returns a function that unsubscribes it.
+func (b *Bus) Subscribe(handler func(domain.TaskEvent)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.subscribers[id] = handler
+	b.order = append(b.order, id)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers, id)
+			for i, subscribed := range b.order {
+				if subscribed == id {
+					b.order = append(b.order[:i:i], b.order[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// Publish calls every subscriber with event, synchronously and in the order
+// they subscribed. Subscribers run on the publisher's goroutine, so they must
+// hand slow work off instead of doing it.
+func (b *Bus) Publish(event domain.TaskEvent) {
+	b.mu.RLock()
+	handlers := make([]func(domain.TaskEvent), len(b.order))
+	for i, id := range b.order {
+		handlers[i] = b.subscribers[id]
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
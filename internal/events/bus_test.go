@@ -0,0 +1,23 @@
+package events_test
+
+import (
+	"testing"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Publish(t *testing.T) {
+	bus := events.NewBus()
+	var got []string
+	bus.Subscribe(func(event domain.TaskEvent) { got = append(got, "first "+event.Type) })
+	unsubscribe := bus.Subscribe(func(event domain.TaskEvent) { got = append(got, "second "+event.Type) })
+
+	bus.Publish(domain.TaskEvent{Type: domain.TaskCreated})
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(domain.TaskEvent{Type: domain.TaskDeleted})
+
+	assert.Equal(t, []string{"first task.created", "second task.created", "first task.deleted"}, got)
+}
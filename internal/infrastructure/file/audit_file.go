@@ -0,0 +1,16 @@
+package file
+
+import "todo-list-task/internal/domain"
+
+// QueryAudit returns one page of the audit log, newest first.
+func (r *FileTaskRepository) QueryAudit(query domain.AuditQuery) (*domain.AuditPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := domain.PageAudit(r.audit, query)
+	for i, entry := range page.Entries {
+		copied := *entry
+		page.Entries[i] = &copied
+	}
+	return page, nil
+}
@@ -0,0 +1,51 @@
+package file_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTaskRepository_AuditPersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := openTaskRepo(t, dir, 0)
+	created := newTask("task-1")
+	created.Version, created.UpdatedBy = 1, "user-1"
+	_, err := repo.CreateTask(created)
+	require.NoError(t, err)
+	_, err = repo.UpdateTask(ownerID, "task-1", &domain.Task{Title: created.Title, Description: created.Description, Completed: true, UpdatedBy: "user-2"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	reopened := openTaskRepo(t, dir, 0)
+	require.NoError(t, reopened.DeleteTask(ownerID, "task-1", "user-1"))
+	purged, err := reopened.PurgeTrash(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	events, err := reopened.PendingEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, domain.TaskPurged, events[3].Type)
+	assert.Equal(t, "task-1@4", events[3].ID)
+
+	page, err := reopened.QueryAudit(domain.AuditQuery{TaskID: "task-1", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, domain.AuditPurge, page.Entries[0].Action)
+	assert.Equal(t, int64(4), page.Entries[0].Seq)
+	assert.Equal(t, domain.AuditDelete, page.Entries[1].Action)
+	assert.Equal(t, int64(3), page.Entries[1].Revision)
+
+	next, err := reopened.QueryAudit(domain.AuditQuery{TaskID: "task-1", Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, next.Entries, 2)
+	assert.Equal(t, domain.AuditComplete, next.Entries[0].Action)
+	assert.Equal(t, "user-2", next.Entries[0].ActorID)
+	assert.Equal(t, []domain.FieldChange{{Field: "completed", Before: []byte("false"), After: []byte("true")}}, next.Entries[0].Changes)
+	assert.Equal(t, domain.TaskEventID("task-1", 1), next.Entries[1].ID)
+	assert.Empty(t, next.NextCursor)
+}
@@ -0,0 +1,134 @@
+package file
+
+import (
+	"sync"
+	"todo-list-task/internal/domain"
+)
+
+// FileListRepository is a ListRepository persisted with the same log and
+// snapshot engine as FileTaskRepository. A list is stored with its members,
+// so sharing it appends the whole list to the log.
+type FileListRepository struct {
+	store *logStore[domain.List]
+	mu    sync.RWMutex
+}
+
+// NewFileListRepository opens (or creates) the list log and snapshot in dir
+// and replays them into memory.
+func NewFileListRepository(dir string, compactEvery int) (*FileListRepository, error) {
+	store, err := openLogStore[domain.List](dir, "lists", compactEvery)
+	if err != nil {
+		return nil, err
+	}
+	return &FileListRepository{store: store}, nil
+}
+
+// CreateList appends a new list to the log.
+func (r *FileListRepository) CreateList(list *domain.List) (*domain.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.store.Put(list.ID, *list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetList get a list by id.
+func (r *FileListRepository) GetList(id string) (*domain.List, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list, ok := r.store.Get(id)
+	if !ok {
+		return nil, domain.ErrListNotFound
+	}
+	return withMembers(list), nil
+}
+
+// GetListsForUser get the lists owned by or shared with userID.
+func (r *FileListRepository) GetListsForUser(userID string) ([]*domain.List, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var lists []*domain.List
+	for _, list := range r.store.Items() {
+		if list.RoleOf(userID) != "" {
+			lists = append(lists, withMembers(list))
+		}
+	}
+	return lists, nil
+}
+
+// UpdateList appends the renamed list to the log.
+func (r *FileListRepository) UpdateList(list *domain.List) (*domain.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.store.Get(list.ID)
+	if !ok {
+		return nil, domain.ErrListNotFound
+	}
+	stored.Name = list.Name
+	stored.UpdatedAt = list.UpdatedAt
+	if err := r.store.Put(list.ID, stored); err != nil {
+		return nil, err
+	}
+	return withMembers(stored), nil
+}
+
+// DeleteList appends the deletion of a list to the log.
+func (r *FileListRepository) DeleteList(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.store.Get(id); !ok {
+		return domain.ErrListNotFound
+	}
+	return r.store.Delete(id)
+}
+
+// SetMember appends the list with the new or changed member to the log.
+func (r *FileListRepository) SetMember(listID string, member domain.ListMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, ok := r.store.Get(listID)
+	if !ok {
+		return domain.ErrListNotFound
+	}
+	list.SetMember(member)
+	return r.store.Put(listID, list)
+}
+
+// RemoveMember appends the list without the member to the log.
+func (r *FileListRepository) RemoveMember(listID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, ok := r.store.Get(listID)
+	if !ok {
+		return domain.ErrListNotFound
+	}
+	if err := list.RemoveMember(userID); err != nil {
+		return err
+	}
+	return r.store.Put(listID, list)
+}
+
+// Close releases the underlying log file.
+func (r *FileListRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store.Close()
+}
+
+// withMembers returns the list with a non-nil members slice, so lists
+// without members are encoded as [].
+func withMembers(list domain.List) *domain.List {
+	if list.Members == nil {
+		list.Members = []domain.ListMember{}
+	}
+	return &list
+}
@@ -0,0 +1,56 @@
+package file_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/file"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openListRepo(t *testing.T, dir string) *file.FileListRepository {
+	repo, err := file.NewFileListRepository(dir, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestFileListRepository_PersistsMembersAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := openListRepo(t, dir)
+	_, err := repo.CreateList(&domain.List{ID: "list-1", OwnerID: "user-1", Name: "home", CreatedAt: created, UpdatedAt: created})
+	require.NoError(t, err)
+	require.NoError(t, repo.SetMember("list-1", domain.ListMember{UserID: "user-2", Username: "ana", Role: domain.ListViewer}))
+	require.NoError(t, repo.SetMember("list-1", domain.ListMember{UserID: "user-3", Username: "luis", Role: domain.ListEditor}))
+	require.NoError(t, repo.RemoveMember("list-1", "user-2"))
+	require.NoError(t, repo.Close())
+
+	reopened := openListRepo(t, dir)
+	list, err := reopened.GetList("list-1")
+	require.NoError(t, err)
+	assert.Equal(t, "home", list.Name)
+	assert.Equal(t, []domain.ListMember{{UserID: "user-3", Username: "luis", Role: domain.ListEditor}}, list.Members)
+
+	shared, err := reopened.GetListsForUser("user-3")
+	require.NoError(t, err)
+	assert.Len(t, shared, 1)
+	none, err := reopened.GetListsForUser("user-2")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestFileListRepository_Delete(t *testing.T) {
+	repo := openListRepo(t, t.TempDir())
+	_, err := repo.CreateList(&domain.List{ID: "list-1", OwnerID: "user-1", Name: "home"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteList("list-1"))
+	_, err = repo.GetList("list-1")
+	assert.ErrorIs(t, err, domain.ErrListNotFound)
+	assert.ErrorIs(t, repo.DeleteList("list-1"), domain.ErrListNotFound)
+	assert.ErrorIs(t, repo.RemoveMember("list-1", "user-2"), domain.ErrListNotFound)
+}
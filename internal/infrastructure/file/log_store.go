@@ -0,0 +1,383 @@
+package file
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	opPut    = "put"
+	opDelete = "delete"
+	opBatch  = "batch"
+
+	// recordHeaderSize is the length prefix plus the CRC32 checksum of every log record.
+	recordHeaderSize = 8
+	// maxRecordSize bounds the payload length read from a record header, so
+	// a corrupted header cannot make replay allocate an arbitrary amount.
+	maxRecordSize = 64 << 20
+)
+
+// errRecordTooLarge is returned for records whose payload exceeds maxRecordSize.
+var errRecordTooLarge = errors.New("log record too large")
+
+// record is a single entry of the append-only log. A batch record nests the
+// records of a multi-key change so it is replayed entirely or not at all.
+type record[T any] struct {
+	Op    string      `json:"op"`
+	Key   string      `json:"key,omitempty"`
+	Value *T          `json:"value,omitempty"`
+	Batch []record[T] `json:"batch,omitempty"`
+}
+
+// logStore keeps a keyed set of values on local disk as a snapshot plus an
+// append-only, fsync'd log of the changes made after it. It is not safe for
+// concurrent use; callers are expected to guard it with their own lock.
+//
+// offset is the end of the last record known to be durable. A write that
+// fails is cut back to it, so a torn record never sits in front of later
+// ones. When the log cannot be brought back to that state, or an fsync
+// fails and the file contents can no longer be trusted, the store keeps the
+// error in failed and refuses further writes until it is reopened.
+type logStore[T any] struct {
+	logPath      string
+	snapshotPath string
+	log          *os.File
+	items        map[string]T
+	offset       int64
+	pending      int
+	compactEvery int
+	failed       error
+}
+
+// openLogStore loads <name>.snapshot and replays <name>.log from dir. A torn
+// or corrupted record at the end of the log, left behind by a crash in the
+// middle of a write, is discarded and the log is truncated to the last good
+// record. A corrupted record followed by more records fails the open instead,
+// since truncating would lose them. Once compactEvery records accumulate the
+// log is folded into a new snapshot; zero or less disables compaction.
+func openLogStore[T any](dir, name string, compactEvery int) (*logStore[T], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &logStore[T]{
+		logPath:      filepath.Join(dir, name+".log"),
+		snapshotPath: filepath.Join(dir, name+".snapshot"),
+		items:        make(map[string]T),
+		compactEvery: compactEvery,
+	}
+
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	logFile, err := os.OpenFile(s.logPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.log = logFile
+
+	if err := s.replay(); err != nil {
+		_ = logFile.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *logStore[T]) loadSnapshot() error {
+	data, err := os.ReadFile(s.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.items); err != nil {
+		return fmt.Errorf("corrupted snapshot %s: %w", s.snapshotPath, err)
+	}
+	return nil
+}
+
+func (s *logStore[T]) replay() error {
+	info, err := s.log.Stat()
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(s.log)
+	var offset int64
+
+	for {
+		rec, size, err := readRecord[T](reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if err := s.discardTail(offset, offset+size, info.Size(), err); err != nil {
+				return err
+			}
+			break
+		}
+		s.apply(rec)
+		s.pending++
+		offset += size
+	}
+
+	s.offset = offset
+	_, err = s.log.Seek(offset, io.SeekStart)
+	return err
+}
+
+// discardTail truncates the log at offset, where a record that could not be
+// read starts, when that record is the last one: it runs up to or past the
+// end of the file at end, or only zeros follow it, as a crash can leave. A
+// bad record followed by anything else is damage in the middle of the log,
+// reported as an error so the records after it are not dropped.
+func (s *logStore[T]) discardTail(offset, end, fileSize int64, cause error) error {
+	if end > offset && end < fileSize {
+		zeros, err := s.zerosFrom(end)
+		if err != nil {
+			return err
+		}
+		if !zeros {
+			return fmt.Errorf("corrupted record at offset %d of %s followed by %d more bytes: %w", offset, s.logPath, fileSize-end, cause)
+		}
+	}
+
+	log.Printf("Se descarta el final de %s: %d bytes desde el desplazamiento %d (%v)", s.logPath, fileSize-offset, offset, cause)
+	if err := s.log.Truncate(offset); err != nil {
+		return err
+	}
+	return s.log.Sync()
+}
+
+// zerosFrom reports whether the log holds only zero bytes from offset on.
+func (s *logStore[T]) zerosFrom(offset int64) (bool, error) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := s.log.ReadAt(buf, offset)
+		for _, b := range buf[:n] {
+			if b != 0 {
+				return false, nil
+			}
+		}
+		offset += int64(n)
+		if errors.Is(err, io.EOF) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// readRecord reads the next record and its size. Once the header is read,
+// the size is the one it declares, even when the record turns out to be
+// torn or corrupted.
+func readRecord[T any](reader io.Reader) (record[T], int64, error) {
+	var rec record[T]
+
+	header := make([]byte, recordHeaderSize)
+	n, err := io.ReadFull(reader, header)
+	if n == 0 && errors.Is(err, io.EOF) {
+		return rec, 0, io.EOF
+	}
+	if err != nil {
+		return rec, 0, fmt.Errorf("torn record header: %w", err)
+	}
+
+	length := binary.BigEndian.Uint32(header[:4])
+	checksum := binary.BigEndian.Uint32(header[4:])
+	size := recordHeaderSize + int64(length)
+	if length > maxRecordSize {
+		return rec, size, errRecordTooLarge
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return rec, size, fmt.Errorf("torn record payload: %w", err)
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return rec, size, errors.New("record checksum mismatch")
+	}
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return rec, size, err
+	}
+	return rec, size, nil
+}
+
+func (s *logStore[T]) apply(rec record[T]) {
+	switch rec.Op {
+	case opPut:
+		if rec.Value != nil {
+			s.items[rec.Key] = *rec.Value
+		}
+	case opDelete:
+		delete(s.items, rec.Key)
+	case opBatch:
+		for _, nested := range rec.Batch {
+			s.apply(nested)
+		}
+	}
+}
+
+// Get returns the value stored under key.
+func (s *logStore[T]) Get(key string) (T, bool) {
+	value, ok := s.items[key]
+	return value, ok
+}
+
+// Items returns the live values keyed by their key. The map must not be modified.
+func (s *logStore[T]) Items() map[string]T {
+	return s.items
+}
+
+// Put durably stores value under key.
+func (s *logStore[T]) Put(key string, value T) error {
+	return s.write(record[T]{Op: opPut, Key: key, Value: &value})
+}
+
+// Delete durably removes key.
+func (s *logStore[T]) Delete(key string) error {
+	return s.write(record[T]{Op: opDelete, Key: key})
+}
+
+// Apply durably stores every value of puts and removes every key of deletes
+// in a single log record, so a crash cannot leave half of them applied.
+func (s *logStore[T]) Apply(puts map[string]T, deletes []string) error {
+	batch := make([]record[T], 0, len(puts)+len(deletes))
+	for key, value := range puts {
+		batch = append(batch, record[T]{Op: opPut, Key: key, Value: &value})
+	}
+	for _, key := range deletes {
+		batch = append(batch, record[T]{Op: opDelete, Key: key})
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.write(record[T]{Op: opBatch, Batch: batch})
+}
+
+func (s *logStore[T]) write(rec record[T]) error {
+	if s.failed != nil {
+		return fmt.Errorf("log %s unusable: %w", s.logPath, s.failed)
+	}
+
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxRecordSize {
+		return errRecordTooLarge
+	}
+
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[recordHeaderSize:], payload)
+
+	if _, err := s.log.Write(buf); err != nil {
+		// Cut the partial record so later appends do not land behind it.
+		if rerr := s.rewind(s.offset); rerr != nil {
+			s.failed = rerr
+		}
+		return err
+	}
+	if err := s.log.Sync(); err != nil {
+		// The record may or may not be on disk; remove it so a restart does
+		// not bring back a write the caller saw fail, and stop accepting
+		// writes since the page cache can no longer be trusted.
+		_ = s.rewind(s.offset)
+		s.failed = err
+		return err
+	}
+
+	s.apply(rec)
+	s.offset += int64(len(buf))
+	s.pending++
+
+	if s.compactEvery > 0 && s.pending >= s.compactEvery {
+		// The record is already durable; a failed compaction only leaves a
+		// longer log to replay and is retried on the next write.
+		if err := s.Compact(); err != nil {
+			log.Printf("Error al compactar %s: %v", s.logPath, err)
+		}
+	}
+	return nil
+}
+
+// rewind truncates the log to offset and moves the write position there.
+func (s *logStore[T]) rewind(offset int64) error {
+	if err := s.log.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := s.log.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	return s.log.Sync()
+}
+
+// Compact writes the current state to a new snapshot and empties the log.
+// The snapshot replaces the old one atomically, so a crash at any point
+// leaves either the old snapshot with the full log or the new one.
+func (s *logStore[T]) Compact() error {
+	data, err := json.Marshal(s.items)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.snapshotPath + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.snapshotPath); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(s.snapshotPath)); err != nil {
+		return err
+	}
+
+	// The new snapshot holds every record of the log, so replaying any of
+	// them on top of it is harmless; only a log that cannot be emptied and
+	// repositioned leaves the store unusable.
+	if err := s.rewind(0); err != nil {
+		s.failed = err
+		return err
+	}
+	s.offset = 0
+	s.pending = 0
+	return nil
+}
+
+// Close releases the log file.
+func (s *logStore[T]) Close() error {
+	return s.log.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,472 @@
+package file
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"todo-list-task/internal/domain"
+)
+
+const (
+	// outboxPrefix starts the keys of the outbox entries of the task log.
+	outboxPrefix = "outbox/"
+	// trashPrefix starts the keys of the trashed tasks of the task log.
+	trashPrefix = "trash/"
+	// auditPrefix starts the keys of the audit entries of the task log.
+	auditPrefix = "audit/"
+)
+
+// taskEntry is a value of the task log: a task, an event of the outbox
+// under an outboxPrefix key, a trashed task under a trashPrefix key or an
+// audit entry under an auditPrefix key. Tasks are stored as before the
+// outbox existed, so older logs and snapshots load unchanged.
+type taskEntry struct {
+	*domain.Task
+	Outbox  *outboxEntry       `json:"outbox,omitempty"`
+	Trashed *domain.Task       `json:"trashed,omitempty"`
+	Audit   *domain.AuditEntry `json:"audit,omitempty"`
+}
+
+// outboxEntry is a recorded event with its position in the outbox.
+type outboxEntry struct {
+	Seq   uint64           `json:"seq"`
+	Event domain.TaskEvent `json:"event"`
+}
+
+// FileTaskRepository is a TaskRepository that survives restarts by keeping
+// every change in a write-ahead log under a data directory. The events and
+// audit entries of a change are written to its outbox and audit log in the
+// same log record as the change, so after a crash either all are there or
+// none is. Task IDs are indexed by owner so queries only visit the owner's
+// tasks.
+type FileTaskRepository struct {
+	store  *logStore[taskEntry]
+	owners map[string]map[string]struct{}
+	// outbox are the recorded events, oldest first, and seq the position of
+	// the latest one.
+	outbox []outboxEntry
+	seq    uint64
+	// audit are the audit entries, oldest first.
+	audit []*domain.AuditEntry
+	mu    sync.RWMutex
+}
+
+// NewFileTaskRepository opens (or creates) the task log and snapshot in dir
+// and replays them into memory.
+func NewFileTaskRepository(dir string, compactEvery int) (*FileTaskRepository, error) {
+	store, err := openLogStore[taskEntry](dir, "tasks", compactEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &FileTaskRepository{store: store, owners: make(map[string]map[string]struct{})}
+	for key, entry := range store.Items() {
+		if entry.Task != nil {
+			r.index(key, entry.Task.OwnerID)
+		}
+		if entry.Outbox != nil {
+			r.outbox = append(r.outbox, *entry.Outbox)
+			r.seq = max(r.seq, entry.Outbox.Seq)
+		}
+		if entry.Audit != nil {
+			r.audit = append(r.audit, entry.Audit)
+		}
+	}
+	slices.SortFunc(r.outbox, func(a, b outboxEntry) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	slices.SortFunc(r.audit, func(a, b *domain.AuditEntry) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return r, nil
+}
+
+// get returns the stored task with the given id.
+func (r *FileTaskRepository) get(id string) (*domain.Task, bool) {
+	if strings.HasPrefix(id, outboxPrefix) || strings.HasPrefix(id, trashPrefix) || strings.HasPrefix(id, auditPrefix) {
+		return nil, false
+	}
+	entry, ok := r.store.Get(id)
+	if !ok || entry.Task == nil {
+		return nil, false
+	}
+	task := *entry.Task
+	return &task, true
+}
+
+// owned returns the stored tasks of ownerID. They must not be modified.
+func (r *FileTaskRepository) owned(ownerID string) []*domain.Task {
+	ids := r.owners[ownerID]
+	tasks := make([]*domain.Task, 0, len(ids))
+	for id := range ids {
+		entry, _ := r.store.Get(id)
+		tasks = append(tasks, entry.Task)
+	}
+	return tasks
+}
+
+// index adds id to the tasks of ownerID.
+func (r *FileTaskRepository) index(id, ownerID string) {
+	ids := r.owners[ownerID]
+	if ids == nil {
+		ids = make(map[string]struct{})
+		r.owners[ownerID] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// unindex removes id from the tasks of ownerID.
+func (r *FileTaskRepository) unindex(id, ownerID string) {
+	delete(r.owners[ownerID], id)
+	if len(r.owners[ownerID]) == 0 {
+		delete(r.owners, ownerID)
+	}
+}
+
+// tasks returns copies of every stored task.
+func (r *FileTaskRepository) tasks() []*domain.Task {
+	var tasks []*domain.Task
+	for _, entry := range r.store.Items() {
+		if entry.Task != nil {
+			task := *entry.Task
+			tasks = append(tasks, &task)
+		}
+	}
+	return tasks
+}
+
+// write stores the tasks of puts, moves trash to the trash, deletes the
+// keys of deletes, records events in the outbox and appends audit to the
+// audit log, in a single log record, and keeps the owner index in sync.
+// Events and entries already there are not recorded again.
+func (r *FileTaskRepository) write(puts map[string]*domain.Task, trash []*domain.Task, deletes []string, events []domain.TaskEvent, audit []*domain.AuditEntry) error {
+	entries := make(map[string]taskEntry, len(puts)+len(trash)+len(events)+len(audit))
+	for id, task := range puts {
+		stored := *task
+		entries[id] = taskEntry{Task: &stored}
+	}
+	for _, task := range trash {
+		entries[trashPrefix+task.ID] = taskEntry{Trashed: task}
+	}
+	seq := r.seq
+	var recorded []outboxEntry
+	for _, event := range events {
+		key := outboxPrefix + event.ID
+		if _, ok := r.store.Get(key); ok {
+			continue
+		}
+		seq++
+		entry := outboxEntry{Seq: seq, Event: event}
+		entries[key] = taskEntry{Outbox: &entry}
+		recorded = append(recorded, entry)
+	}
+	var audited []*domain.AuditEntry
+	for _, entry := range audit {
+		key := auditPrefix + entry.ID
+		if _, ok := r.store.Get(key); ok {
+			continue
+		}
+		stored := *entry
+		stored.Seq = int64(len(r.audit)+len(audited)) + 1
+		entries[key] = taskEntry{Audit: &stored}
+		audited = append(audited, &stored)
+	}
+	var removed []*domain.Task
+	for _, key := range deletes {
+		if task, ok := r.get(key); ok {
+			removed = append(removed, task)
+		}
+	}
+	if err := r.store.Apply(entries, deletes); err != nil {
+		return err
+	}
+	for _, task := range removed {
+		r.unindex(task.ID, task.OwnerID)
+	}
+	for id, task := range puts {
+		r.index(id, task.OwnerID)
+	}
+	r.seq = seq
+	r.outbox = append(r.outbox, recorded...)
+	r.audit = append(r.audit, audited...)
+	return nil
+}
+
+// CreateTask appends a new task and its event to the log.
+func (r *FileTaskRepository) CreateTask(task *domain.Task) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event := domain.NewTaskChangeEvent(task.UpdatedBy, nil, task, task.UpdatedAt)
+	entry := domain.NewTaskChangeAudit(task.UpdatedBy, "", nil, task, task.UpdatedAt)
+	if err := r.write(map[string]*domain.Task{task.ID: task}, nil, nil, []domain.TaskEvent{event}, []*domain.AuditEntry{entry}); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetTask get a task owned by ownerID.
+func (r *FileTaskRepository) GetTask(ownerID, id string) (*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.get(id)
+	if !ok || task.OwnerID != ownerID {
+		return nil, domain.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// GetTasks get all tasks owned by ownerID.
+func (r *FileTaskRepository) GetTasks(ownerID string) ([]*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*domain.Task
+	for _, task := range r.owned(ownerID) {
+		copied := *task
+		tasks = append(tasks, &copied)
+	}
+	return tasks, nil
+}
+
+// QueryTasks get one page of the tasks matching query.
+func (r *FileTaskRepository) QueryTasks(query domain.TaskQuery) (*domain.TaskPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page, err := domain.PageTasks(r.owned(query.OwnerID), query)
+	if err != nil {
+		return nil, err
+	}
+	for i, task := range page.Tasks {
+		copied := *task
+		page.Tasks[i] = &copied
+	}
+	return page, nil
+}
+
+// ScanTasks calls fn with copies of every task of ownerID, oldest first,
+// read from the owner index before fn is first called.
+func (r *FileTaskRepository) ScanTasks(ownerID string, fn func(*domain.Task) error) error {
+	tasks, err := r.GetTasks(ownerID)
+	if err != nil {
+		return err
+	}
+	domain.SortTasks(tasks, domain.TaskQuery{SortBy: domain.SortCreatedAt})
+	for _, task := range tasks {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTagCounts get the tag catalog of ownerID.
+func (r *FileTaskRepository) GetTagCounts(ownerID string) ([]domain.TagCount, error) {
+	tasks, err := r.GetTasks(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.CountTags(tasks), nil
+}
+
+// GetTasksDueBetween get the open tasks of every owner with reminders due in (from, to].
+func (r *FileTaskRepository) GetTasksDueBetween(from, to time.Time) ([]*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*domain.Task
+	for _, task := range r.tasks() {
+		if domain.IsDueBetween(task, from, to) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// UpdateTask appends the new version of a task and its event to the log.
+func (r *FileTaskRepository) UpdateTask(ownerID, id string, task *domain.Task) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.get(id)
+	if !ok || stored.OwnerID != ownerID {
+		return nil, domain.ErrTaskNotFound
+	}
+	if task.Version != 0 && task.Version != stored.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	task.ID = id
+	task.OwnerID = ownerID
+	task.CreatedAt = stored.CreatedAt
+	task.Version = stored.Version + 1
+	event := domain.NewTaskChangeEvent(task.UpdatedBy, stored, task, task.UpdatedAt)
+	entry := domain.NewTaskChangeAudit(task.UpdatedBy, "", stored, task, task.UpdatedAt)
+	if err := r.write(map[string]*domain.Task{id: task}, nil, nil, []domain.TaskEvent{event}, []*domain.AuditEntry{entry}); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ApplyTaskChanges appends the outcome of a batch of changes and their
+// events to the log as a single record.
+func (r *FileTaskRepository) ApplyTaskChanges(ownerID string, changes []domain.TaskChange, atomic bool) ([]domain.TaskChangeResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	staged, results, events, err := domain.ApplyTaskChanges(ownerID, changes, atomic, func(id string) *domain.Task {
+		task, _ := r.get(id)
+		return task
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	puts := make(map[string]*domain.Task)
+	var deletes []string
+	for id, task := range staged {
+		if task == nil {
+			if _, ok := r.get(id); ok {
+				deletes = append(deletes, id)
+			}
+			continue
+		}
+		puts[id] = task
+	}
+	if err := r.write(puts, domain.TrashedTasks(changes, results), deletes, events, domain.AuditTaskChanges(changes, results)); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DeleteTask appends the move of a task to the trash and its event to the log.
+func (r *FileTaskRepository) DeleteTask(ownerID, id, actorID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.get(id)
+	if !ok || task.OwnerID != ownerID {
+		return domain.ErrTaskNotFound
+	}
+	now := time.Now().UTC()
+	trashed := domain.TrashTask(task, actorID, now)
+	event := domain.NewTaskChangeEvent(actorID, task, nil, now)
+	entry := domain.NewTaskChangeAudit(actorID, "", task, nil, now)
+	return r.write(nil, []*domain.Task{trashed}, []string{id}, []domain.TaskEvent{event}, []*domain.AuditEntry{entry})
+}
+
+// GetTrash get the trashed tasks of ownerID.
+func (r *FileTaskRepository) GetTrash(ownerID string) ([]*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []*domain.Task{}
+	for _, entry := range r.store.Items() {
+		if entry.Trashed != nil && entry.Trashed.OwnerID == ownerID {
+			task := *entry.Trashed
+			tasks = append(tasks, &task)
+		}
+	}
+	domain.SortTrash(tasks)
+	return tasks, nil
+}
+
+// RestoreTask appends the move of a trashed task back and its event to the log.
+func (r *FileTaskRepository) RestoreTask(ownerID, id, actorID string) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.store.Get(trashPrefix + id)
+	if !ok || entry.Trashed.OwnerID != ownerID {
+		return nil, domain.ErrTaskNotFound
+	}
+	restored := domain.RestoreTask(entry.Trashed, actorID, time.Now().UTC())
+	event := domain.NewTaskChangeEvent(actorID, entry.Trashed, restored, restored.UpdatedAt)
+	audit := domain.NewTaskChangeAudit(actorID, "", entry.Trashed, restored, restored.UpdatedAt)
+	if err := r.write(map[string]*domain.Task{id: restored}, nil, []string{trashPrefix + id}, []domain.TaskEvent{event}, []*domain.AuditEntry{audit}); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// PurgeTrash appends the removal of the tasks trashed before the given time,
+// with its events and audit entries, to the log.
+func (r *FileTaskRepository) PurgeTrash(before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	var (
+		deletes []string
+		events  []domain.TaskEvent
+		audit   []*domain.AuditEntry
+	)
+	for key, entry := range r.store.Items() {
+		if entry.Trashed != nil && entry.Trashed.DeletedAt.Before(before) {
+			deletes = append(deletes, key)
+			events = append(events, domain.NewTaskPurgeEvent(entry.Trashed, now))
+			audit = append(audit, domain.NewTaskChangeAudit("", domain.AuditPurge, entry.Trashed, nil, now))
+		}
+	}
+	if err := r.write(nil, nil, deletes, events, audit); err != nil {
+		return 0, err
+	}
+	return len(deletes), nil
+}
+
+// PendingEvents get the oldest events of the outbox.
+func (r *FileTaskRepository) PendingEvents(limit int) ([]domain.TaskEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]domain.TaskEvent, 0, min(limit, len(r.outbox)))
+	for _, entry := range r.outbox[:cap(events)] {
+		events = append(events, entry.Event)
+	}
+	return events, nil
+}
+
+// AckEvents appends the removal of published events from the outbox to the log.
+func (r *FileTaskRepository) AckEvents(ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deletes []string
+	acked := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := r.store.Get(outboxPrefix + id); ok {
+			deletes = append(deletes, outboxPrefix+id)
+			acked[id] = struct{}{}
+		}
+	}
+	if len(deletes) == 0 {
+		return nil
+	}
+	if err := r.store.Apply(nil, deletes); err != nil {
+		return err
+	}
+	r.outbox = slices.DeleteFunc(r.outbox, func(entry outboxEntry) bool {
+		_, ok := acked[entry.Event.ID]
+		return ok
+	})
+	return nil
+}
+
+// Compact folds the log into a new snapshot.
+func (r *FileTaskRepository) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store.Compact()
+}
+
+// Close releases the underlying log file.
+func (r *FileTaskRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store.Close()
+}
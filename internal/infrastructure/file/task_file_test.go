@@ -0,0 +1,605 @@
+package file_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/file"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ownerID = "user-1"
+
+func newTask(id string) *domain.Task {
+	return &domain.Task{
+		ID:          id,
+		OwnerID:     ownerID,
+		Title:       "title " + id,
+		Description: "description " + id,
+	}
+}
+
+func openTaskRepo(t *testing.T, dir string, compactEvery int) *file.FileTaskRepository {
+	repo, err := file.NewFileTaskRepository(dir, compactEvery)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestFileTaskRepository_PersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	_, err = repo.CreateTask(newTask("2"))
+	require.NoError(t, err)
+	_, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "updated", Description: "updated", Completed: true})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTask(ownerID, "2", ownerID))
+	require.NoError(t, repo.Close())
+
+	reopened := openTaskRepo(t, dir, 0)
+
+	task, err := reopened.GetTask(ownerID, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", task.Title)
+	assert.True(t, task.Completed)
+
+	_, err = reopened.GetTask(ownerID, "2")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	tasks, err := reopened.GetTasks(ownerID)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
+
+func TestFileTaskRepository_ScopesToOwner(t *testing.T) {
+	repo := openTaskRepo(t, t.TempDir(), 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+
+	_, err = repo.GetTask("user-2", "1")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	_, err = repo.UpdateTask("user-2", "1", newTask("1"))
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.DeleteTask("user-2", "1", "user-2"), domain.ErrTaskNotFound)
+
+	tasks, err := repo.GetTasks("user-2")
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestFileTaskRepository_OwnerIndex(t *testing.T) {
+	dir := t.TempDir()
+	repo := openTaskRepo(t, dir, 0)
+	other := newTask("2")
+	other.OwnerID = "user-2"
+	for _, task := range []*domain.Task{newTask("1"), other, newTask("3")} {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteTask(ownerID, "1", ownerID))
+	require.NoError(t, repo.Close())
+
+	reopened := openTaskRepo(t, dir, 0)
+	query := domain.TaskQuery{OwnerID: ownerID}
+	require.NoError(t, query.Normalize())
+	page, err := reopened.QueryTasks(query)
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "3", page.Tasks[0].ID)
+	page.Tasks[0].Title = "changed"
+
+	_, err = reopened.RestoreTask(ownerID, "1", ownerID)
+	require.NoError(t, err)
+	tasks, err := reopened.GetTasks(ownerID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	stored, err := reopened.GetTask(ownerID, "3")
+	require.NoError(t, err)
+	assert.Equal(t, "title 3", stored.Title)
+}
+
+func TestFileTaskRepository_UpdateChecksVersion(t *testing.T) {
+	repo := openTaskRepo(t, t.TempDir(), 0)
+	task := newTask("1")
+	task.Version = 1
+	_, err := repo.CreateTask(task)
+	require.NoError(t, err)
+
+	updated, err := repo.UpdateTask(ownerID, "1", &domain.Task{Title: "first", Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	_, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "stale", Version: 1})
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	updated, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "forced"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), updated.Version)
+}
+
+func TestFileTaskRepository_ApplyTaskChanges(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := openTaskRepo(t, dir, 0)
+	for _, id := range []string{"1", "2"} {
+		task := newTask(id)
+		task.Version = 1
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	t.Run("Should apply nothing when an atomic batch fails", func(t *testing.T) {
+		_, err := repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+			{Op: domain.BatchComplete, ID: "1", At: now},
+			{Op: domain.BatchDelete, ID: "2", Version: 7},
+		}, true)
+
+		var batchErr *domain.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 1, batchErr.Index)
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+		task, err := repo.GetTask(ownerID, "1")
+		require.NoError(t, err)
+		assert.False(t, task.Completed)
+	})
+
+	t.Run("Should apply the changes that succeed in best-effort mode", func(t *testing.T) {
+		created := newTask("3")
+		created.Version = 1
+		results, err := repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+			{Op: domain.BatchCreate, ID: "3", Task: created},
+			{Op: domain.BatchComplete, ID: "1", Version: 1, At: now},
+			{Op: domain.BatchUpdate, ID: "1", Task: &domain.Task{Title: "renamed", Description: "renamed", Completed: true}, At: now},
+			{Op: domain.BatchDelete, ID: "missing"},
+			{Op: domain.BatchDelete, ID: "2"},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+		assert.Equal(t, int64(3), results[2].Task.Version)
+		assert.ErrorIs(t, results[3].Err, domain.ErrTaskNotFound)
+		assert.Nil(t, results[4].Task)
+	})
+
+	require.NoError(t, repo.Close())
+	reopened := openTaskRepo(t, dir, 0)
+
+	task, err := reopened.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", task.Title)
+	assert.Equal(t, now, task.UpdatedAt)
+	_, err = reopened.GetTask(ownerID, "2")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	_, err = reopened.GetTask(ownerID, "3")
+	assert.NoError(t, err)
+}
+
+func TestFileTaskRepository_CompactsIntoSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := openTaskRepo(t, dir, 3)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, err := repo.CreateTask(newTask(id))
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.Close())
+
+	_, err := os.Stat(filepath.Join(dir, "tasks.snapshot"))
+	assert.NoError(t, err)
+
+	snapshotted := openTaskRepo(t, dir, 3)
+	tasks, err := snapshotted.GetTasks(ownerID)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 4)
+}
+
+func TestFileTaskRepository_RecoversFromTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "tasks.log")
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	info, err := os.Stat(logPath)
+	require.NoError(t, err)
+	firstRecordSize := info.Size()
+
+	repo = openTaskRepo(t, dir, 0)
+	_, err = repo.CreateTask(newTask("2"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	// Simulate a crash halfway through writing the second record.
+	info, err = os.Stat(logPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(logPath, firstRecordSize+(info.Size()-firstRecordSize)/2))
+
+	recovered := openTaskRepo(t, dir, 0)
+
+	_, err = recovered.GetTask(ownerID, "1")
+	assert.NoError(t, err)
+	_, err = recovered.GetTask(ownerID, "2")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	info, err = os.Stat(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, firstRecordSize, info.Size())
+
+	_, err = recovered.CreateTask(newTask("3"))
+	require.NoError(t, err)
+	require.NoError(t, recovered.Close())
+
+	reopened := openTaskRepo(t, dir, 0)
+	tasks, err := reopened.GetTasks(ownerID)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestFileTaskRepository_RecoversFromTornHeader(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "tasks.log")
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	info, err := os.Stat(logPath)
+	require.NoError(t, err)
+
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	recovered := openTaskRepo(t, dir, 0)
+	_, err = recovered.GetTask(ownerID, "1")
+	assert.NoError(t, err)
+
+	after, err := os.Stat(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), after.Size())
+}
+
+func TestFileTaskRepository_DiscardsOversizedRecord(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "tasks.log")
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	info, err := os.Stat(logPath)
+	require.NoError(t, err)
+
+	// A corrupted header claiming a 4 GiB payload must not be allocated.
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	recovered := openTaskRepo(t, dir, 0)
+	_, err = recovered.GetTask(ownerID, "1")
+	assert.NoError(t, err)
+
+	after, err := os.Stat(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), after.Size())
+}
+
+func TestFileTaskRepository_DiscardsCorruptedRecord(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "tasks.log")
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	data[len(data)-2] ^= 0xff
+	require.NoError(t, os.WriteFile(logPath, data, 0o600))
+
+	recovered := openTaskRepo(t, dir, 0)
+	_, err = recovered.GetTask(ownerID, "1")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+}
+
+func TestFileTaskRepository_RefusesCorruptedRecordBeforeOthers(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "tasks.log")
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	_, err = repo.CreateTask(newTask("2"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	data[10] ^= 0xff
+	require.NoError(t, os.WriteFile(logPath, data, 0o600))
+
+	_, err = file.NewFileTaskRepository(dir, 0)
+	assert.ErrorContains(t, err, "offset 0")
+
+	after, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, data, after, "the log is left for repair")
+}
+
+func TestFileTaskRepository_DiscardsZeroedTail(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "tasks.log")
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	info, err := os.Stat(logPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(logPath, info.Size()+64))
+
+	recovered := openTaskRepo(t, dir, 0)
+	_, err = recovered.GetTask(ownerID, "1")
+	assert.NoError(t, err)
+
+	after, err := os.Stat(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), after.Size())
+}
+
+func TestFileTaskRepository_QueryTasks(t *testing.T) {
+	repo := openTaskRepo(t, t.TempDir(), 0)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"1", "2", "3", "4", "5"} {
+		task := newTask(id)
+		task.Completed = i%2 == 0
+		task.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		task.UpdatedAt = task.CreatedAt
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	query := domain.TaskQuery{OwnerID: ownerID, SortBy: domain.SortCreatedAt, Descending: true, Limit: 2}
+	var ids []string
+	for {
+		page, err := repo.QueryTasks(query)
+		require.NoError(t, err)
+		for _, task := range page.Tasks {
+			ids = append(ids, task.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"5", "4", "3", "2", "1"}, ids)
+
+	completed := true
+	page, err := repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, Completed: &completed, Title: "TITLE 3", SortBy: domain.SortCreatedAt, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "3", page.Tasks[0].ID)
+
+	_, err = repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, SortBy: domain.SortUpdatedAt, Limit: 2, Cursor: query.Cursor})
+	assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+}
+
+func TestFileTaskRepository_DueDates(t *testing.T) {
+	repo := openTaskRepo(t, t.TempDir(), 0)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tasks := []*domain.Task{
+		{ID: "1", OwnerID: ownerID, TaskSchedule: domain.TaskSchedule{DueAt: &past, TimeZone: "Europe/Madrid", Priority: domain.PriorityHigh, ReminderMinutes: []int{10, 90}, Recurrence: "FREQ=DAILY"}},
+		{ID: "2", OwnerID: ownerID, Completed: true, TaskSchedule: domain.TaskSchedule{DueAt: &past, ReminderMinutes: []int{10}}},
+		{ID: "3", OwnerID: ownerID, TaskSchedule: domain.TaskSchedule{DueAt: &future}},
+		{ID: "4", OwnerID: ownerID},
+		{ID: "5", OwnerID: "user-2", TaskSchedule: domain.TaskSchedule{DueAt: &future, ReminderMinutes: []int{5}}},
+	}
+	for _, task := range tasks {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	task, err := repo.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.Equal(t, tasks[0].TaskSchedule, task.TaskSchedule)
+
+	ids := func(tasks []*domain.Task) []string {
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	page, err := repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, Overdue: true, Now: now, SortBy: domain.SortCreatedAt, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, ids(page.Tasks))
+
+	page, err = repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, DueBefore: &now, SortBy: domain.SortCreatedAt, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, ids(page.Tasks))
+
+	due, err := repo.GetTasksDueBetween(past.Add(-time.Minute), future)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "5"}, ids(due))
+
+	updated, err := repo.UpdateTask(ownerID, "1", &domain.Task{Title: "title", Description: "description"})
+	require.NoError(t, err)
+	assert.Nil(t, updated.DueAt)
+	assert.Empty(t, updated.ReminderMinutes)
+}
+
+func TestFileTaskRepository_Links(t *testing.T) {
+	dir := t.TempDir()
+	links := domain.TaskLinks{ParentID: "1", BlockedBy: []string{"2", "3"}}
+
+	repo := openTaskRepo(t, dir, 0)
+	task := newTask("4")
+	task.TaskLinks = links
+	_, err := repo.CreateTask(task)
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	reopened := openTaskRepo(t, dir, 0)
+
+	stored, err := reopened.GetTask(ownerID, "4")
+	require.NoError(t, err)
+	assert.Equal(t, links, stored.TaskLinks)
+}
+
+func pendingEvents(t *testing.T, repo *file.FileTaskRepository) []string {
+	events, err := repo.PendingEvents(10)
+	require.NoError(t, err)
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.ID+" "+event.Type)
+	}
+	return ids
+}
+
+func TestFileTaskRepository_Outbox(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := openTaskRepo(t, dir, 4)
+	task := newTask("1")
+	task.Version = 1
+	task.UpdatedBy = ownerID
+	_, err := repo.CreateTask(task)
+	require.NoError(t, err)
+	_, err = repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchComplete, ID: "1", At: now, ActorID: "user-2"},
+		{Op: domain.BatchDelete, ID: "missing"},
+	}, true)
+	require.Error(t, err)
+	_, err = repo.ApplyTaskChanges(ownerID, []domain.TaskChange{{Op: domain.BatchComplete, ID: "1", At: now, ActorID: "user-2"}}, true)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTask(ownerID, "1", ownerID))
+
+	assert.Equal(t, []string{"1@1 task.created", "1@2 task.completed", "1@3 task.deleted"}, pendingEvents(t, repo),
+		"the failed batch records nothing")
+	events, err := repo.PendingEvents(2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "user-2", events[1].ActorID)
+	assert.True(t, events[1].Task.Completed)
+
+	require.NoError(t, repo.AckEvents([]string{events[0].ID, events[1].ID, "unknown"}))
+	require.NoError(t, repo.Close())
+
+	// The log was compacted on the way; acknowledged events stay removed.
+	reopened := openTaskRepo(t, dir, 4)
+	assert.Equal(t, []string{"1@3 task.deleted"}, pendingEvents(t, reopened))
+	tasks, err := reopened.GetTasks(ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestFileTaskRepository_OutboxSurvivesCrash(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "tasks.log")
+
+	repo := openTaskRepo(t, dir, 0)
+	_, err := repo.CreateTask(newTask("1"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	info, err := os.Stat(logPath)
+	require.NoError(t, err)
+	firstRecordSize := info.Size()
+
+	repo = openTaskRepo(t, dir, 0)
+	_, err = repo.CreateTask(newTask("2"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	// Simulate a crash halfway through writing the second task and its event.
+	info, err = os.Stat(logPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(logPath, firstRecordSize+(info.Size()-firstRecordSize)/2))
+
+	// The event of the first task was never acknowledged, so it is still
+	// pending; the torn write left neither the task nor its event.
+	recovered := openTaskRepo(t, dir, 0)
+	assert.Equal(t, []string{"1@0 task.created"}, pendingEvents(t, recovered))
+	_, err = recovered.GetTask(ownerID, "2")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	_, err = recovered.CreateTask(newTask("3"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1@0 task.created", "3@0 task.created"}, pendingEvents(t, recovered))
+}
+
+func TestFileTaskRepository_Trash(t *testing.T) {
+	dir := t.TempDir()
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := openTaskRepo(t, dir, 0)
+	for _, id := range []string{"1", "2", "3"} {
+		task := newTask(id)
+		task.Version = 1
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteTask(ownerID, "1", ownerID))
+	_, err := repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchComplete, ID: "2", At: deletedAt, ActorID: "user-2"},
+		{Op: domain.BatchDelete, ID: "2", At: deletedAt, ActorID: "user-2"},
+	}, true)
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	reopened := openTaskRepo(t, dir, 0)
+	tasks, err := reopened.GetTasks(ownerID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1, "trashed tasks are left out of reads")
+	trash, err := reopened.GetTrash(ownerID)
+	require.NoError(t, err)
+	require.Len(t, trash, 2)
+	assert.Equal(t, "1", trash[0].ID, "most recently deleted first")
+	assert.Equal(t, "2", trash[1].ID)
+	assert.True(t, trash[1].Completed)
+	assert.Equal(t, int64(3), trash[1].Version)
+	assert.Equal(t, deletedAt, *trash[1].DeletedAt)
+
+	restored, err := reopened.RestoreTask(ownerID, "1", ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), restored.Version)
+	_, err = reopened.RestoreTask(ownerID, "1", ownerID)
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	purged, err := reopened.PurgeTrash(deletedAt.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	require.NoError(t, reopened.Compact())
+	require.NoError(t, reopened.Close())
+
+	compacted := openTaskRepo(t, dir, 0)
+	trash, err = compacted.GetTrash(ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+	task, err := compacted.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.Nil(t, task.DeletedAt)
+}
@@ -0,0 +1,154 @@
+package file
+
+import (
+	"sync"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/utils"
+)
+
+// FileUserRepository is a UserRepository persisted with the same log and
+// snapshot engine as FileTaskRepository. Usernames are kept in a unique
+// index that is rebuilt from the stored users at startup.
+type FileUserRepository struct {
+	store      *logStore[domain.User]
+	byUsername map[string]string
+	mu         sync.RWMutex
+	appCrypto  *utils.DefaultAppCrypto
+	jwt        *utils.JWTManager
+}
+
+// NewFileUserRepository opens (or creates) the user log and snapshot in dir
+// and replays them into memory.
+func NewFileUserRepository(dir string, compactEvery int, appCrypto *utils.DefaultAppCrypto, jwt *utils.JWTManager) (*FileUserRepository, error) {
+	store, err := openLogStore[domain.User](dir, "users", compactEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	byUsername := make(map[string]string, len(store.Items()))
+	for id, user := range store.Items() {
+		byUsername[user.Username] = id
+	}
+
+	return &FileUserRepository{
+		store:      store,
+		byUsername: byUsername,
+		appCrypto:  appCrypto,
+		jwt:        jwt,
+	}, nil
+}
+
+// Create stores a user with a hashed password, rejecting taken usernames.
+func (r *FileUserRepository) Create(user *domain.User) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUsername[user.Username]; ok {
+		return "", domain.ErrUsernameTaken
+	}
+
+	password, err := r.appCrypto.HashPassword(user.Password)
+	if err != nil {
+		return "", err
+	}
+
+	user.Password = password
+	if err := r.store.Put(user.ID, *user); err != nil {
+		return "", err
+	}
+	r.byUsername[user.Username] = user.ID
+
+	return r.jwt.GenerateJWT(user.ID, user.Username, user.Role)
+}
+
+// Login looks the user up by username and checks the password.
+func (r *FileUserRepository) Login(user domain.User) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byUsername[user.Username]
+	if !ok {
+		return "", domain.ErrInvalidCredentials
+	}
+
+	stored, _ := r.store.Get(id)
+	if !r.appCrypto.CheckPasswordHash(user.Password, stored.Password) {
+		return "", domain.ErrInvalidCredentials
+	}
+	if stored.Disabled {
+		return "", domain.ErrUserDisabled
+	}
+
+	return r.jwt.GenerateJWT(stored.ID, stored.Username, stored.Role)
+}
+
+// Get returns the user with the given ID.
+func (r *FileUserRepository) Get(id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.store.Get(id)
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// GetByUsername returns the user with the given username.
+func (r *FileUserRepository) GetByUsername(username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byUsername[username]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	user, _ := r.store.Get(id)
+	return &user, nil
+}
+
+// List returns every registered user.
+func (r *FileUserRepository) List() ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(r.store.Items()))
+	for _, user := range r.store.Items() {
+		users = append(users, &user)
+	}
+	return users, nil
+}
+
+// SetDisabled appends the new state of a user account to the log.
+func (r *FileUserRepository) SetDisabled(id string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store.Get(id)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Disabled = disabled
+	return r.store.Put(id, user)
+}
+
+// SetRole appends the new role of a user account to the log.
+func (r *FileUserRepository) SetRole(id, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store.Get(id)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Role = role
+	return r.store.Put(id, user)
+}
+
+// Close releases the underlying log file.
+func (r *FileUserRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store.Close()
+}
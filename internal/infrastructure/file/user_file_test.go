@@ -0,0 +1,102 @@
+package file_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/file"
+	"todo-list-task/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var jwtManager = utils.NewJWTManager("test-secret", time.Hour)
+
+func openUserRepo(t *testing.T, dir string) *file.FileUserRepository {
+	repo, err := file.NewFileUserRepository(dir, 0, utils.NewHashPassword(app.BcryptCrypto{}, bcrypt.MinCost), jwtManager)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestFileUserRepository_PersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := openUserRepo(t, dir)
+	token, err := repo.Create(&domain.User{ID: "user-1", Username: "cristianm", Password: "password"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	require.NoError(t, repo.Close())
+
+	reopened := openUserRepo(t, dir)
+
+	token, err = reopened.Login(domain.User{Username: "cristianm", Password: "password"})
+	assert.NoError(t, err)
+
+	claims, err := jwtManager.ValidateJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.(*utils.Claims).UserID)
+
+	user, err := reopened.GetByUsername("cristianm")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+	_, err = reopened.GetByUsername("unknown")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestFileUserRepository_RejectsDuplicateUsername(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := openUserRepo(t, dir)
+	_, err := repo.Create(&domain.User{ID: "user-1", Username: "cristianm", Password: "password"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	reopened := openUserRepo(t, dir)
+	_, err = reopened.Create(&domain.User{ID: "user-2", Username: "cristianm", Password: "another"})
+	assert.ErrorIs(t, err, domain.ErrUsernameTaken)
+}
+
+func TestFileUserRepository_LoginRejectsBadCredentials(t *testing.T) {
+	repo := openUserRepo(t, t.TempDir())
+	_, err := repo.Create(&domain.User{ID: "user-1", Username: "cristianm", Password: "password"})
+	require.NoError(t, err)
+
+	_, err = repo.Login(domain.User{Username: "cristianm", Password: "wrong"})
+	assert.Error(t, err)
+
+	_, err = repo.Login(domain.User{Username: "unknown", Password: "password"})
+	assert.Error(t, err)
+}
+
+func TestFileUserRepository_DisableAndList(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := openUserRepo(t, dir)
+	_, err := repo.Create(&domain.User{ID: "user-1", Username: "cristianm", Password: "password", Role: domain.RoleAdmin})
+	require.NoError(t, err)
+	require.NoError(t, repo.SetDisabled("user-1", true))
+	assert.ErrorIs(t, repo.SetDisabled("missing", true), domain.ErrUserNotFound)
+	require.NoError(t, repo.Close())
+
+	reopened := openUserRepo(t, dir)
+
+	_, err = reopened.Login(domain.User{Username: "cristianm", Password: "password"})
+	assert.ErrorIs(t, err, domain.ErrUserDisabled)
+
+	users, err := reopened.List()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.True(t, users[0].Disabled)
+	assert.Equal(t, domain.RoleAdmin, users[0].Role)
+
+	require.NoError(t, reopened.SetDisabled("user-1", false))
+	token, err := reopened.Login(domain.User{Username: "cristianm", Password: "password"})
+	require.NoError(t, err)
+	claims, err := jwtManager.ValidateJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, claims.(*utils.Claims).Role)
+}
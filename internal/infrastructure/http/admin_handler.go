@@ -0,0 +1,90 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+)
+
+// AdminHandler serves the endpoints reserved to the admin role.
+type AdminHandler struct {
+	users *app.UserService
+	tasks *app.TaskService
+}
+
+func NewAdminHandler(users *app.UserService, tasks *app.TaskService) *AdminHandler {
+	return &AdminHandler{users: users, tasks: tasks}
+}
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	users, err := h.users.ListUsers()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	h.setUserDisabled(c, true)
+}
+
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	h.setUserDisabled(c, false)
+}
+
+func (h *AdminHandler) setUserDisabled(c *gin.Context, disabled bool) {
+	err := h.users.SetUserDisabled(c.Param("id"), disabled)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User updated successfully"})
+}
+
+// SetUserRole promotes or demotes a user.
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	var request domain.RoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	if err := h.users.SetUserRole(c.Param("id"), request.Role); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User updated successfully"})
+}
+
+func (h *AdminHandler) GetUserTasks(c *gin.Context) {
+	tasks, err := h.tasks.GetTasks(c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetAuditLog returns one page of the audit log of every user, newest
+// first, filtered by task, owner, actor, action and time.
+func (h *AdminHandler) GetAuditLog(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	page, err := h.tasks.GetAuditLog(query)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
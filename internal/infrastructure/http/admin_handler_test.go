@@ -0,0 +1,220 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/middleware"
+	"todo-list-task/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type adminMocks struct {
+	users  *mocks.UserRepository
+	tasks  *mocks.TaskRepository
+	tokens *mocks.RefreshTokenRepository
+	lists  *mocks.ListRepository
+	audit  *memory.InMemoryAuditRepository
+}
+
+func configurationAdmin() (*adminMocks, *httpHandler.AdminHandler, *gin.Engine) {
+	m := &adminMocks{
+		users:  new(mocks.UserRepository),
+		tasks:  new(mocks.TaskRepository),
+		tokens: new(mocks.RefreshTokenRepository),
+		lists:  new(mocks.ListRepository),
+		audit:  memory.NewInMemoryAuditRepository(),
+	}
+	tokenService := app.NewTokenService(m.tokens, jwtManager, time.Hour)
+	userService := app.NewUserService(m.users, tokenService)
+	handler := httpHandler.NewAdminHandler(userService, app.NewTaskService(m.tasks, m.lists, m.audit))
+
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+
+	return m, handler, router
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	m, handler, router := configurationAdmin()
+	router.GET("/admin/users", handler.ListUsers)
+	m.users.On("List").Return([]*domain.User{
+		{ID: "user-1", Username: "cristianm", Password: "hash", Role: domain.RoleAdmin},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/users", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "hash")
+
+	var users []*domain.UserInfo
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &users))
+	assert.Equal(t, []*domain.UserInfo{{ID: "user-1", Username: "cristianm", Role: domain.RoleAdmin}}, users)
+}
+
+func TestAdminHandler_DisableUser(t *testing.T) {
+	testCases := []struct {
+		name       string
+		route      string
+		disabled   bool
+		err        error
+		statusCode int
+	}{
+		{
+			name:       "Should disable the user and revoke its refresh tokens",
+			route:      "/admin/users/user-1/disable",
+			disabled:   true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Should enable the user",
+			route:      "/admin/users/user-1/enable",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Should return not found when the user does not exist",
+			route:      "/admin/users/user-1/disable",
+			disabled:   true,
+			err:        domain.ErrUserNotFound,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "should return an error when repository return an error",
+			route:      "/admin/users/user-1/enable",
+			err:        assert.AnError,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, handler, router := configurationAdmin()
+			router.POST("/admin/users/:id/disable", handler.DisableUser)
+			router.POST("/admin/users/:id/enable", handler.EnableUser)
+			m.users.On("SetDisabled", "user-1", tc.disabled).Return(tc.err)
+			m.tokens.On("RevokeUser", "user-1").Return(nil)
+
+			req, _ := http.NewRequest("POST", tc.route, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.statusCode, resp.Code)
+			if tc.disabled && tc.err == nil {
+				m.tokens.AssertCalled(t, "RevokeUser", "user-1")
+			} else {
+				m.tokens.AssertNotCalled(t, "RevokeUser", "user-1")
+			}
+		})
+	}
+}
+
+func TestAdminHandler_SetUserRole(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		err        error
+		statusCode int
+	}{
+		{name: "Should promote the user and revoke its refresh tokens", body: `{"role":"admin"}`, statusCode: http.StatusOK},
+		{name: "Should return bad request for an unknown role", body: `{"role":"root"}`, statusCode: http.StatusBadRequest},
+		{name: "Should return bad request without a role", body: `{}`, statusCode: http.StatusBadRequest},
+		{name: "Should return not found when the user does not exist", body: `{"role":"user"}`, err: domain.ErrUserNotFound, statusCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, handler, router := configurationAdmin()
+			router.PUT("/admin/users/:id/role", handler.SetUserRole)
+			m.users.On("SetRole", "user-1", mock.Anything).Return(tc.err)
+			m.tokens.On("RevokeUser", "user-1").Return(nil)
+
+			req, _ := http.NewRequest("PUT", "/admin/users/user-1/role", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.statusCode, resp.Code)
+			if tc.statusCode == http.StatusOK {
+				m.users.AssertCalled(t, "SetRole", "user-1", domain.RoleAdmin)
+				m.tokens.AssertCalled(t, "RevokeUser", "user-1")
+			} else {
+				m.tokens.AssertNotCalled(t, "RevokeUser", "user-1")
+			}
+		})
+	}
+}
+
+func TestAdminHandler_GetUserTasks(t *testing.T) {
+	m, handler, router := configurationAdmin()
+	router.GET("/admin/users/:id/tasks", handler.GetUserTasks)
+	m.tasks.On("GetTasks", "user-2").Return([]*domain.Task{{ID: "1", OwnerID: "user-2"}}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/users/user-2/tasks", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var tasks []*domain.Task
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &tasks))
+	assert.Len(t, tasks, 1)
+}
+
+func TestAdminHandler_GetAuditLog(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name       string
+		query      string
+		ids        []string
+		statusCode int
+	}{
+		{name: "should return every entry, newest first", query: "", ids: []string{"audit-3", "audit-2", "audit-1"}, statusCode: http.StatusOK},
+		{name: "should filter by owner", query: "?owner_id=user-2", ids: []string{"audit-3"}, statusCode: http.StatusOK},
+		{name: "should filter by actor and action", query: "?actor_id=user-1&action=update", ids: []string{"audit-2"}, statusCode: http.StatusOK},
+		{name: "should filter by time", query: "?since=2024-01-01T01:00:00Z&until=2024-01-01T02:00:00Z", ids: []string{"audit-2"}, statusCode: http.StatusOK},
+		{name: "should return bad request for a malformed time", query: "?since=yesterday", statusCode: http.StatusBadRequest},
+		{name: "should return bad request for an unknown action", query: "?action=rename", statusCode: http.StatusBadRequest},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, handler, router := configurationAdmin()
+			router.GET("/admin/audit", handler.GetAuditLog)
+			first := &domain.Task{ID: "task-1", OwnerID: "user-1", Title: "title", Version: 1}
+			second := &domain.Task{ID: "task-1", OwnerID: "user-1", Title: "renamed", Version: 2}
+			other := &domain.Task{ID: "task-2", OwnerID: "user-2", Title: "title", Version: 1}
+			entries := []domain.AuditEntry{
+				domain.NewAuditEntry("audit-1", "user-1", "", nil, first, at),
+				domain.NewAuditEntry("audit-2", "user-1", "", first, second, at.Add(time.Hour)),
+				domain.NewAuditEntry("audit-3", "user-2", "", nil, other, at.Add(2*time.Hour)),
+			}
+			assert.NoError(t, m.audit.AppendAudit([]*domain.AuditEntry{&entries[0], &entries[1], &entries[2]}))
+
+			req, _ := http.NewRequest("GET", "/admin/audit"+testCase.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code, resp.Body.String())
+			if testCase.ids != nil {
+				var page domain.AuditPage
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+				ids := []string{}
+				for _, entry := range page.Entries {
+					ids = append(ids, entry.ID)
+				}
+				assert.Equal(t, testCase.ids, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"todo-list-task/internal/utils"
+)
+
+type JWKSHandler struct {
+	keys *utils.KeyRing
+}
+
+func NewJWKSHandler(keys *utils.KeyRing) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS publishes the public signing keys so other services can verify tokens offline.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
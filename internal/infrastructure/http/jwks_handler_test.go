@@ -0,0 +1,41 @@
+package http_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSHandler_GetJWKS(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := utils.NewAsymmetricKey("2026-10", private)
+	require.NoError(t, err)
+	ring, err := utils.NewKeyRing("2026-10", key)
+	require.NoError(t, err)
+
+	router := gin.Default()
+	router.GET("/.well-known/jwks.json", httpHandler.NewJWKSHandler(ring).GetJWKS)
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var set utils.JWKSet
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "2026-10", set.Keys[0].Kid)
+	assert.Equal(t, "OKP", set.Keys[0].Kty)
+	assert.Equal(t, "EdDSA", set.Keys[0].Alg)
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/middleware"
+)
+
+// ListHandler serves the endpoints that manage lists and their members.
+// The tasks of a list are read through GET /tasks?list_id=.
+type ListHandler struct {
+	service *app.ListService
+}
+
+func NewListHandler(service *app.ListService) *ListHandler {
+	return &ListHandler{service: service}
+}
+
+func (h *ListHandler) CreateList(c *gin.Context) {
+	var request domain.ListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	list, err := h.service.CreateList(middleware.CurrentUserID(c), request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+// GetLists returns the lists the user owns or is a member of.
+func (h *ListHandler) GetLists(c *gin.Context) {
+	lists, err := h.service.GetLists(middleware.CurrentUserID(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lists": lists})
+}
+
+func (h *ListHandler) GetList(c *gin.Context) {
+	list, err := h.service.GetList(middleware.CurrentUserID(c), c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ListHandler) UpdateList(c *gin.Context) {
+	var request domain.ListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	list, err := h.service.UpdateList(middleware.CurrentUserID(c), c.Param("id"), request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ListHandler) DeleteList(c *gin.Context) {
+	if err := h.service.DeleteList(middleware.CurrentUserID(c), c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "List deleted successfully"})
+}
+
+// ShareList shares the list with the user named in the path, as viewer or
+// editor. Sharing it again changes the role.
+func (h *ListHandler) ShareList(c *gin.Context) {
+	var request domain.ListMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	list, err := h.service.ShareList(middleware.CurrentUserID(c), c.Param("id"), c.Param("username"), request.Role)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// UnshareList removes the user named in the path from the list.
+func (h *ListHandler) UnshareList(c *gin.Context) {
+	if err := h.service.UnshareList(middleware.CurrentUserID(c), c.Param("id"), c.Param("username")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "List member removed successfully"})
+}
@@ -0,0 +1,209 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/middleware"
+	"todo-list-task/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type listMocks struct {
+	lists *mocks.ListRepository
+	tasks *mocks.TaskRepository
+	users *mocks.UserRepository
+}
+
+func configurationList() (*listMocks, *httpHandler.ListHandler, *gin.Engine) {
+	m := &listMocks{
+		lists: new(mocks.ListRepository),
+		tasks: new(mocks.TaskRepository),
+		users: new(mocks.UserRepository),
+	}
+	handler := httpHandler.NewListHandler(app.NewListService(m.lists, m.tasks, m.users))
+
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler(), MockAuthMiddleware())
+	router.POST("/lists", handler.CreateList)
+	router.GET("/lists/:id", handler.GetList)
+	router.DELETE("/lists/:id", handler.DeleteList)
+	router.PUT("/lists/:id/members/:username", handler.ShareList)
+	router.DELETE("/lists/:id/members/:username", handler.UnshareList)
+
+	return m, handler, router
+}
+
+// sharedList is a list of mockUserID shared with ana as viewer, or a list
+// of ana shared with mockUserID when owned is false.
+func sharedList(owned bool) *domain.List {
+	if owned {
+		return &domain.List{ID: "list-1", OwnerID: mockUserID, Name: "home", Members: []domain.ListMember{
+			{UserID: "user-2", Username: "ana", Role: domain.ListViewer},
+		}}
+	}
+	return &domain.List{ID: "list-1", OwnerID: "user-2", Name: "home", Members: []domain.ListMember{
+		{UserID: mockUserID, Username: "cristianm", Role: domain.ListEditor},
+		{UserID: "user-3", Username: "luis", Role: domain.ListViewer},
+	}}
+}
+
+func TestListHandler_CreateList(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{name: "Should create the list of the user", body: `{"name": "home"}`, statusCode: http.StatusCreated},
+		{name: "Should throw an error when the name is missing", body: `{}`, statusCode: http.StatusBadRequest},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, _, router := configurationList()
+			m.lists.On("CreateList", mock.MatchedBy(func(list *domain.List) bool {
+				return list.OwnerID == mockUserID && list.Name == "home"
+			})).Return(func(list *domain.List) (*domain.List, error) { return list, nil })
+
+			req, _ := http.NewRequest("POST", "/lists", strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+		})
+	}
+}
+
+func TestListHandler_GetList(t *testing.T) {
+	testCases := []struct {
+		name       string
+		list       *domain.List
+		statusCode int
+	}{
+		{name: "Should return a list the user owns", list: sharedList(true), statusCode: http.StatusOK},
+		{name: "Should return a list shared with the user", list: sharedList(false), statusCode: http.StatusOK},
+		{name: "Should return not found when the user is not a member", list: &domain.List{ID: "list-1", OwnerID: "user-2"}, statusCode: http.StatusNotFound},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, _, router := configurationList()
+			m.lists.On("GetList", "list-1").Return(testCase.list, nil)
+
+			req, _ := http.NewRequest("GET", "/lists/list-1", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+		})
+	}
+}
+
+func TestListHandler_DeleteList(t *testing.T) {
+	testCases := []struct {
+		name       string
+		list       *domain.List
+		tasks      []*domain.Task
+		statusCode int
+	}{
+		{name: "Should delete an empty list", list: sharedList(true), tasks: []*domain.Task{}, statusCode: http.StatusOK},
+		{name: "Should return conflict when the list has tasks", list: sharedList(true), tasks: []*domain.Task{{ID: "1"}}, statusCode: http.StatusConflict},
+		{name: "Should return forbidden when the user is a member", list: sharedList(false), statusCode: http.StatusForbidden},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, _, router := configurationList()
+			m.lists.On("GetList", "list-1").Return(testCase.list, nil)
+			m.lists.On("DeleteList", "list-1").Return(nil)
+			m.tasks.On("QueryTasks", mock.MatchedBy(func(query domain.TaskQuery) bool {
+				return query.OwnerID == mockUserID && query.ListID == "list-1"
+			})).Return(&domain.TaskPage{Tasks: testCase.tasks}, nil)
+
+			req, _ := http.NewRequest("DELETE", "/lists/list-1", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode == http.StatusOK {
+				m.lists.AssertCalled(t, "DeleteList", "list-1")
+			} else {
+				m.lists.AssertNotCalled(t, "DeleteList", "list-1")
+			}
+		})
+	}
+}
+
+func TestListHandler_ShareList(t *testing.T) {
+	users := []*domain.User{
+		{ID: mockUserID, Username: "cristianm"},
+		{ID: "user-2", Username: "ana"},
+	}
+	testCases := []struct {
+		name       string
+		list       *domain.List
+		username   string
+		body       string
+		statusCode int
+	}{
+		{name: "Should share the list as editor", list: sharedList(true), username: "ana", body: `{"role": "editor"}`, statusCode: http.StatusOK},
+		{name: "Should throw an error when the role is unknown", list: sharedList(true), username: "ana", body: `{"role": "owner"}`, statusCode: http.StatusBadRequest},
+		{name: "Should return not found when the user does not exist", list: sharedList(true), username: "nobody", body: `{"role": "viewer"}`, statusCode: http.StatusNotFound},
+		{name: "Should throw an error when sharing with the owner", list: sharedList(true), username: "cristianm", body: `{"role": "viewer"}`, statusCode: http.StatusBadRequest},
+		{name: "Should return forbidden when the user is a member", list: sharedList(false), username: "ana", body: `{"role": "viewer"}`, statusCode: http.StatusForbidden},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, _, router := configurationList()
+			m.lists.On("GetList", "list-1").Return(testCase.list, nil)
+			for _, user := range users {
+				m.users.On("GetByUsername", user.Username).Return(user, nil)
+			}
+			m.users.On("GetByUsername", mock.Anything).Return(nil, domain.ErrUserNotFound)
+			m.lists.On("SetMember", "list-1", domain.ListMember{UserID: "user-2", Username: "ana", Role: domain.ListEditor}).Return(nil)
+
+			req, _ := http.NewRequest("PUT", "/lists/list-1/members/"+testCase.username, strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+		})
+	}
+}
+
+func TestListHandler_UnshareList(t *testing.T) {
+	testCases := []struct {
+		name       string
+		list       *domain.List
+		username   string
+		userID     string
+		statusCode int
+	}{
+		{name: "Should let the owner remove a member", list: sharedList(true), username: "ana", userID: "user-2", statusCode: http.StatusOK},
+		{name: "Should let a member leave the list", list: sharedList(false), username: "cristianm", userID: mockUserID, statusCode: http.StatusOK},
+		{name: "Should return forbidden when a member removes another member", list: sharedList(false), username: "luis", userID: "user-3", statusCode: http.StatusForbidden},
+		{name: "Should return not found when the user is not a member", list: sharedList(true), username: "luis", statusCode: http.StatusNotFound},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, _, router := configurationList()
+			m.lists.On("GetList", "list-1").Return(testCase.list, nil)
+			m.lists.On("RemoveMember", "list-1", testCase.userID).Return(nil)
+
+			req, _ := http.NewRequest("DELETE", "/lists/list-1/members/"+testCase.username, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode == http.StatusOK {
+				m.lists.AssertCalled(t, "RemoveMember", "list-1", testCase.userID)
+			} else {
+				m.lists.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
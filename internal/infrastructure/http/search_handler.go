@@ -0,0 +1,43 @@
+package http
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/middleware"
+)
+
+// SearchHandler serves the full-text search over the user's tasks.
+type SearchHandler struct {
+	service *app.SearchService
+}
+
+func NewSearchHandler(service *app.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// SearchTasks returns the tasks matching the q query parameter, most
+// relevant first, as {"results": [{"task": ..., "score": ...}]}. limit caps
+// the number of results.
+func (h *SearchHandler) SearchTasks(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			_ = c.Error(domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid limit %q", value)))
+			return
+		}
+		limit = n
+	}
+
+	results, err := h.service.SearchTasks(middleware.CurrentUserID(c), c.Query("q"), limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,62 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/middleware"
+	"todo-list-task/internal/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchHandler_SearchTasks(t *testing.T) {
+	index := search.NewIndex()
+	index.Put(&domain.Task{ID: "1", OwnerID: mockUserID, Title: "Comprar leche", Description: "Semidesnatada"})
+	index.Put(&domain.Task{ID: "2", OwnerID: mockUserID, Title: "Llamar", Description: "Preguntar por la leche"})
+	index.Put(&domain.Task{ID: "3", OwnerID: "user-2", Title: "Comprar leche", Description: "Entera"})
+
+	testCases := []struct {
+		name       string
+		url        string
+		expected   []string
+		statusCode int
+	}{
+		{name: "Should return the user's matches by relevance", url: "/tasks/search?q=Leche", expected: []string{"1", "2"}, statusCode: http.StatusOK},
+		{name: "Should limit the results", url: "/tasks/search?q=leche&limit=1", expected: []string{"1"}, statusCode: http.StatusOK},
+		{name: "Should return no results", url: "/tasks/search?q=pan", expected: []string{}, statusCode: http.StatusOK},
+		{name: "Should throw an error when q is missing", url: "/tasks/search?q=%20", statusCode: http.StatusBadRequest},
+		{name: "Should throw an error when limit is invalid", url: "/tasks/search?q=leche&limit=0", statusCode: http.StatusBadRequest},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := httpHandler.NewSearchHandler(app.NewSearchService(index))
+			router := gin.Default()
+			router.Use(middleware.ErrorHandler(), MockAuthMiddleware())
+			router.GET("/tasks/search", handler.SearchTasks)
+
+			req, _ := http.NewRequest("GET", testCase.url, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.expected != nil {
+				var response struct {
+					Results []search.Result `json:"results"`
+				}
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+				ids := []string{}
+				for _, result := range response.Results {
+					ids = append(ids, result.Task.ID)
+					assert.Positive(t, result.Score)
+				}
+				assert.Equal(t, testCase.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"io"
+	"net/http"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/middleware"
+)
+
+// Types of the stream messages that carry no task event.
+const (
+	// streamReset tells the client that events may have been missed since
+	// the event it resumed from, so it should reload its tasks.
+	streamReset = "reset"
+	// streamHeartbeat keeps idle WebSocket connections open.
+	streamHeartbeat = "heartbeat"
+)
+
+// streamReadLimit bounds the WebSocket messages read from clients, which
+// are ignored anyway.
+const streamReadLimit = 4 << 10
+
+// StreamHandler pushes the changes to the user's tasks as they happen, over
+// Server-Sent Events or a WebSocket, and issues the tickets browsers open
+// them with.
+type StreamHandler struct {
+	stream    *app.TaskStream
+	tickets   *app.StreamTickets
+	heartbeat time.Duration
+	// upgrader keeps the default origin check, which only lets pages of this
+	// host open the WebSocket.
+	upgrader websocket.Upgrader
+}
+
+func NewStreamHandler(stream *app.TaskStream, tickets *app.StreamTickets, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{stream: stream, tickets: tickets, heartbeat: heartbeat}
+}
+
+// CreateStreamTicket issues a single-use ticket for the current user, to
+// open a stream with ?ticket=... where the Authorization header cannot be
+// set. It must be redeemed within app.StreamTicketTTL.
+func (h *StreamHandler) CreateStreamTicket(c *gin.Context) {
+	ticket, err := h.tickets.Issue(middleware.CurrentUserID(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, ticket)
+}
+
+// streamMessage is a WebSocket message: a task event with its stream ID, a
+// reset or a heartbeat.
+type streamMessage struct {
+	ID    string            `json:"id,omitempty"`
+	Type  string            `json:"type"`
+	Event *domain.TaskEvent `json:"event,omitempty"`
+}
+
+// StreamTasks serves GET /tasks/stream as Server-Sent Events. Every event has
+// the stream ID as id, the event type (task.created, ...) as event and the
+// task event as JSON data. A client resumes with the Last-Event-ID header or
+// the last_event_id query parameter; when that is not possible it first gets
+// a reset event. A comment line is sent every heartbeat interval.
+func (h *StreamHandler) StreamTasks(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	subscription, replay, resumed := h.stream.Subscribe(middleware.CurrentUserID(c), lastEventID)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if !resumed {
+		_, _ = fmt.Fprintf(w, "event: %s\ndata: {}\n\n", streamReset)
+	}
+	for _, event := range replay {
+		if writeSSE(w, event) != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			err = writeSSE(w, event)
+		case <-ticker.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+		if err != nil {
+			return
+		}
+		w.Flush()
+	}
+}
+
+func writeSSE(w io.Writer, event app.StreamEvent) error {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Event.Type, data)
+	return err
+}
+
+// StreamTasksWebSocket serves GET /tasks/stream/ws as a WebSocket that sends
+// a JSON text message per task event ({"id": ..., "type": ..., "event":
+// {...}}), a reset message when the last_event_id query parameter cannot be
+// resumed, and a heartbeat message every heartbeat interval. Messages from
+// the client are ignored.
+func (h *StreamHandler) StreamTasksWebSocket(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+	lastEventID := c.Query("last_event_id")
+
+	// Any page can try to open a WebSocket, so the upgrader refuses
+	// cross-origin handshakes, answering them with 403.
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	h.serveWebSocket(conn, userID, lastEventID)
+}
+
+func (h *StreamHandler) serveWebSocket(conn *websocket.Conn, userID, lastEventID string) {
+	defer conn.Close()
+	subscription, replay, resumed := h.stream.Subscribe(userID, lastEventID)
+	defer subscription.Close()
+
+	// Reading processes the control frames and notices when the client
+	// goes away; the messages themselves are discarded.
+	conn.SetReadLimit(streamReadLimit)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(message streamMessage) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(h.heartbeat))
+		return conn.WriteJSON(message) == nil
+	}
+	if !resumed && !send(streamMessage{Type: streamReset}) {
+		return
+	}
+	for _, event := range replay {
+		if !send(streamMessage{ID: event.ID, Type: event.Event.Type, Event: &event.Event}) {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		var message streamMessage
+		select {
+		case <-closed:
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			message = streamMessage{ID: event.ID, Type: event.Event.Type, Event: &event.Event}
+		case <-ticker.C:
+			message = streamMessage{Type: streamHeartbeat}
+		}
+		if !send(message) {
+			return
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package http_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/middleware"
+	"todo-list-task/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configurationStream serves the stream endpoints until the end of the test.
+// Connections must be closed in cleanups registered later, which run first.
+func configurationStream(t *testing.T, heartbeat time.Duration) (*app.TaskStream, *httptest.Server) {
+	stream := app.NewTaskStream(memory.NewInMemoryListRepository(), 10)
+	tickets := app.NewStreamTickets(time.Minute)
+	handler := httpHandler.NewStreamHandler(stream, tickets, heartbeat)
+	users := new(mocks.UserRepository)
+	users.On("Get", mockUserID).Return(&domain.User{ID: mockUserID, Username: "cristianm", Role: domain.RoleUser}, nil)
+
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.POST("/tasks/stream/tickets", MockAuthMiddleware(), handler.CreateStreamTicket)
+	router.GET("/tasks/stream", MockAuthMiddleware(), handler.StreamTasks)
+	router.GET("/tasks/stream/ws", MockAuthMiddleware(), handler.StreamTasksWebSocket)
+	router.GET("/browser/stream", middleware.StreamAuthMiddleware(jwtManager, users, tickets), handler.StreamTasks)
+	router.GET("/tasks/:id/subtasks", func(c *gin.Context) {})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return stream, server
+}
+
+func streamEvent(ownerID, taskID string) domain.TaskEvent {
+	return domain.TaskEvent{ID: "event-" + taskID, Type: domain.TaskCreated, OwnerID: ownerID, ActorID: ownerID, TaskID: taskID}
+}
+
+// readSSE reads the next event of a Server-Sent Events stream, as its
+// fields, or the next comment line.
+func readSSE(t *testing.T, reader *bufio.Reader) map[string]string {
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return fields
+		}
+		if strings.HasPrefix(line, ":") {
+			return map[string]string{"comment": strings.TrimSpace(line[1:])}
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+func openSSE(t *testing.T, server *httptest.Server, lastEventID string) *bufio.Reader {
+	req, _ := http.NewRequest("GET", server.URL+"/tasks/stream", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewReader(resp.Body)
+}
+
+func TestStreamHandler_StreamTasks(t *testing.T) {
+	stream, server := configurationStream(t, time.Hour)
+	reader := openSSE(t, server, "")
+
+	stream.Handle(streamEvent("user-2", "other"))
+	stream.Handle(streamEvent(mockUserID, "1"))
+
+	event := readSSE(t, reader)
+	assert.Equal(t, domain.TaskCreated, event["event"])
+	assert.Contains(t, event["data"], `"task_id":"1"`)
+	require.NotEmpty(t, event["id"])
+
+	// A reconnection resumes after the last event received.
+	stream.Handle(streamEvent(mockUserID, "2"))
+	resumed := openSSE(t, server, event["id"])
+	assert.Contains(t, readSSE(t, resumed)["data"], `"task_id":"2"`)
+
+	// An unknown ID asks the client to reload its tasks.
+	reset := openSSE(t, server, "unknown-1")
+	assert.Equal(t, "reset", readSSE(t, reset)["event"])
+}
+
+func TestStreamHandler_StreamTasks_Heartbeat(t *testing.T) {
+	_, server := configurationStream(t, 10*time.Millisecond)
+
+	reader := openSSE(t, server, "")
+
+	assert.Equal(t, map[string]string{"comment": "heartbeat"}, readSSE(t, reader))
+}
+
+func dialStream(server *httptest.Server, origin string) (*websocket.Conn, *http.Response, error) {
+	return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/tasks/stream/ws", http.Header{"Origin": {origin}})
+}
+
+func TestStreamHandler_StreamTasksWebSocket(t *testing.T) {
+	stream, server := configurationStream(t, 50*time.Millisecond)
+
+	conn, _, err := dialStream(server, server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// The heartbeat also tells that the subscription is in place.
+	var message struct {
+		ID    string            `json:"id"`
+		Type  string            `json:"type"`
+		Event *domain.TaskEvent `json:"event"`
+	}
+	require.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, "heartbeat", message.Type)
+
+	stream.Handle(streamEvent("user-2", "other"))
+	stream.Handle(streamEvent(mockUserID, "1"))
+	for message.Type == "heartbeat" {
+		require.NoError(t, conn.ReadJSON(&message))
+	}
+	assert.Equal(t, domain.TaskCreated, message.Type)
+	assert.NotEmpty(t, message.ID)
+	require.NotNil(t, message.Event)
+	assert.Equal(t, "1", message.Event.TaskID)
+}
+
+func TestStreamHandler_StreamTasksWebSocket_RefusesOtherOrigins(t *testing.T) {
+	_, server := configurationStream(t, time.Hour)
+
+	_, resp, err := dialStream(server, "https://evil.example.com")
+
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestStreamHandler_StreamTicket(t *testing.T) {
+	stream, server := configurationStream(t, time.Hour)
+
+	resp, err := server.Client().Post(server.URL+"/tasks/stream/tickets", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var ticket domain.StreamTicket
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ticket))
+	require.NotEmpty(t, ticket.Ticket)
+
+	// A browser opens the stream with the ticket instead of a bearer token.
+	streamResp, err := server.Client().Get(server.URL + "/browser/stream?ticket=" + ticket.Ticket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = streamResp.Body.Close() })
+	require.Equal(t, http.StatusOK, streamResp.StatusCode)
+	stream.Handle(streamEvent(mockUserID, "1"))
+	assert.Contains(t, readSSE(t, bufio.NewReader(streamResp.Body))["data"], `"task_id":"1"`)
+
+	// The ticket can only be redeemed once.
+	again, err := server.Client().Get(server.URL + "/browser/stream?ticket=" + ticket.Ticket)
+	require.NoError(t, err)
+	defer again.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, again.StatusCode)
+}
@@ -1,10 +1,16 @@
 package http
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 	"todo-list-task/internal/app"
 	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/taskio"
+	"todo-list-task/internal/middleware"
 )
 
 type TaskHandler struct {
@@ -19,66 +25,470 @@ func (h *TaskHandler) RegisterTask(c *gin.Context) {
 	var request *domain.TaskRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
 		return
 	}
 
-	task, err := h.service.RegisterTask(request)
+	task, err := h.service.RegisterTask(middleware.CurrentUserID(c), request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, task)
 	c.JSON(http.StatusCreated, task)
 }
 
 func (h *TaskHandler) GetTaskByID(c *gin.Context) {
 	taskId := c.Param("id")
 
-	task, err := h.service.GetTask(taskId)
+	task, err := h.service.GetTask(middleware.CurrentUserID(c), taskId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, task)
 	c.JSON(http.StatusOK, task)
 }
 
+// GetSubtasks returns the task with its subtasks, nested to any depth.
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	tree, err := h.service.GetSubtree(middleware.CurrentUserID(c), c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetTags returns the tag catalog of the user, with the number of tasks
+// carrying each tag.
+func (h *TaskHandler) GetTags(c *gin.Context) {
+	tags, err := h.service.GetTags(middleware.CurrentUserID(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// GetBlockers lists the tasks that must be completed before the task.
+func (h *TaskHandler) GetBlockers(c *gin.Context) {
+	blockers, err := h.service.GetBlockers(middleware.CurrentUserID(c), c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": blockers})
+}
+
+// GetAllTask lists the user's tasks one page at a time. Supported query
+// parameters: limit, cursor, list_id, tags, tag_match, completed, title,
+// description, overdue, due_before (RFC 3339) and sort (created_at,
+// updated_at, prefixed with "-" for descending order). With list_id the page
+// holds the tasks of that list, which may be shared with the user. tags is a
+// comma-separated list, matched all together or, with tag_match=any, one of
+// them at least.
 func (h *TaskHandler) GetAllTask(c *gin.Context) {
-	tasks, err := h.service.GetTasks()
+	query, err := parseTaskQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	page, err := h.service.QueryTasks(middleware.CurrentUserID(c), query)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func parseTaskQuery(c *gin.Context) (domain.TaskQuery, error) {
+	query := domain.TaskQuery{
+		ListID:      c.Query("list_id"),
+		Title:       c.Query("title"),
+		Description: c.Query("description"),
+		Cursor:      c.Query("cursor"),
+	}
+
+	for _, tags := range c.QueryArray("tags") {
+		if tags == "" {
+			continue
+		}
+		query.Tags = append(query.Tags, strings.Split(tags, ",")...)
+	}
+	switch tagMatch := c.Query("tag_match"); tagMatch {
+	case "", "all":
+	case "any":
+		query.AnyTag = true
+	default:
+		return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid tag_match %q", tagMatch))
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid limit %q", limit))
+		}
+		query.Limit = n
+	}
+
+	if completed := c.Query("completed"); completed != "" {
+		value, err := strconv.ParseBool(completed)
+		if err != nil {
+			return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid completed %q", completed))
+		}
+		query.Completed = &value
+	}
+
+	if overdue := c.Query("overdue"); overdue != "" {
+		value, err := strconv.ParseBool(overdue)
+		if err != nil {
+			return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid overdue %q", overdue))
+		}
+		query.Overdue = value
+	}
+
+	if dueBefore := c.Query("due_before"); dueBefore != "" {
+		value, err := time.Parse(time.RFC3339, dueBefore)
+		if err != nil {
+			return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid due_before %q", dueBefore))
+		}
+		value = value.UTC()
+		query.DueBefore = &value
+	}
+
+	if sortBy := c.Query("sort"); sortBy != "" {
+		query.Descending = strings.HasPrefix(sortBy, "-")
+		query.SortBy = strings.TrimPrefix(sortBy, "-")
+		if query.SortBy != domain.SortCreatedAt && query.SortBy != domain.SortUpdatedAt {
+			return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid sort %q", sortBy))
+		}
+	}
+
+	return query, nil
 }
 
+// UpdateTask replaces a task. When the request carries an If-Match header the
+// update only applies if it matches the current ETag of the task, otherwise
+// it fails with 412 Precondition Failed.
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	var request domain.TaskRequest
 	taskId := c.Param("id")
 
+	version, ok := parseIfMatch(c.GetHeader("If-Match"))
+	if !ok {
+		_ = c.Error(domain.ErrVersionConflict)
+		return
+	}
+
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	task, err := h.service.UpdateTaskByID(middleware.CurrentUserID(c), taskId, version, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, task)
+	c.JSON(http.StatusOK, task)
+}
+
+// PatchTask partially updates a task. The body is a JSON Merge Patch
+// (application/merge-patch+json, also accepted as application/json) or a
+// JSON Patch (application/json-patch+json). If-Match works as in UpdateTask.
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	taskId := c.Param("id")
+
+	version, ok := parseIfMatch(c.GetHeader("If-Match"))
+	if !ok {
+		_ = c.Error(domain.ErrVersionConflict)
+		return
+	}
+
+	format := c.ContentType()
+	if format == gin.MIMEJSON {
+		format = domain.MergePatchFormat
+	}
+	if format != domain.MergePatchFormat && format != domain.JSONPatchFormat {
+		middleware.AbortWithProblem(c, http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported patch format %q", c.ContentType()))
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
 	}
 
-	task, err := h.service.UpdateTaskByID(taskId, request)
+	task, err := h.service.PatchTaskByID(middleware.CurrentUserID(c), taskId, version, domain.TaskPatch{Format: format, Body: body})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, task)
 	c.JSON(http.StatusOK, task)
 }
 
+// taskBatchResult is the outcome of one operation of a batch.
+type taskBatchResult struct {
+	Status     int          `json:"status"`
+	Task       *domain.Task `json:"task,omitempty"`
+	Occurrence *domain.Task `json:"occurrence,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// BatchTasks runs a list of create, update, complete and delete operations.
+// It is mounted as POST /tasks:batch; gin cannot escape ':' in a route, so
+// that pattern is really /tasks followed by a parameter holding ":batch" and
+// any other suffix is answered with 404.
+func (h *TaskHandler) BatchTasks(c *gin.Context) {
+	if c.Param("batch") != ":batch" {
+		middleware.AbortWithProblem(c, http.StatusNotFound, "")
+		return
+	}
+
+	var request domain.TaskBatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	results, err := h.service.ExecuteBatch(middleware.CurrentUserID(c), request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := make([]taskBatchResult, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			response[i] = taskBatchResult{Status: middleware.ErrorStatus(result.Err), Error: result.Err.Error()}
+			continue
+		}
+		switch request.Operations[i].Op {
+		case domain.BatchCreate:
+			response[i] = taskBatchResult{Status: http.StatusCreated, Task: result.Task}
+		case domain.BatchDelete:
+			response[i] = taskBatchResult{Status: http.StatusNoContent}
+		default:
+			response[i] = taskBatchResult{Status: http.StatusOK, Task: result.Task, Occurrence: result.Occurrence}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": response})
+}
+
+// ExportTasks streams every task of the user as an attachment. The format
+// query parameter selects jsonl (default), csv or ics.
+func (h *TaskHandler) ExportTasks(c *gin.Context) {
+	format := c.DefaultQuery("format", domain.FormatJSONLines)
+	enc, err := taskio.NewEncoder(format, c.Writer)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	begin := func() {
+		c.Header("Content-Type", taskio.ContentType(format))
+		c.Header("Content-Disposition", `attachment; filename="tasks.`+format+`"`)
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+	}
+	if err := h.service.ExportTasks(middleware.CurrentUserID(c), enc, begin); err != nil {
+		// Once the response has started the error can only be logged.
+		_ = c.Error(err)
+	}
+}
+
+// ImportTasks creates tasks from the request body and returns a report of
+// the accepted and rejected records. The format query parameter selects
+// jsonl, csv or ics and defaults to the one matching the Content-Type;
+// dry_run=true only validates the records.
+func (h *TaskHandler) ImportTasks(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = importFormat(c.ContentType())
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			_ = c.Error(domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid dry_run %q", value)))
+			return
+		}
+		dryRun = parsed
+	}
+
+	dec, err := taskio.NewDecoder(format, c.Request.Body)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	report, err := h.service.ImportTasks(middleware.CurrentUserID(c), dec, dryRun)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if !dryRun && report.Imported > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, report)
+}
+
+// importFormat guesses the import format from the request media type.
+func importFormat(contentType string) string {
+	switch contentType {
+	case "text/csv":
+		return domain.FormatCSV
+	case "text/calendar":
+		return domain.FormatICalendar
+	default:
+		return domain.FormatJSONLines
+	}
+}
+
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	taskId := c.Param("id")
 
-	err := h.service.DeleteTaskByID(taskId)
+	err := h.service.DeleteTaskByID(middleware.CurrentUserID(c), taskId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
+
+// GetTrash lists the user's deleted tasks, most recently deleted first.
+func (h *TaskHandler) GetTrash(c *gin.Context) {
+	tasks, err := h.service.GetTrash(middleware.CurrentUserID(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// RestoreTask brings a task back from the trash.
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	task, err := h.service.RestoreTask(middleware.CurrentUserID(c), c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, task)
+	c.JSON(http.StatusOK, task)
+}
+
+// GetTaskHistory returns one page of the audit entries of a task, newest
+// first.
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	page, err := h.service.GetTaskHistory(middleware.CurrentUserID(c), c.Param("id"), query)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// RestoreTaskRevision brings a task back to its state at an earlier
+// revision, recorded as a new revision.
+func (h *TaskHandler) RestoreTaskRevision(c *gin.Context) {
+	revision, err := strconv.ParseInt(c.Param("revision"), 10, 64)
+	if err != nil || revision < 1 {
+		_ = c.Error(domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid revision %q", c.Param("revision"))))
+		return
+	}
+
+	task, err := h.service.RestoreTaskRevision(middleware.CurrentUserID(c), c.Param("id"), revision)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, task)
+	c.JSON(http.StatusOK, task)
+}
+
+// parseAuditQuery reads the filters, limit and cursor of an audit query.
+// since and until are RFC 3339 times.
+func parseAuditQuery(c *gin.Context) (domain.AuditQuery, error) {
+	query := domain.AuditQuery{
+		TaskID:  c.Query("task_id"),
+		OwnerID: c.Query("owner_id"),
+		ActorID: c.Query("actor_id"),
+		Action:  c.Query("action"),
+		Cursor:  c.Query("cursor"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid limit %q", limit))
+		}
+		query.Limit = n
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value **time.Time
+	}{{"since", &query.Since}, {"until", &query.Until}} {
+		if value := c.Query(bound.name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, domain.NewError(domain.ErrValidation, fmt.Sprintf("invalid %s %q", bound.name, value))
+			}
+			parsed = parsed.UTC()
+			*bound.value = &parsed
+		}
+	}
+
+	return query, nil
+}
+
+// setETag exposes the task version as a strong entity tag.
+func setETag(c *gin.Context, task *domain.Task) {
+	c.Header("ETag", `"`+strconv.FormatInt(task.Version, 10)+`"`)
+}
+
+// parseIfMatch returns the task version required by an If-Match header, or 0
+// when the header is absent or "*". Weak or malformed tags can never match a
+// version, so they are reported as not ok.
+func parseIfMatch(header string) (int64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
@@ -7,19 +7,24 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 	"todo-list-task/internal/app"
 	"todo-list-task/internal/domain"
 	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/middleware"
 	"todo-list-task/mocks"
 )
 
 const (
-	route = "/tasks"
+	route      = "/tasks"
+	mockUserID = "user-1"
 )
 
 type valuesTestCases struct {
@@ -42,6 +47,8 @@ var taskResponse = &domain.Task{
 	Title:       "title",
 	Description: "description",
 	ID:          "12334556778",
+	OwnerID:     mockUserID,
+	Version:     1,
 }
 
 func TestTaskHandler_RegisterTask(t *testing.T) {
@@ -71,7 +78,7 @@ func TestTaskHandler_RegisterTask(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockRepo, handler, router := configuration()
 			router.POST("/tasks", handler.RegisterTask)
-			mockRepo.On("CreateTask", mock.Anything).Return(testCase.userResponse, testCase.err)
+			mockRepo.On("CreateTask", mock.MatchedBy(func(task *domain.Task) bool { return task.OwnerID == mockUserID })).Return(testCase.userResponse, testCase.err)
 			bodyBytes, _ := json.Marshal(testCase.body)
 			req, _ := mockRequestEndPoint(testCase.isErrorBody, "POST", route, bytes.NewBuffer(bodyBytes))
 
@@ -91,6 +98,69 @@ func TestTaskHandler_RegisterTask(t *testing.T) {
 	}
 }
 
+func TestTaskHandler_RegisterTask_Schedule(t *testing.T) {
+	due := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		body       string
+		expected   domain.TaskSchedule
+		statusCode int
+	}{
+		{
+			name:       "should store the due date in UTC and sort the reminders",
+			body:       `{"due_at": "2024-05-01T10:00:00+02:00", "time_zone": "Europe/Madrid", "priority": 3, "reminder_minutes": [60, 15, 60]}`,
+			expected:   domain.TaskSchedule{DueAt: &due, TimeZone: "Europe/Madrid", Priority: domain.PriorityHigh, ReminderMinutes: []int{15, 60}},
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "should return bad request when priority is out of range",
+			body:       `{"priority": 5}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when the time zone is unknown",
+			body:       `{"due_at": "2024-05-01T10:00:00Z", "time_zone": "Mars/Olympus"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when reminders have no due date",
+			body:       `{"reminder_minutes": [10]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when there are too many reminders",
+			body:       `{"due_at": "2024-05-01T10:00:00Z", "reminder_minutes": [1, 2, 3, 4, 5, 6]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when a reminder is too early",
+			body:       `{"due_at": "2024-05-01T10:00:00Z", "reminder_minutes": [20000]}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.POST("/tasks", handler.RegisterTask)
+			mockRepo.On("CreateTask", mock.MatchedBy(func(task *domain.Task) bool {
+				return assert.ObjectsAreEqual(testCase.expected, task.TaskSchedule)
+			})).Return(taskResponse, nil)
+			body := `{"title": "title", "description": "description", ` + strings.TrimPrefix(testCase.body, "{")
+			req, _ := mockRequestEndPoint(false, "POST", route, strings.NewReader(body))
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode != http.StatusCreated {
+				mockRepo.AssertNotCalled(t, "CreateTask", mock.Anything)
+			}
+		})
+	}
+}
+
 func TestTaskHandler_GetTaskByID(t *testing.T) {
 	testCases := []valuesTestCases{
 		{
@@ -106,12 +176,19 @@ func TestTaskHandler_GetTaskByID(t *testing.T) {
 			isError:    true,
 			statusCode: http.StatusInternalServerError,
 		},
+		{
+			name:       "should return not found when the task belongs to another user",
+			err:        domain.ErrTaskNotFound,
+			id:         "12334556778",
+			isError:    true,
+			statusCode: http.StatusNotFound,
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockRepo, handler, router := configuration()
 			router.GET("/tasks/:id", handler.GetTaskByID)
-			mockRepo.On("GetTask", mock.Anything).Return(testCase.userResponse, testCase.err)
+			mockRepo.On("GetTask", mockUserID, testCase.id).Return(testCase.userResponse, testCase.err)
 			req, _ := mockRequestEndPoint(testCase.isErrorBody, "GET", route+"/"+testCase.id, nil)
 
 			resp := httptest.NewRecorder()
@@ -125,26 +202,148 @@ func TestTaskHandler_GetTaskByID(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, testCase.userResponse, response)
 				assert.Equal(t, testCase.statusCode, resp.Code)
+				assert.Equal(t, `"1"`, resp.Header().Get("ETag"))
 			}
 		})
 	}
 }
 
 func TestTaskHandler_GetAllTasks(t *testing.T) {
-	testCases := []valuesTestCases{
+	completed := true
+	dueBefore := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	page := &domain.TaskPage{
+		Tasks:      []*domain.Task{taskResponse},
+		NextCursor: "next",
+	}
+
+	testCases := []struct {
+		name       string
+		url        string
+		query      domain.TaskQuery
+		page       *domain.TaskPage
+		err        error
+		statusCode int
+	}{
 		{
-			name: "Get all tasks",
-			userResponse: &domain.Task{
-				Title:       "title",
-				Description: "description",
-				ID:          "12334556778",
+			name: "Get first page with defaults",
+			url:  route,
+			query: domain.TaskQuery{
+				OwnerID: mockUserID,
+				SortBy:  domain.SortCreatedAt,
+				Limit:   domain.DefaultTaskLimit,
 			},
+			page:       page,
 			statusCode: http.StatusOK,
 		},
 		{
-			name:       "should return an error when repository return an error",
+			name: "Get page with filters, sort and cursor",
+			url:  route + "?limit=500&cursor=abc&completed=true&title=milk&description=coffee&sort=-updated_at",
+			query: domain.TaskQuery{
+				OwnerID:     mockUserID,
+				Completed:   &completed,
+				Title:       "milk",
+				Description: "coffee",
+				SortBy:      domain.SortUpdatedAt,
+				Descending:  true,
+				Limit:       domain.MaxTaskLimit,
+				Cursor:      "abc",
+			},
+			page:       page,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should return bad request when limit is invalid",
+			url:        route + "?limit=zero",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when completed is invalid",
+			url:        route + "?completed=maybe",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when sort is invalid",
+			url:        route + "?sort=title",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Get tasks due before a date",
+			url:  route + "?due_before=2024-05-01T10:00:00%2B02:00",
+			query: domain.TaskQuery{
+				OwnerID:   mockUserID,
+				DueBefore: &dueBefore,
+				SortBy:    domain.SortCreatedAt,
+				Limit:     domain.DefaultTaskLimit,
+			},
+			page:       page,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "Get tasks with every tag",
+			url:  route + "?tags=Backend,urgent&tags=urgent",
+			query: domain.TaskQuery{
+				OwnerID: mockUserID,
+				Tags:    []string{"backend", "urgent"},
+				SortBy:  domain.SortCreatedAt,
+				Limit:   domain.DefaultTaskLimit,
+			},
+			page:       page,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "Get tasks with any tag",
+			url:  route + "?tags=urgent,backend&tag_match=any",
+			query: domain.TaskQuery{
+				OwnerID: mockUserID,
+				Tags:    []string{"backend", "urgent"},
+				AnyTag:  true,
+				SortBy:  domain.SortCreatedAt,
+				Limit:   domain.DefaultTaskLimit,
+			},
+			page:       page,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should return bad request when tag_match is invalid",
+			url:        route + "?tags=urgent&tag_match=some",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when a tag is invalid",
+			url:        route + "?tags=urgent,,backend",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when due_before is invalid",
+			url:        route + "?due_before=tomorrow",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when overdue is invalid",
+			url:        route + "?overdue=often",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return bad request when cursor is invalid",
+			url:  route + "?cursor=bogus",
+			query: domain.TaskQuery{
+				OwnerID: mockUserID,
+				SortBy:  domain.SortCreatedAt,
+				Limit:   domain.DefaultTaskLimit,
+				Cursor:  "bogus",
+			},
+			err:        domain.ErrInvalidQuery,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return an error when repository return an error",
+			url:  route,
+			query: domain.TaskQuery{
+				OwnerID: mockUserID,
+				SortBy:  domain.SortCreatedAt,
+				Limit:   domain.DefaultTaskLimit,
+			},
 			err:        errors.New("some error"),
-			isError:    true,
 			statusCode: http.StatusInternalServerError,
 		},
 	}
@@ -152,25 +351,38 @@ func TestTaskHandler_GetAllTasks(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockRepo, handler, router := configuration()
 			router.GET("/tasks", handler.GetAllTask)
-			mockRepo.On("GetTasks").Return([]*domain.Task{testCase.userResponse}, testCase.err)
-			req, _ := mockRequestEndPoint(testCase.isErrorBody, "GET", route, nil)
+			mockRepo.On("QueryTasks", testCase.query).Return(testCase.page, testCase.err)
+			req, _ := mockRequestEndPoint(false, "GET", testCase.url, nil)
 
 			resp := httptest.NewRecorder()
 			router.ServeHTTP(resp, req)
 
-			if testCase.isError {
-				assert.Equal(t, testCase.statusCode, resp.Code)
-			} else {
-				var response []*domain.Task
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode == http.StatusOK {
+				var response *domain.TaskPage
 				err := json.Unmarshal(resp.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, []*domain.Task{testCase.userResponse}, response)
-				assert.Equal(t, testCase.statusCode, resp.Code)
+				assert.Equal(t, testCase.page, response)
 			}
 		})
 	}
 }
 
+func TestTaskHandler_GetAllTasks_Overdue(t *testing.T) {
+	mockRepo, handler, router := configuration()
+	router.GET("/tasks", handler.GetAllTask)
+	mockRepo.On("QueryTasks", mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.Overdue && !query.Now.IsZero() && time.Since(query.Now) < time.Minute
+	})).Return(&domain.TaskPage{Tasks: []*domain.Task{}}, nil)
+	req, _ := mockRequestEndPoint(false, "GET", route+"?overdue=true", nil)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestTaskHandler_UpdateTask(t *testing.T) {
 	testCases := []valuesTestCases{
 		{
@@ -195,12 +407,21 @@ func TestTaskHandler_UpdateTask(t *testing.T) {
 			isError:    true,
 			statusCode: http.StatusInternalServerError,
 		},
+		{
+			name:       "should return not found when the task belongs to another user",
+			id:         "12334556778",
+			err:        domain.ErrTaskNotFound,
+			body:       taskRequest,
+			isError:    true,
+			statusCode: http.StatusNotFound,
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockRepo, handler, router := configuration()
 			router.PUT("/tasks/:id", handler.UpdateTask)
-			mockRepo.On("UpdateTask", mock.Anything, mock.Anything).Return(testCase.userResponse, testCase.err)
+			mockRepo.On("GetTask", mockUserID, testCase.id).Return(taskResponse, nil)
+			mockRepo.On("UpdateTask", mockUserID, testCase.id, mock.Anything).Return(testCase.userResponse, testCase.err)
 			bodyBytes, _ := json.Marshal(testCase.body)
 			req, _ := mockRequestEndPoint(testCase.isErrorBody, "PUT", route+"/"+testCase.id, bytes.NewBuffer(bodyBytes))
 
@@ -220,64 +441,1434 @@ func TestTaskHandler_UpdateTask(t *testing.T) {
 	}
 }
 
-func TestTaskHandler_DeleteTask(t *testing.T) {
-	testCases := []valuesTestCases{
+func TestTaskHandler_UpdateTask_IfMatch(t *testing.T) {
+	updated := &domain.Task{ID: "12334556778", OwnerID: mockUserID, Title: "title", Version: 4}
+
+	testCases := []struct {
+		name       string
+		ifMatch    string
+		version    int64
+		callsRepo  bool
+		err        error
+		statusCode int
+		etag       string
+	}{
 		{
-			name:       "Delete task",
-			id:         "12334556778",
+			name:       "should update when If-Match is the current version",
+			ifMatch:    `"3"`,
+			version:    3,
+			callsRepo:  true,
 			statusCode: http.StatusOK,
+			etag:       `"4"`,
 		},
 		{
-			name:       "should return an error when repository return an error",
-			id:         "12334556778",
-			err:        errors.New("some error"),
-			isError:    true,
-			statusCode: http.StatusInternalServerError,
+			name:       "should update unconditionally when If-Match is a wildcard",
+			ifMatch:    "*",
+			callsRepo:  true,
+			statusCode: http.StatusOK,
+			etag:       `"4"`,
+		},
+		{
+			name:       "should return precondition failed when the version is stale",
+			ifMatch:    `"2"`,
+			version:    2,
+			callsRepo:  true,
+			err:        domain.ErrVersionConflict,
+			statusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "should return precondition failed when If-Match is a weak tag",
+			ifMatch:    `W/"3"`,
+			statusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "should return precondition failed when If-Match is malformed",
+			ifMatch:    "3",
+			statusCode: http.StatusPreconditionFailed,
 		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockRepo, handler, router := configuration()
-			router.DELETE("/tasks/:id", handler.DeleteTask)
-			mockRepo.On("DeleteTask", mock.Anything).Return(testCase.err)
-			req, _ := mockRequestEndPoint(testCase.isErrorBody, "DELETE", route+"/"+testCase.id, nil)
+			router.PUT("/tasks/:id", handler.UpdateTask)
+			if testCase.callsRepo {
+				mockRepo.On("GetTask", mockUserID, "12334556778").Return(updated, nil)
+				mockRepo.On("UpdateTask", mockUserID, "12334556778", mock.MatchedBy(func(task *domain.Task) bool {
+					return task.Version == testCase.version
+				})).Return(updated, testCase.err)
+			}
+			bodyBytes, _ := json.Marshal(taskRequest)
+			req, _ := mockRequestEndPoint(false, "PUT", route+"/12334556778", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("If-Match", testCase.ifMatch)
 
 			resp := httptest.NewRecorder()
 			router.ServeHTTP(resp, req)
 
-			if testCase.isError {
-				assert.Equal(t, testCase.statusCode, resp.Code)
-			} else {
-				assert.Equal(t, testCase.statusCode, resp.Code)
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			assert.Equal(t, testCase.etag, resp.Header().Get("ETag"))
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskHandler_PatchTask(t *testing.T) {
+	stored := &domain.Task{ID: "12334556778", OwnerID: mockUserID, Title: "title", Description: "description", Version: 1}
+	due := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		ifMatch     string
+		getErr      error
+		expected    *domain.Task
+		statusCode  int
+	}{
+		{
+			name:        "should complete a task with a merge patch",
+			contentType: domain.MergePatchFormat,
+			body:        `{"completed": true}`,
+			expected:    &domain.Task{Title: "title", Description: "description", Completed: true},
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:        "should treat a plain JSON body as a merge patch",
+			contentType: "application/json",
+			body:        `{"title": "renamed"}`,
+			ifMatch:     `"1"`,
+			expected:    &domain.Task{Title: "renamed", Description: "description"},
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:        "should rename a task with a JSON patch",
+			contentType: domain.JSONPatchFormat,
+			body:        `[{"op": "test", "path": "/version", "value": 1}, {"op": "replace", "path": "/title", "value": "renamed"}]`,
+			expected:    &domain.Task{Title: "renamed", Description: "description"},
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:        "should copy fields with a JSON patch",
+			contentType: domain.JSONPatchFormat,
+			body:        `[{"op": "copy", "from": "/title", "path": "/description"}]`,
+			expected:    &domain.Task{Title: "title", Description: "title"},
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:        "should set a due date and reminders with a merge patch",
+			contentType: domain.MergePatchFormat,
+			body:        `{"due_at": "2024-05-01T10:00:00+02:00", "reminder_minutes": [30, 5]}`,
+			expected: &domain.Task{Title: "title", Description: "description", TaskSchedule: domain.TaskSchedule{
+				DueAt:           &due,
+				ReminderMinutes: []int{5, 30},
+			}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "should return bad request when a patch adds reminders without a due date",
+			contentType: domain.JSONPatchFormat,
+			body:        `[{"op": "add", "path": "/reminder_minutes", "value": [10]}]`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "should return bad request when a merge patch removes a required field",
+			contentType: domain.MergePatchFormat,
+			body:        `{"title": null}`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "should return bad request when a patch changes a read-only field",
+			contentType: domain.MergePatchFormat,
+			body:        `{"version": 9}`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "should return bad request when a patch adds an unknown field",
+			contentType: domain.JSONPatchFormat,
+			body:        `[{"op": "add", "path": "/color", "value": "red"}]`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "should return bad request when a field has the wrong type",
+			contentType: domain.MergePatchFormat,
+			body:        `{"completed": "yes"}`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "should return bad request when the patch is malformed",
+			contentType: domain.JSONPatchFormat,
+			body:        `{"op": "replace"}`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "should return conflict when a test operation fails",
+			contentType: domain.JSONPatchFormat,
+			body:        `[{"op": "test", "path": "/completed", "value": true}]`,
+			statusCode:  http.StatusConflict,
+		},
+		{
+			name:        "should return unsupported media type for other formats",
+			contentType: "text/plain",
+			body:        `title=renamed`,
+			statusCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "should return precondition failed when If-Match is stale",
+			contentType: domain.MergePatchFormat,
+			body:        `{"completed": true}`,
+			ifMatch:     `"2"`,
+			statusCode:  http.StatusPreconditionFailed,
+		},
+		{
+			name:        "should return not found when the task belongs to another user",
+			contentType: domain.MergePatchFormat,
+			body:        `{"completed": true}`,
+			getErr:      domain.ErrTaskNotFound,
+			statusCode:  http.StatusNotFound,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.PATCH("/tasks/:id", handler.PatchTask)
+			mockRepo.On("GetTask", mockUserID, stored.ID).Return(stored, testCase.getErr)
+			if testCase.expected != nil {
+				mockRepo.On("UpdateTask", mockUserID, stored.ID, mock.MatchedBy(func(task *domain.Task) bool {
+					return task.Title == testCase.expected.Title &&
+						task.Description == testCase.expected.Description &&
+						task.Completed == testCase.expected.Completed &&
+						assert.ObjectsAreEqual(testCase.expected.TaskSchedule, task.TaskSchedule) &&
+						task.Version == stored.Version
+				})).Return(&domain.Task{ID: stored.ID, Version: 2}, nil)
+			}
+			req, _ := http.NewRequest("PATCH", route+"/"+stored.ID, strings.NewReader(testCase.body))
+			req.Header.Set("Content-Type", testCase.contentType)
+			req.Header.Set("If-Match", testCase.ifMatch)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode == http.StatusOK {
+				assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+			}
+			if testCase.expected == nil {
+				mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
 			}
 		})
 	}
 }
 
-func mockRequestEndPoint(isError bool, method string, api string, body io.Reader) (*http.Request, error) {
-	if isError {
-		return http.NewRequest(method, api, strings.NewReader("Invalid Body"))
+func TestTaskHandler_PatchTaskTags(t *testing.T) {
+	stored := &domain.Task{ID: "12334556778", OwnerID: mockUserID, Title: "title", Description: "description", Tags: []string{"home", "work"}, Version: 1}
+
+	testCases := []struct {
+		name       string
+		body       string
+		expected   []string
+		statusCode int
+	}{
+		{
+			name:       "should append a tag with an add to the end of the array",
+			body:       `[{"op": "add", "path": "/tags/-", "value": "zoo"}]`,
+			expected:   []string{"home", "work", "zoo"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should insert a tag with an add at an index",
+			body:       `[{"op": "add", "path": "/tags/0", "value": "garden"}]`,
+			expected:   []string{"garden", "home", "work"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should remove a tag by index",
+			body:       `[{"op": "remove", "path": "/tags/0"}]`,
+			expected:   []string{"work"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should replace a tag by index",
+			body:       `[{"op": "test", "path": "/tags/1", "value": "work"}, {"op": "replace", "path": "/tags/1", "value": "office"}]`,
+			expected:   []string{"home", "office"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should move a tag out of the array",
+			body:       `[{"op": "move", "from": "/tags/1", "path": "/title"}]`,
+			expected:   []string{"home"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should return bad request when replacing past the end of the array",
+			body:       `[{"op": "replace", "path": "/tags/2", "value": "office"}]`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when removing the end of the array",
+			body:       `[{"op": "remove", "path": "/tags/-"}]`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request for an index with leading zeros",
+			body:       `[{"op": "remove", "path": "/tags/01"}]`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when moving the array into itself",
+			body:       `[{"op": "move", "from": "/tags", "path": "/tags/0"}]`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return conflict when testing a missing element",
+			body:       `[{"op": "test", "path": "/tags/5", "value": "home"}]`,
+			statusCode: http.StatusConflict,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.PATCH("/tasks/:id", handler.PatchTask)
+			mockRepo.On("GetTask", mockUserID, stored.ID).Return(stored, nil)
+			if testCase.expected != nil {
+				mockRepo.On("UpdateTask", mockUserID, stored.ID, mock.MatchedBy(func(task *domain.Task) bool {
+					return assert.ObjectsAreEqual(testCase.expected, task.Tags)
+				})).Return(&domain.Task{ID: stored.ID, Version: 2}, nil)
+			}
+			req, _ := http.NewRequest("PATCH", route+"/"+stored.ID, strings.NewReader(testCase.body))
+			req.Header.Set("Content-Type", domain.JSONPatchFormat)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.expected == nil {
+				mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+			}
+			assert.Equal(t, []string{"home", "work"}, stored.Tags)
+		})
 	}
+}
 
-	req, _ := http.NewRequest(method, api, body)
-	req.Header.Set("Content-Type", "application/json")
+func TestTaskHandler_PatchTask_RetriesUnconditionalPatch(t *testing.T) {
+	mockRepo, handler, router := configuration()
+	router.PATCH("/tasks/:id", handler.PatchTask)
+	mockRepo.On("GetTask", mockUserID, "1").Return(&domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", Description: "description", Version: 1}, nil).Once()
+	mockRepo.On("GetTask", mockUserID, "1").Return(&domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", Description: "description", Version: 2}, nil).Once()
+	mockRepo.On("UpdateTask", mockUserID, "1", mock.MatchedBy(func(task *domain.Task) bool { return task.Version == 1 })).Return(nil, domain.ErrVersionConflict).Once()
+	mockRepo.On("UpdateTask", mockUserID, "1", mock.MatchedBy(func(task *domain.Task) bool { return task.Version == 2 })).Return(&domain.Task{ID: "1", Version: 3}, nil).Once()
 
-	return req, nil
+	req, _ := http.NewRequest("PATCH", route+"/1", strings.NewReader(`{"completed": true}`))
+	req.Header.Set("Content-Type", domain.MergePatchFormat)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"3"`, resp.Header().Get("ETag"))
+	mockRepo.AssertExpectations(t)
 }
 
-func configuration() (*mocks.TaskRepository, *httpHandler.TaskHandler, *gin.Engine) {
-	mockRepo := new(mocks.TaskRepository)
-	taskService := app.NewTaskService(mockRepo)
-	handler := httpHandler.NewTaskHandler(taskService)
+func TestTaskHandler_CompleteRecurringTask(t *testing.T) {
+	// 2026-03-27 09:00 in Madrid, two days before summer time starts.
+	due := time.Date(2026, 3, 27, 8, 0, 0, 0, time.UTC)
+	schedule := domain.TaskSchedule{DueAt: &due, TimeZone: "Europe/Madrid", ReminderMinutes: []int{30}, Recurrence: "FREQ=WEEKLY;COUNT=3"}
+	last := schedule
+	last.Recurrence = "FREQ=WEEKLY;COUNT=1"
 
-	router := gin.Default()
+	testCases := []struct {
+		name       string
+		method     string
+		body       string
+		stored     domain.Task
+		spawn      bool
+		statusCode int
+	}{
+		{
+			name:       "should create the next occurrence when a PUT completes the task",
+			method:     "PUT",
+			body:       `{"title": "Water plants", "description": "Balcony", "completed": true, "due_at": "2026-03-27T09:00:00+01:00", "time_zone": "Europe/Madrid", "reminder_minutes": [30], "recurrence": "FREQ=WEEKLY;COUNT=3"}`,
+			stored:     domain.Task{TaskSchedule: schedule},
+			spawn:      true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should create the next occurrence when a PATCH completes the task",
+			method:     "PATCH",
+			body:       `{"completed": true}`,
+			stored:     domain.Task{TaskSchedule: schedule},
+			spawn:      true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should not create another occurrence when the task was already completed",
+			method:     "PATCH",
+			body:       `{"completed": true}`,
+			stored:     domain.Task{Completed: true, TaskSchedule: schedule},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should not create another occurrence after the last one",
+			method:     "PATCH",
+			body:       `{"completed": true}`,
+			stored:     domain.Task{TaskSchedule: last},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should return bad request when the recurrence is invalid",
+			method:     "PUT",
+			body:       `{"title": "Water plants", "description": "Balcony", "completed": true, "due_at": "2026-03-27T09:00:00+01:00", "recurrence": "FREQ=HOURLY"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
 
-	router.Use(MockAuthMiddleware())
-	return mockRepo, handler, router
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.PUT("/tasks/:id", handler.UpdateTask)
+			router.PATCH("/tasks/:id", handler.PatchTask)
+			stored := testCase.stored
+			stored.ID, stored.OwnerID, stored.Title, stored.Description, stored.Version = "1", mockUserID, "Water plants", "Balcony", 4
+			mockRepo.On("GetTask", mockUserID, "1").Return(&stored, nil)
+			mockRepo.On("UpdateTask", mockUserID, "1", mock.Anything).Return(&domain.Task{ID: "1", Version: 5}, nil)
+			mockRepo.On("ApplyTaskChanges", mockUserID, mock.MatchedBy(func(changes []domain.TaskChange) bool {
+				if len(changes) != 1 || changes[0].Op != domain.BatchUpdate || changes[0].Version != 4 || !changes[0].Task.Completed {
+					return false
+				}
+				next, err := changes[0].Apply(mockUserID, &stored)
+				if err != nil {
+					return false
+				}
+				create, ok := changes[0].NextOccurrence(&stored, next)
+				nextDue := time.Date(2026, 4, 3, 7, 0, 0, 0, time.UTC)
+				return ok && create.Op == domain.BatchCreate && !create.Task.Completed &&
+					create.Task.Title == "Water plants" && create.Task.DueAt.Equal(nextDue) &&
+					create.Task.Recurrence == "FREQ=WEEKLY;COUNT=2" &&
+					assert.ObjectsAreEqual([]int{30}, create.Task.ReminderMinutes)
+			}), true).Return([]domain.TaskChangeResult{{Task: &domain.Task{ID: "1", Version: 5}, Occurrence: &domain.Task{ID: "2", Version: 1}}}, nil)
+			req, _ := http.NewRequest(testCase.method, route+"/1", strings.NewReader(testCase.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, `"5"`, resp.Header().Get("ETag"))
+			if testCase.spawn {
+				mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				mockRepo.AssertNotCalled(t, "ApplyTaskChanges", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
 }
 
-func MockAuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Next()
+// linkedTasks returns a task graph: 3 is a subtask of 2, a subtask of 1; 5
+// is blocked by the open task 4; 7 is blocked by the completed task 6 and by
+// a deleted task.
+func linkedTasks() []*domain.Task {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := []*domain.Task{
+		{ID: "1"},
+		{ID: "2", TaskLinks: domain.TaskLinks{ParentID: "1"}},
+		{ID: "3", TaskLinks: domain.TaskLinks{ParentID: "2"}},
+		{ID: "4"},
+		{ID: "5", TaskLinks: domain.TaskLinks{BlockedBy: []string{"4"}}},
+		{ID: "6", Completed: true},
+		{ID: "7", TaskLinks: domain.TaskLinks{ParentID: "1", BlockedBy: []string{"6", "deleted"}}},
 	}
+	for i, task := range tasks {
+		task.OwnerID, task.Title, task.Description, task.Version = mockUserID, "title "+task.ID, "description", 1
+		task.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+	}
+	return tasks
+}
+
+// onLinkedTasks makes the repository serve linkedTasks.
+func onLinkedTasks(mockRepo *mocks.TaskRepository) {
+	mockRepo.On("GetTasks", mockUserID).Return(linkedTasks(), nil)
+	mockRepo.On("GetTask", mockUserID, mock.Anything).Return(func(ownerID, id string) (*domain.Task, error) {
+		for _, task := range linkedTasks() {
+			if task.ID == id {
+				return task, nil
+			}
+		}
+		return nil, domain.ErrTaskNotFound
+	})
+}
+
+func TestTaskHandler_TaskLinks(t *testing.T) {
+	testCases := []struct {
+		name       string
+		method     string
+		id         string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "should create a subtask blocked by another task",
+			method:     "POST",
+			body:       `{"title": "title", "description": "description", "parent_id": "1", "blocked_by": ["4", "4"]}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "should return bad request when the parent does not exist",
+			method:     "POST",
+			body:       `{"title": "title", "description": "description", "parent_id": "missing"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when a blocker does not exist",
+			method:     "PATCH",
+			id:         "1",
+			body:       `{"blocked_by": ["missing"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when a task blocks itself",
+			method:     "PATCH",
+			id:         "4",
+			body:       `{"blocked_by": ["4"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return conflict when the dependencies form a cycle",
+			method:     "PATCH",
+			id:         "4",
+			body:       `{"blocked_by": ["5"]}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "should return conflict when a task becomes its own ancestor",
+			method:     "PATCH",
+			id:         "1",
+			body:       `{"parent_id": "3"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "should return conflict when a PATCH completes a blocked task",
+			method:     "PATCH",
+			id:         "5",
+			body:       `{"completed": true}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "should return conflict when a PUT completes a blocked task",
+			method:     "PUT",
+			id:         "5",
+			body:       `{"title": "title", "description": "description", "completed": true, "blocked_by": ["4"]}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "should complete a task whose blockers are completed or deleted",
+			method:     "PATCH",
+			id:         "7",
+			body:       `{"completed": true}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should complete a task once its blockers are removed",
+			method:     "PATCH",
+			id:         "5",
+			body:       `{"completed": true, "blocked_by": null}`,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.POST("/tasks", handler.RegisterTask)
+			router.PUT("/tasks/:id", handler.UpdateTask)
+			router.PATCH("/tasks/:id", handler.PatchTask)
+			tasks := linkedTasks()
+			mockRepo.On("GetTasks", mockUserID).Return(tasks, nil)
+			for _, task := range tasks {
+				mockRepo.On("GetTask", mockUserID, task.ID).Return(task, nil)
+			}
+			mockRepo.On("CreateTask", mock.MatchedBy(func(task *domain.Task) bool {
+				return task.ParentID == "1" && assert.ObjectsAreEqual([]string{"4"}, task.BlockedBy)
+			})).Return(taskResponse, nil)
+			mockRepo.On("UpdateTask", mockUserID, testCase.id, mock.Anything).Return(taskResponse, nil)
+			url := route
+			if testCase.id != "" {
+				url += "/" + testCase.id
+			}
+			req, _ := mockRequestEndPoint(false, testCase.method, url, strings.NewReader(testCase.body))
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if resp.Code >= http.StatusBadRequest {
+				mockRepo.AssertNotCalled(t, "CreateTask", mock.Anything)
+				mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_GetSubtasks(t *testing.T) {
+	type tree struct {
+		Task struct {
+			ID string `json:"id"`
+		} `json:"task"`
+		Subtasks []tree `json:"subtasks"`
+	}
+	var flatten func(node tree, depth int) []string
+	flatten = func(node tree, depth int) []string {
+		ids := []string{strings.Repeat("-", depth) + node.Task.ID}
+		for _, subtask := range node.Subtasks {
+			ids = append(ids, flatten(subtask, depth+1)...)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name       string
+		id         string
+		expected   []string
+		statusCode int
+	}{
+		{name: "should nest subtasks oldest first", id: "1", expected: []string{"1", "-2", "--3", "-7"}, statusCode: http.StatusOK},
+		{name: "should return a leaf task alone", id: "3", expected: []string{"3"}, statusCode: http.StatusOK},
+		{name: "should return not found when the task does not exist", id: "missing", statusCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.GET("/tasks/:id/subtasks", handler.GetSubtasks)
+			onLinkedTasks(mockRepo)
+			req, _ := http.NewRequest("GET", route+"/"+testCase.id+"/subtasks", nil)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.expected != nil {
+				var response tree
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+				assert.Equal(t, testCase.expected, flatten(response, 0))
+			}
+		})
+	}
+}
+
+func TestTaskHandler_GetBlockers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		id         string
+		expected   []string
+		statusCode int
+	}{
+		{name: "should list the open blockers", id: "5", expected: []string{"4"}, statusCode: http.StatusOK},
+		{name: "should skip deleted blockers", id: "7", expected: []string{"6"}, statusCode: http.StatusOK},
+		{name: "should return an empty list when nothing blocks the task", id: "1", expected: []string{}, statusCode: http.StatusOK},
+		{name: "should return not found when the task does not exist", id: "missing", statusCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.GET("/tasks/:id/blockers", handler.GetBlockers)
+			onLinkedTasks(mockRepo)
+			req, _ := http.NewRequest("GET", route+"/"+testCase.id+"/blockers", nil)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.expected != nil {
+				var response struct {
+					Tasks []*domain.Task `json:"tasks"`
+				}
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+				ids := []string{}
+				for _, task := range response.Tasks {
+					ids = append(ids, task.ID)
+				}
+				assert.Equal(t, testCase.expected, ids)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_BatchTasks_Links(t *testing.T) {
+	testCases := []struct {
+		name       string
+		operations []domain.TaskBatchOperation
+		statuses   []int
+	}{
+		{
+			name: "should complete a task after its blocker in the same batch",
+			operations: []domain.TaskBatchOperation{
+				{Op: domain.BatchComplete, ID: "4"},
+				{Op: domain.BatchComplete, ID: "5"},
+			},
+			statuses: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "should refuse to complete a task before its blocker",
+			operations: []domain.TaskBatchOperation{
+				{Op: domain.BatchComplete, ID: "5"},
+				{Op: domain.BatchComplete, ID: "4"},
+			},
+			statuses: []int{http.StatusConflict, http.StatusOK},
+		},
+		{
+			name: "should detect a cycle made by two updates",
+			operations: []domain.TaskBatchOperation{
+				{Op: domain.BatchUpdate, ID: "1", Task: &domain.TaskRequest{Title: "title", Description: "description", TaskLinks: domain.TaskLinks{BlockedBy: []string{"4"}}}},
+				{Op: domain.BatchUpdate, ID: "4", Task: &domain.TaskRequest{Title: "title", Description: "description", TaskLinks: domain.TaskLinks{BlockedBy: []string{"1"}}}},
+			},
+			statuses: []int{http.StatusOK, http.StatusConflict},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.POST("/tasks:batch", handler.BatchTasks)
+			mockRepo.On("GetTasks", mockUserID).Return(linkedTasks(), nil)
+			mockRepo.On("ApplyTaskChanges", mockUserID, mock.Anything, false).Return(func(_ string, changes []domain.TaskChange, _ bool) []domain.TaskChangeResult {
+				results := make([]domain.TaskChangeResult, len(changes))
+				for i := range results {
+					results[i].Task = taskResponse
+				}
+				return results
+			}, nil)
+
+			resp := postJSON(router, route+":batch", domain.TaskBatchRequest{Operations: testCase.operations})
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var response struct {
+				Results []struct {
+					Status int `json:"status"`
+				} `json:"results"`
+			}
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+			var statuses []int
+			for _, result := range response.Results {
+				statuses = append(statuses, result.Status)
+			}
+			assert.Equal(t, testCase.statuses, statuses)
+		})
+	}
+}
+
+func TestTaskHandler_BatchTasks_CompletesRecurringTask(t *testing.T) {
+	due := time.Date(2026, 3, 27, 8, 0, 0, 0, time.UTC)
+	stored := &domain.Task{ID: "1", OwnerID: mockUserID, Title: "Water plants", Description: "Balcony", Version: 4, TaskSchedule: domain.TaskSchedule{DueAt: &due, Recurrence: "FREQ=WEEKLY;COUNT=3"}}
+
+	mockRepo, handler, router := configuration()
+	router.POST("/tasks:batch", handler.BatchTasks)
+	mockRepo.On("GetTasks", mockUserID).Return([]*domain.Task{stored}, nil)
+	mockRepo.On("ApplyTaskChanges", mockUserID, mock.Anything, true).Return(func(_ string, changes []domain.TaskChange, _ bool) []domain.TaskChangeResult {
+		next, err := changes[0].Apply(mockUserID, stored)
+		require.NoError(t, err)
+		create, ok := changes[0].NextOccurrence(stored, next)
+		require.True(t, ok)
+		return []domain.TaskChangeResult{{Task: next, Previous: stored, Occurrence: create.Task}}
+	}, nil)
+
+	resp := postJSON(router, route+":batch", domain.TaskBatchRequest{Atomic: true, Operations: []domain.TaskBatchOperation{{Op: domain.BatchComplete, ID: "1", Version: 4}}})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var response struct {
+		Results []struct {
+			Status     int          `json:"status"`
+			Task       *domain.Task `json:"task"`
+			Occurrence *domain.Task `json:"occurrence"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	require.Len(t, response.Results, 1)
+	assert.True(t, response.Results[0].Task.Completed)
+	require.NotNil(t, response.Results[0].Occurrence)
+	assert.False(t, response.Results[0].Occurrence.Completed)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", response.Results[0].Occurrence.Recurrence)
+	assert.True(t, response.Results[0].Occurrence.DueAt.Equal(due.AddDate(0, 0, 7)))
+}
+
+func TestTaskHandler_BatchTasks(t *testing.T) {
+	created := &domain.Task{ID: "new", OwnerID: mockUserID, Title: "title", Description: "description", Version: 1}
+	completed := &domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", Description: "description", Completed: true, Version: 2}
+	operations := []domain.TaskBatchOperation{
+		{Op: domain.BatchCreate, Task: taskRequest},
+		{Op: domain.BatchUpdate, Task: taskRequest},
+		{Op: domain.BatchComplete, ID: "1"},
+		{Op: domain.BatchUpdate, ID: "missing", Task: taskRequest},
+		{Op: domain.BatchDelete, ID: "2", Version: 3},
+	}
+
+	testCases := []struct {
+		name       string
+		url        string
+		body       interface{}
+		results    []domain.TaskChangeResult
+		err        error
+		statuses   []int
+		statusCode int
+	}{
+		{
+			name: "should report a status per operation in best-effort mode",
+			url:  route + ":batch",
+			body: domain.TaskBatchRequest{Operations: operations},
+			results: []domain.TaskChangeResult{
+				{Task: created},
+				{Task: completed},
+				{Err: domain.ErrTaskNotFound},
+				{},
+			},
+			statuses:   []int{http.StatusCreated, http.StatusBadRequest, http.StatusOK, http.StatusNotFound, http.StatusNoContent},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should fail as a whole when an atomic batch fails",
+			url:        route + ":batch",
+			body:       domain.TaskBatchRequest{Atomic: true, Operations: append([]domain.TaskBatchOperation{}, operations[2:]...)},
+			err:        &domain.BatchError{Index: 2, Err: domain.ErrVersionConflict},
+			statusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "should reject an invalid operation of an atomic batch before touching the repository",
+			url:        route + ":batch",
+			body:       domain.TaskBatchRequest{Atomic: true, Operations: operations},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when the batch is empty",
+			url:        route + ":batch",
+			body:       domain.TaskBatchRequest{},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when an operation is unknown",
+			url:        route + ":batch",
+			body:       domain.TaskBatchRequest{Operations: []domain.TaskBatchOperation{{Op: "archive", ID: "1"}}},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return not found for other custom methods",
+			url:        route + ":purge",
+			body:       domain.TaskBatchRequest{Operations: operations},
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.POST("/tasks:batch", handler.BatchTasks)
+			mockRepo.On("GetTasks", mockUserID).Return([]*domain.Task{}, nil).Maybe()
+			if testCase.results != nil || testCase.err != nil {
+				mockRepo.On("ApplyTaskChanges", mockUserID, mock.Anything, mock.Anything).Return(testCase.results, testCase.err)
+			}
+
+			resp := postJSON(router, testCase.url, testCase.body)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statuses != nil {
+				var response struct {
+					Results []struct {
+						Status int          `json:"status"`
+						Task   *domain.Task `json:"task"`
+					} `json:"results"`
+				}
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+				var statuses []int
+				for _, result := range response.Results {
+					statuses = append(statuses, result.Status)
+				}
+				assert.Equal(t, testCase.statuses, statuses)
+				assert.Equal(t, created, response.Results[0].Task)
+			}
+			if testCase.results == nil && testCase.err == nil {
+				mockRepo.AssertNotCalled(t, "ApplyTaskChanges", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_BatchTasks_BuildsChanges(t *testing.T) {
+	mockRepo, handler, router := configuration()
+	router.POST("/tasks:batch", handler.BatchTasks)
+	mockRepo.On("ApplyTaskChanges", mockUserID, mock.MatchedBy(func(changes []domain.TaskChange) bool {
+		return len(changes) == 2 &&
+			changes[0].Op == domain.BatchCreate && changes[0].Task.OwnerID == mockUserID && changes[0].Task.Version == 1 &&
+			changes[0].ID == changes[0].Task.ID && changes[0].ID != "" &&
+			changes[1].Op == domain.BatchDelete && changes[1].ID == "2" && changes[1].Version == 3
+	}), true).Return([]domain.TaskChangeResult{{Task: taskResponse}, {}}, nil)
+
+	resp := postJSON(router, route+":batch", domain.TaskBatchRequest{
+		Atomic: true,
+		Operations: []domain.TaskBatchOperation{
+			{Op: domain.BatchCreate, Task: taskRequest},
+			{Op: domain.BatchDelete, ID: "2", Version: 3},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskHandler_ExportTasks(t *testing.T) {
+	first := &domain.Task{ID: "1", OwnerID: mockUserID, Title: "Milk", Description: "Buy milk"}
+	second := &domain.Task{ID: "2", OwnerID: mockUserID, Title: "Eggs", Description: "Buy eggs", Completed: true}
+
+	mockRepo, handler, router := configuration()
+	router.GET("/tasks/export", handler.ExportTasks)
+	mockRepo.On("ScanTasks", mockUserID, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*domain.Task) error)
+		_ = fn(first)
+		_ = fn(second)
+	}).Return(nil)
+
+	t.Run("Should stream every task as CSV", func(t *testing.T) {
+		req, _ := mockRequestEndPoint(false, "GET", route+"/export?format=csv", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="tasks.csv"`, resp.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,title,description,completed,created_at,updated_at,due_at,time_zone,priority,reminder_minutes,recurrence,tags\n1,Milk,Buy milk,false,,,,,0,,,\n2,Eggs,Buy eggs,true,,,,,0,,,\n", resp.Body.String())
+	})
+
+	t.Run("Should default to JSON Lines", func(t *testing.T) {
+		req, _ := mockRequestEndPoint(false, "GET", route+"/export", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Len(t, strings.Split(strings.TrimSpace(resp.Body.String()), "\n"), 2)
+	})
+
+	t.Run("Should return bad request for an unknown format", func(t *testing.T) {
+		req, _ := mockRequestEndPoint(false, "GET", route+"/export?format=xml", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestTaskHandler_ExportTasks_ReportsReadError(t *testing.T) {
+	mockRepo, handler, router := configuration()
+	router.GET("/tasks/export", handler.ExportTasks)
+	mockRepo.On("ScanTasks", mockUserID, mock.Anything).Return(errors.New("database unavailable"))
+
+	req, _ := mockRequestEndPoint(false, "GET", route+"/export?format=csv", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+	assert.Empty(t, resp.Header().Get("Content-Disposition"))
+}
+
+func TestTaskHandler_ImportTasks(t *testing.T) {
+	body := "title,description,completed\nMilk,Buy milk,false\n,No title,false\nEggs,Buy eggs,maybe\nBread,Buy bread,true\n"
+
+	testCases := []struct {
+		name       string
+		url        string
+		report     domain.ImportReport
+		statusCode int
+	}{
+		{
+			name: "should only validate in a dry run",
+			url:  route + "/import?dry_run=true",
+			report: domain.ImportReport{
+				DryRun: true, Total: 4, Imported: 2, Failed: 2,
+				Errors: []domain.ImportError{{Record: 2, Error: "title is required"}, {Record: 3, Error: `invalid completed "maybe"`}},
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "should create the valid records",
+			url:  route + "/import",
+			report: domain.ImportReport{
+				Total: 4, Imported: 1, Failed: 3,
+				Errors: []domain.ImportError{{Record: 2, Error: "title is required"}, {Record: 3, Error: `invalid completed "maybe"`}, {Record: 4, Error: "task already exists"}},
+			},
+			statusCode: http.StatusCreated,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.POST("/tasks/import", handler.ImportTasks)
+			mockRepo.On("ApplyTaskChanges", mockUserID, mock.MatchedBy(func(changes []domain.TaskChange) bool {
+				return len(changes) == 2 && changes[0].Task.Title == "Milk" && changes[1].Task.Title == "Bread" &&
+					changes[1].Task.Completed && changes[1].Task.OwnerID == mockUserID
+			}), false).Return([]domain.TaskChangeResult{{Task: taskResponse}, {Err: domain.ErrTaskExists}}, nil)
+
+			req, _ := http.NewRequest("POST", testCase.url, strings.NewReader(body))
+			req.Header.Set("Content-Type", "text/csv")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			var report domain.ImportReport
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &report))
+			assert.Equal(t, testCase.report, report)
+			if testCase.report.DryRun {
+				mockRepo.AssertNotCalled(t, "ApplyTaskChanges", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("should return bad request when the CSV has no title column", func(t *testing.T) {
+		_, handler, router := configuration()
+		router.POST("/tasks/import", handler.ImportTasks)
+		req, _ := http.NewRequest("POST", route+"/import?format=csv", strings.NewReader("name\nMilk\n"))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestTaskHandler_DeleteTask(t *testing.T) {
+	testCases := []valuesTestCases{
+		{
+			name:       "Delete task",
+			id:         "12334556778",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "should return an error when repository return an error",
+			id:         "12334556778",
+			err:        errors.New("some error"),
+			isError:    true,
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "should return not found when the task belongs to another user",
+			id:         "12334556778",
+			err:        domain.ErrTaskNotFound,
+			isError:    true,
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.DELETE("/tasks/:id", handler.DeleteTask)
+			mockRepo.On("GetTask", mockUserID, testCase.id).Return(taskResponse, nil)
+			mockRepo.On("DeleteTask", mockUserID, testCase.id, mockUserID).Return(testCase.err)
+			req, _ := mockRequestEndPoint(testCase.isErrorBody, "DELETE", route+"/"+testCase.id, nil)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if testCase.isError {
+				assert.Equal(t, testCase.statusCode, resp.Code)
+			} else {
+				assert.Equal(t, testCase.statusCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_Actor(t *testing.T) {
+	stored := &domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", Description: "description", Version: 1}
+	byUser := mock.MatchedBy(func(task *domain.Task) bool { return task.UpdatedBy == mockUserID })
+
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+	}{
+		{name: "should record the user who creates a task", method: "POST", url: route, body: `{"title": "title", "description": "description"}`},
+		{name: "should record the user who patches a task", method: "PATCH", url: route + "/1", body: `{"completed": true}`},
+		{name: "should record the user who replaces a task", method: "PUT", url: route + "/1", body: `{"title": "title", "description": "description"}`},
+		{name: "should record the user who deletes a task", method: "DELETE", url: route + "/1"},
+		{name: "should record the user who runs a batch", method: "POST", url: route + ":batch", body: `{"operations": [{"op": "create", "task": {"title": "title", "description": "description"}}, {"op": "delete", "id": "1"}]}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.POST("/tasks", handler.RegisterTask)
+			router.POST("/tasks:batch", handler.BatchTasks)
+			router.PUT("/tasks/:id", handler.UpdateTask)
+			router.PATCH("/tasks/:id", handler.PatchTask)
+			router.DELETE("/tasks/:id", handler.DeleteTask)
+
+			mockRepo.On("GetTask", mockUserID, "1").Return(stored, nil)
+			mockRepo.On("CreateTask", byUser).Return(stored, nil)
+			mockRepo.On("UpdateTask", mockUserID, "1", byUser).Return(stored, nil)
+			mockRepo.On("DeleteTask", mockUserID, "1", mockUserID).Return(nil)
+			mockRepo.On("ApplyTaskChanges", mockUserID, mock.MatchedBy(func(changes []domain.TaskChange) bool {
+				return changes[0].ActorID == mockUserID && changes[0].Task.UpdatedBy == mockUserID && changes[1].ActorID == mockUserID
+			}), false).Return([]domain.TaskChangeResult{{Task: stored}, {}}, nil)
+			req, _ := http.NewRequest(testCase.method, testCase.url, strings.NewReader(testCase.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Less(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+		})
+	}
+}
+
+func TestTaskHandler_Tags(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		tags       []string
+		statusCode int
+	}{
+		{
+			name:       "should normalize the tags of a new task",
+			body:       `{"title": "title", "description": "description", "tags": ["Urgent", " backend", "urgent"]}`,
+			tags:       []string{"backend", "urgent"},
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "should return bad request when a tag has spaces",
+			body:       `{"title": "title", "description": "description", "tags": ["two words"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when a tag is empty",
+			body:       `{"title": "title", "description": "description", "tags": [" "]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "should return bad request when a tag is too long",
+			body:       `{"title": "title", "description": "description", "tags": ["` + strings.Repeat("a", domain.MaxTagLength+1) + `"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configuration()
+			router.POST("/tasks", handler.RegisterTask)
+			mockRepo.On("CreateTask", mock.Anything).Return(func(task *domain.Task) (*domain.Task, error) { return task, nil })
+			req, _ := http.NewRequest("POST", route, strings.NewReader(testCase.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.tags != nil {
+				var task domain.Task
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &task))
+				assert.Equal(t, testCase.tags, task.Tags)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_GetTags(t *testing.T) {
+	mockRepo, handler, router := configuration()
+	router.GET("/tags", handler.GetTags)
+	mockRepo.On("GetTagCounts", mockUserID).Return([]domain.TagCount{{Tag: "urgent", Count: 3}, {Tag: "backend", Count: 1}}, nil)
+	req, _ := http.NewRequest("GET", "/tags", nil)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"tags": [{"tag": "urgent", "count": 3}, {"tag": "backend", "count": 1}]}`, resp.Body.String())
+}
+
+// sharedLists are lists of another user: list-1 is shared with mockUserID
+// as editor, list-2 as viewer and list-3 not at all.
+func sharedLists() []*domain.List {
+	return []*domain.List{
+		{ID: "list-1", OwnerID: "user-2", Members: []domain.ListMember{{UserID: mockUserID, Username: "cristianm", Role: domain.ListEditor}}},
+		{ID: "list-2", OwnerID: "user-2", Members: []domain.ListMember{{UserID: mockUserID, Username: "cristianm", Role: domain.ListViewer}}},
+		{ID: "list-3", OwnerID: "user-2"},
+	}
+}
+
+func TestTaskHandler_SharedLists(t *testing.T) {
+	// Task "in-list-N" of user-2 is in list-N.
+	getTask := func(ownerID, id string) (*domain.Task, error) {
+		if ownerID != "user-2" || !strings.HasPrefix(id, "in-") {
+			return nil, domain.ErrTaskNotFound
+		}
+		return &domain.Task{ID: id, OwnerID: ownerID, ListID: strings.TrimPrefix(id, "in-"), Title: "title", Description: "description", Version: 1}, nil
+	}
+
+	testCases := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		statusCode int
+	}{
+		{name: "should read a task of a list shared as viewer", method: "GET", url: route + "/in-list-2", statusCode: http.StatusOK},
+		{name: "should return not found for a task of a list not shared", method: "GET", url: route + "/in-list-3", statusCode: http.StatusNotFound},
+		{name: "should update a task of a list shared as editor", method: "PUT", url: route + "/in-list-1", body: `{"title": "title", "description": "description", "list_id": "list-1"}`, statusCode: http.StatusOK},
+		{name: "should return forbidden when updating a task of a list shared as viewer", method: "PUT", url: route + "/in-list-2", body: `{"title": "title", "description": "description", "list_id": "list-2"}`, statusCode: http.StatusForbidden},
+		{name: "should return forbidden when a member moves a task out of the list", method: "PATCH", url: route + "/in-list-1", body: `{"list_id": null}`, statusCode: http.StatusForbidden},
+		{name: "should return forbidden when deleting a task of a list shared as viewer", method: "DELETE", url: route + "/in-list-2", statusCode: http.StatusForbidden},
+		{name: "should delete a task of a list shared as editor", method: "DELETE", url: route + "/in-list-1", statusCode: http.StatusOK},
+		{name: "should create a task in a list shared as editor for the list owner", method: "POST", url: route, body: `{"title": "title", "description": "description", "list_id": "list-1"}`, statusCode: http.StatusCreated},
+		{name: "should return forbidden when creating a task in a list shared as viewer", method: "POST", url: route, body: `{"title": "title", "description": "description", "list_id": "list-2"}`, statusCode: http.StatusForbidden},
+		{name: "should throw an error when creating a task in a list not shared", method: "POST", url: route, body: `{"title": "title", "description": "description", "list_id": "list-3"}`, statusCode: http.StatusBadRequest},
+		{name: "should query the tasks of a list shared as viewer", method: "GET", url: route + "?list_id=list-2", statusCode: http.StatusOK},
+		{name: "should return not found when querying a list not shared", method: "GET", url: route + "?list_id=list-3", statusCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configurationWithLists(sharedLists()...)
+			router.POST("/tasks", handler.RegisterTask)
+			router.GET("/tasks", handler.GetAllTask)
+			router.GET("/tasks/:id", handler.GetTaskByID)
+			router.PUT("/tasks/:id", handler.UpdateTask)
+			router.PATCH("/tasks/:id", handler.PatchTask)
+			router.DELETE("/tasks/:id", handler.DeleteTask)
+			mockRepo.On("GetTask", mock.Anything, mock.Anything).Return(getTask)
+			mockRepo.On("UpdateTask", "user-2", mock.Anything, mock.Anything).Return(func(ownerID, id string, task *domain.Task) (*domain.Task, error) {
+				return task, nil
+			})
+			mockRepo.On("DeleteTask", "user-2", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateTask", mock.MatchedBy(func(task *domain.Task) bool { return task.OwnerID == "user-2" })).Return(func(task *domain.Task) (*domain.Task, error) {
+				return task, nil
+			})
+			mockRepo.On("QueryTasks", mock.MatchedBy(func(query domain.TaskQuery) bool {
+				return query.OwnerID == "user-2" && query.ListID == "list-2"
+			})).Return(&domain.TaskPage{Tasks: []*domain.Task{}}, nil)
+
+			req, _ := http.NewRequest(testCase.method, testCase.url, strings.NewReader(testCase.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code, resp.Body.String())
+		})
+	}
+}
+
+func mockRequestEndPoint(isError bool, method string, api string, body io.Reader) (*http.Request, error) {
+	if isError {
+		return http.NewRequest(method, api, strings.NewReader("Invalid Body"))
+	}
+
+	req, _ := http.NewRequest(method, api, body)
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+func configuration() (*mocks.TaskRepository, *httpHandler.TaskHandler, *gin.Engine) {
+	return configurationWithLists()
+}
+
+// configurationWithLists also stores lists in an in-memory list repository.
+func configurationWithLists(lists ...*domain.List) (*mocks.TaskRepository, *httpHandler.TaskHandler, *gin.Engine) {
+	mockRepo, _, handler, router := configurationWithAudit(lists...)
+	return mockRepo, handler, router
+}
+
+// configurationWithAudit also returns the in-memory audit repository of the service.
+func configurationWithAudit(lists ...*domain.List) (*mocks.TaskRepository, *memory.InMemoryAuditRepository, *httpHandler.TaskHandler, *gin.Engine) {
+	mockRepo := new(mocks.TaskRepository)
+	listRepo := memory.NewInMemoryListRepository()
+	for _, list := range lists {
+		_, _ = listRepo.CreateList(list)
+	}
+	auditRepo := memory.NewInMemoryAuditRepository()
+	taskService := app.NewTaskService(mockRepo, listRepo, auditRepo)
+	handler := httpHandler.NewTaskHandler(taskService)
+
+	router := gin.Default()
+
+	router.Use(middleware.ErrorHandler(), MockAuthMiddleware())
+	return mockRepo, auditRepo, handler, router
+}
+
+func MockAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, mockUserID)
+		c.Next()
+	}
+}
+
+func TestTaskHandler_History(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	handler := httpHandler.NewTaskHandler(app.NewTaskService(repo, memory.NewInMemoryListRepository(), repo))
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler(), MockAuthMiddleware())
+	router.PUT("/tasks/:id", handler.UpdateTask)
+	router.GET("/tasks/:id/history", handler.GetTaskHistory)
+	router.POST("/tasks/:id/history/:revision/restore", handler.RestoreTaskRevision)
+
+	now := time.Now().UTC()
+	_, err := repo.CreateTask(&domain.Task{
+		ID: "1", OwnerID: mockUserID, Title: "title", Description: "description", Version: 1, UpdatedBy: mockUserID, CreatedAt: now, UpdatedAt: now,
+	})
+	require.NoError(t, err)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := serve("PUT", route+"/1", `{"title": "renamed", "description": "description"}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	resp = serve("GET", route+"/1/history", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var page domain.AuditPage
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, domain.AuditUpdate, page.Entries[0].Action)
+	assert.Equal(t, mockUserID, page.Entries[0].ActorID)
+	assert.Equal(t, int64(2), page.Entries[0].Revision)
+	assert.Equal(t, []domain.FieldChange{{Field: "title", Before: []byte(`"title"`), After: []byte(`"renamed"`)}}, page.Entries[0].Changes)
+
+	resp = serve("GET", route+"/1/history?limit=1", "")
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+	assert.Len(t, page.Entries, 1)
+	assert.NotEmpty(t, page.NextCursor)
+
+	resp = serve("POST", route+"/1/history/1/restore", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var restored domain.Task
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &restored))
+	assert.Equal(t, "title", restored.Title)
+	assert.Equal(t, int64(3), restored.Version)
+	assert.Equal(t, `"3"`, resp.Header().Get("ETag"))
+
+	resp = serve("GET", route+"/1/history?action=restore", "")
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 1)
+	assert.Equal(t, int64(3), page.Entries[0].Revision)
+
+	testCases := []struct {
+		name       string
+		url        string
+		statusCode int
+	}{
+		{name: "should return bad request for a malformed revision", url: route + "/1/history/first/restore", statusCode: http.StatusBadRequest},
+		{name: "should return not found for an unknown revision", url: route + "/1/history/9/restore", statusCode: http.StatusNotFound},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp := serve("POST", testCase.url, "")
+			assert.Equal(t, testCase.statusCode, resp.Code, resp.Body.String())
+		})
+	}
+
+	t.Run("should return bad request for a malformed filter", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("GET", route+"/1/history?since=yesterday", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("GET", route+"/1/history?action=rename", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("GET", route+"/1/history?cursor=first", "").Code)
+	})
+}
+
+func TestTaskHandler_HistoryOfDeletedTask(t *testing.T) {
+	stored := &domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", Description: "description", Version: 1}
+
+	testCases := []struct {
+		name       string
+		deletedBy  string
+		statusCode int
+	}{
+		{name: "should return the history of a task the user deleted", deletedBy: mockUserID, statusCode: http.StatusOK},
+		{name: "should return not found for a task of another owner", deletedBy: "user-2", statusCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, auditRepo, handler, router := configurationWithAudit()
+			router.GET("/tasks/:id/history", handler.GetTaskHistory)
+			router.POST("/tasks/:id/history/:revision/restore", handler.RestoreTaskRevision)
+			mockRepo.On("GetTask", mockUserID, "1").Return(nil, domain.ErrTaskNotFound)
+
+			task := *stored
+			task.OwnerID = testCase.deletedBy
+			deleted := domain.NewAuditEntry("audit-1", testCase.deletedBy, "", &task, nil, time.Now().UTC())
+			require.NoError(t, auditRepo.AppendAudit([]*domain.AuditEntry{&deleted}))
+
+			req, _ := http.NewRequest("GET", route+"/1/history", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			assert.Equal(t, testCase.statusCode, resp.Code, resp.Body.String())
+
+			req, _ = http.NewRequest("POST", route+"/1/history/1/restore", nil)
+			resp = httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusNotFound, resp.Code, "deleted tasks cannot be restored to a revision")
+		})
+	}
+}
+
+func TestTaskHandler_Trash(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := &domain.List{ID: "list-1", OwnerID: mockUserID, Name: "home"}
+
+	testCases := []struct {
+		name       string
+		listID     string
+		statusCode int
+		restoredIn string
+	}{
+		{name: "should restore a task from the trash", statusCode: http.StatusOK},
+		{name: "should restore a task to its list", listID: "list-1", statusCode: http.StatusOK, restoredIn: "list-1"},
+		{name: "should restore a task out of a deleted list", listID: "list-2", statusCode: http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, handler, router := configurationWithLists(list)
+			router.GET("/trash", handler.GetTrash)
+			router.POST("/tasks/:id/restore", handler.RestoreTask)
+
+			trashed := &domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", ListID: testCase.listID, Version: 2, DeletedAt: &deletedAt}
+			restored := &domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", ListID: testCase.listID, Version: 3, UpdatedBy: mockUserID}
+			mockRepo.On("GetTrash", mockUserID).Return([]*domain.Task{trashed}, nil)
+			mockRepo.On("RestoreTask", mockUserID, "1", mockUserID).Return(restored, nil).Once()
+			mockRepo.On("GetTask", mockUserID, "1").Return(restored, nil)
+			mockRepo.On("UpdateTask", mockUserID, "1", mock.MatchedBy(func(task *domain.Task) bool {
+				return task.ListID == "" && task.Version == 3
+			})).Return(&domain.Task{ID: "1", OwnerID: mockUserID, Title: "title", Version: 4}, nil)
+
+			req, _ := http.NewRequest("GET", "/trash", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"deleted_at":"2024-01-01T00:00:00Z"`)
+
+			req, _ = http.NewRequest("POST", route+"/1/restore", nil)
+			resp = httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			require.Equal(t, testCase.statusCode, resp.Code, resp.Body.String())
+
+			var task domain.Task
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &task))
+			assert.Equal(t, testCase.restoredIn, task.ListID)
+			assert.Nil(t, task.DeletedAt)
+
+		})
+	}
+
+	t.Run("should return not found for a task missing from the trash", func(t *testing.T) {
+		mockRepo, handler, router := configuration()
+		router.POST("/tasks/:id/restore", handler.RestoreTask)
+		mockRepo.On("GetTrash", mockUserID).Return([]*domain.Task{}, nil)
+
+		req, _ := http.NewRequest("POST", route+"/1/restore", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		mockRepo.AssertNotCalled(t, "RestoreTask", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
@@ -19,13 +19,13 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 	var request *domain.UserRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
 		return
 	}
 
 	token, err := h.service.Register(request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -36,15 +36,48 @@ func (h *UserHandler) LoginUser(c *gin.Context) {
 	var request domain.User
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
 		return
 	}
 
-	token, err := h.service.Login(request)
+	response, err := h.service.Login(request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var request domain.RefreshRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	response, err := h.service.Refresh(request.RefreshToken)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) Logout(c *gin.Context) {
+	var request domain.RefreshRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	if err := h.service.Logout(request.RefreshToken); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
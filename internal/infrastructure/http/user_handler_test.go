@@ -9,9 +9,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"todo-list-task/internal/app"
 	"todo-list-task/internal/domain"
 	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/middleware"
+	"todo-list-task/internal/utils"
 	"todo-list-task/mocks"
 )
 
@@ -39,6 +43,10 @@ var userResponse = &domain.UserResponse{
 	Token: "123DSASDEFRGR",
 }
 
+var jwtManager = utils.NewJWTManager("test-secret", time.Hour)
+
+var loginToken, _ = jwtManager.GenerateJWT("user-1", "cristianm", "user")
+
 func TestUserHandler_RegisterUser(t *testing.T) {
 	testCases := []valuesTestCasesUser{
 		{
@@ -61,6 +69,13 @@ func TestUserHandler_RegisterUser(t *testing.T) {
 			body:       userRequest,
 			err:        assert.AnError,
 		},
+		{
+			name:       "Should return conflict when username already exists",
+			statusCode: http.StatusConflict,
+			isError:    true,
+			body:       userRequest,
+			err:        domain.ErrUsernameTaken,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -87,14 +102,38 @@ func TestUserHandler_RegisterUser(t *testing.T) {
 	}
 }
 
+func TestUserHandler_RegisterUser_AssignsRole(t *testing.T) {
+	testCases := []struct {
+		name     string
+		username string
+		role     string
+	}{
+		{name: "Should register a regular user", username: "cristianm", role: domain.RoleUser},
+		{name: "Should not grant the admin role at registration", username: "admin", role: domain.RoleUser},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo, handler, router := configurationUser()
+			router.POST("/users", handler.RegisterUser)
+			mockRepo.On("Create", mock.MatchedBy(func(user *domain.User) bool { return user.Role == tc.role })).Return("token", nil)
+
+			resp := postJSON(router, "/users", &domain.UserRequest{Username: tc.username, Password: "password"})
+
+			assert.Equal(t, http.StatusCreated, resp.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestUserHandler_LoginUser(t *testing.T) {
 	testCases := []valuesTestCasesUser{
 		{
 			name:         "Should login user when body is correct",
 			body:         userRequest,
-			userResponse: userResponse,
+			userResponse: &domain.UserResponse{Token: loginToken},
 			statusCode:   http.StatusOK,
-			token:        "123DSASDEFRGR",
+			token:        loginToken,
 		},
 		{
 			name:        "Should throw an error when body is incorrect",
@@ -109,6 +148,20 @@ func TestUserHandler_LoginUser(t *testing.T) {
 			body:       userRequest,
 			err:        assert.AnError,
 		},
+		{
+			name:       "Should return unauthorized when credentials are wrong",
+			statusCode: http.StatusUnauthorized,
+			isError:    true,
+			body:       userRequest,
+			err:        domain.ErrInvalidCredentials,
+		},
+		{
+			name:       "Should return forbidden when the user is disabled",
+			statusCode: http.StatusForbidden,
+			isError:    true,
+			body:       userRequest,
+			err:        domain.ErrUserDisabled,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -128,7 +181,8 @@ func TestUserHandler_LoginUser(t *testing.T) {
 				var response *domain.UserResponse
 				err := json.Unmarshal(resp.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, tc.userResponse, response)
+				assert.Equal(t, tc.userResponse.Token, response.Token)
+				assert.NotEmpty(t, response.RefreshToken)
 				assert.Equal(t, tc.statusCode, resp.Code)
 			}
 
@@ -136,12 +190,89 @@ func TestUserHandler_LoginUser(t *testing.T) {
 	}
 }
 
+func TestUserHandler_RefreshToken(t *testing.T) {
+	mockRepo, handler, router := configurationUser()
+	router.POST("/login", handler.LoginUser)
+	router.POST("/token/refresh", handler.RefreshToken)
+	router.POST("/logout", handler.Logout)
+	mockRepo.On("Login", mock.Anything).Return(loginToken, nil)
+
+	login := postJSON(router, "/login", userRequest)
+	assert.Equal(t, http.StatusOK, login.Code)
+	first := decodeUserResponse(t, login)
+
+	refreshed := postJSON(router, "/token/refresh", domain.RefreshRequest{RefreshToken: first.RefreshToken})
+	assert.Equal(t, http.StatusOK, refreshed.Code)
+	second := decodeUserResponse(t, refreshed)
+	assert.NotEmpty(t, second.Token)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	claims, err := jwtManager.ValidateJWT(second.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.(*utils.Claims).UserID)
+
+	t.Run("Should reject and revoke the family when a rotated token is reused", func(t *testing.T) {
+		reused := postJSON(router, "/token/refresh", domain.RefreshRequest{RefreshToken: first.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, reused.Code)
+
+		revoked := postJSON(router, "/token/refresh", domain.RefreshRequest{RefreshToken: second.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, revoked.Code)
+	})
+
+	t.Run("Should reject an unknown token", func(t *testing.T) {
+		resp := postJSON(router, "/token/refresh", domain.RefreshRequest{RefreshToken: "unknown"})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Should throw an error when body is incorrect", func(t *testing.T) {
+		req, _ := mockRequestEndPoint(true, "POST", "/token/refresh", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestUserHandler_Logout(t *testing.T) {
+	mockRepo, handler, router := configurationUser()
+	router.POST("/login", handler.LoginUser)
+	router.POST("/token/refresh", handler.RefreshToken)
+	router.POST("/logout", handler.Logout)
+	mockRepo.On("Login", mock.Anything).Return(loginToken, nil)
+
+	login := decodeUserResponse(t, postJSON(router, "/login", userRequest))
+
+	logout := postJSON(router, "/logout", domain.RefreshRequest{RefreshToken: login.RefreshToken})
+	assert.Equal(t, http.StatusOK, logout.Code)
+
+	refreshed := postJSON(router, "/token/refresh", domain.RefreshRequest{RefreshToken: login.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, refreshed.Code)
+
+	unknown := postJSON(router, "/logout", domain.RefreshRequest{RefreshToken: "unknown"})
+	assert.Equal(t, http.StatusUnauthorized, unknown.Code)
+}
+
+func postJSON(router *gin.Engine, route string, body interface{}) *httptest.ResponseRecorder {
+	bodyBytes, _ := json.Marshal(body)
+	req, _ := mockRequestEndPoint(false, "POST", route, bytes.NewBuffer(bodyBytes))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func decodeUserResponse(t *testing.T, resp *httptest.ResponseRecorder) *domain.UserResponse {
+	var response *domain.UserResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	return response
+}
+
 func configurationUser() (*mocks.UserRepository, *httpHandler.UserHandler, *gin.Engine) {
 	mockRepo := new(mocks.UserRepository)
-	userService := app.NewUserService(mockRepo)
+	tokenService := app.NewTokenService(memory.NewInMemoryRefreshTokenRepository(), jwtManager, time.Hour)
+	userService := app.NewUserService(mockRepo, tokenService)
 	handler := httpHandler.NewUserHandler(userService)
 
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
 
 	return mockRepo, handler, router
 }
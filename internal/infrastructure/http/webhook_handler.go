@@ -0,0 +1,78 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/middleware"
+)
+
+// WebhookHandler serves the endpoints that manage the webhooks of a user and
+// expose their deliveries.
+type WebhookHandler struct {
+	service *app.WebhookService
+}
+
+func NewWebhookHandler(service *app.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// CreateWebhook registers a webhook. The response is the only one that
+// carries its signing secret.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var request domain.WebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(domain.NewError(domain.ErrValidation, err.Error()))
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(middleware.CurrentUserID(c), request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.service.GetWebhooks(middleware.CurrentUserID(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.service.DeleteWebhook(middleware.CurrentUserID(c), c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetDeliveries returns the delivery log of a webhook, newest first.
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	deliveries, err := h.service.GetDeliveries(middleware.CurrentUserID(c), c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// GetDeadLetters returns the deliveries that failed every attempt.
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	deliveries, err := h.service.GetDeadLetters(middleware.CurrentUserID(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
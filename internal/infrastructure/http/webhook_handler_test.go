@@ -0,0 +1,166 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	httpHandler "todo-list-task/internal/infrastructure/http"
+	"todo-list-task/internal/middleware"
+	"todo-list-task/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func configurationWebhook() (*mocks.WebhookRepository, *gin.Engine) {
+	repo := new(mocks.WebhookRepository)
+	resolver := new(mocks.HostResolver)
+	resolver.On("LookupNetIP", mock.Anything, "ip", "example.com").Return([]netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil)
+	handler := httpHandler.NewWebhookHandler(app.NewWebhookService(repo, resolver, false))
+
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler(), MockAuthMiddleware())
+	router.POST("/webhooks", handler.CreateWebhook)
+	router.GET("/webhooks", handler.GetWebhooks)
+	router.GET("/webhooks/dead-letters", handler.GetDeadLetters)
+	router.DELETE("/webhooks/:id", handler.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", handler.GetDeliveries)
+
+	return repo, router
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Should register the webhook and show its secret",
+			body:       `{"url": "https://example.com/hooks", "events": ["task.completed"]}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Should throw an error when the url is missing",
+			body:       `{"events": ["task.completed"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Should throw an error when the url is not http",
+			body:       `{"url": "ftp://example.com/hooks", "events": ["task.completed"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Should throw an error when the url targets a private address",
+			body:       `{"url": "http://10.0.0.7/hooks", "events": ["task.completed"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Should throw an error when an event type is unknown",
+			body:       `{"url": "https://example.com/hooks", "events": ["task.archived"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Should throw an error when there are no events",
+			body:       `{"url": "https://example.com/hooks", "events": []}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo, router := configurationWebhook()
+			repo.On("CreateWebhook", mock.MatchedBy(func(webhook *domain.Webhook) bool {
+				return webhook.OwnerID == mockUserID && webhook.URL == "https://example.com/hooks"
+			})).Return(nil)
+
+			req, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode == http.StatusCreated {
+				var webhook domain.Webhook
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &webhook))
+				assert.Equal(t, []string{domain.TaskCompleted}, webhook.Events)
+				assert.NotEmpty(t, webhook.Secret)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_GetWebhooks(t *testing.T) {
+	repo, router := configurationWebhook()
+	repo.On("GetWebhooks", mockUserID).Return([]*domain.Webhook{
+		{ID: "webhook-1", OwnerID: mockUserID, URL: "https://example.com/hooks", Events: []string{domain.TaskCreated}, Secret: "whsec_1"},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/webhooks", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"id":"webhook-1"`)
+	assert.NotContains(t, resp.Body.String(), "whsec_1")
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		statusCode int
+	}{
+		{name: "Should delete the webhook", statusCode: http.StatusOK},
+		{name: "Should return not found for webhooks of other users", err: domain.ErrWebhookNotFound, statusCode: http.StatusNotFound},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo, router := configurationWebhook()
+			repo.On("DeleteWebhook", mockUserID, "webhook-1").Return(testCase.err)
+
+			req, _ := http.NewRequest("DELETE", "/webhooks/webhook-1", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	dead := &domain.WebhookDelivery{ID: "delivery-1", WebhookID: "webhook-1", OwnerID: mockUserID, EventType: domain.TaskDeleted, Status: domain.DeliveryDead, Attempts: 5}
+	testCases := []struct {
+		name       string
+		url        string
+		statusCode int
+	}{
+		{name: "Should return the delivery log of a webhook", url: "/webhooks/webhook-1/deliveries", statusCode: http.StatusOK},
+		{name: "Should return not found for unknown webhooks", url: "/webhooks/webhook-2/deliveries", statusCode: http.StatusNotFound},
+		{name: "Should return the dead letters of the user", url: "/webhooks/dead-letters", statusCode: http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo, router := configurationWebhook()
+			repo.On("GetDeliveries", mockUserID, "webhook-1").Return([]*domain.WebhookDelivery{dead}, nil)
+			repo.On("GetDeliveries", mockUserID, "webhook-2").Return(nil, domain.ErrWebhookNotFound)
+			repo.On("GetDeadDeliveries", mockUserID).Return([]*domain.WebhookDelivery{dead}, nil)
+
+			req, _ := http.NewRequest("GET", testCase.url, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testCase.statusCode, resp.Code)
+			if testCase.statusCode == http.StatusOK {
+				assert.JSONEq(t, `{"deliveries": [{
+					"id": "delivery-1", "webhook_id": "webhook-1", "owner_id": "user-1", "event_id": "", "event_type": "task.deleted",
+					"payload": null, "status": "dead", "attempts": 5, "created_at": "0001-01-01T00:00:00Z"
+				}]}`, resp.Body.String())
+			}
+		})
+	}
+}
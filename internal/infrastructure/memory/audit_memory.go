@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"sync"
+	"todo-list-task/internal/domain"
+)
+
+// InMemoryAuditRepository is an AuditRepository kept in memory.
+type InMemoryAuditRepository struct {
+	// entries are kept oldest first.
+	entries []*domain.AuditEntry
+	mu      sync.RWMutex
+}
+
+func NewInMemoryAuditRepository() *InMemoryAuditRepository {
+	return &InMemoryAuditRepository{}
+}
+
+// AppendAudit stores copies of entries, numbering them in order.
+func (r *InMemoryAuditRepository) AppendAudit(entries []*domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.Seq = int64(len(r.entries)) + 1
+		stored := *entry
+		r.entries = append(r.entries, &stored)
+	}
+	return nil
+}
+
+// QueryAudit returns one page of the stored entries, newest first.
+func (r *InMemoryAuditRepository) QueryAudit(query domain.AuditQuery) (*domain.AuditPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := domain.PageAudit(r.entries, query)
+	for i, entry := range page.Entries {
+		copied := *entry
+		page.Entries[i] = &copied
+	}
+	return page, nil
+}
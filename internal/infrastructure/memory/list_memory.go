@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+	"todo-list-task/internal/domain"
+)
+
+// InMemoryListRepository is a ListRepository kept in memory. Lists are
+// copied in and out so callers never share the stored members.
+type InMemoryListRepository struct {
+	lists map[string]domain.List
+	mu    sync.RWMutex
+}
+
+func NewInMemoryListRepository() *InMemoryListRepository {
+	return &InMemoryListRepository{
+		lists: make(map[string]domain.List),
+	}
+}
+
+func cloneList(list domain.List) *domain.List {
+	list.Members = slices.Clone(list.Members)
+	if list.Members == nil {
+		list.Members = []domain.ListMember{}
+	}
+	return &list
+}
+
+// CreateList creates a new list in the in-memory repository.
+func (r *InMemoryListRepository) CreateList(list *domain.List) (*domain.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lists[list.ID] = *cloneList(*list)
+	return cloneList(*list), nil
+}
+
+// GetList get a list by id in the in-memory repository
+func (r *InMemoryListRepository) GetList(id string) (*domain.List, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list, ok := r.lists[id]
+	if !ok {
+		return nil, domain.ErrListNotFound
+	}
+	return cloneList(list), nil
+}
+
+// GetListsForUser get the lists owned by or shared with userID in the in-memory repository
+func (r *InMemoryListRepository) GetListsForUser(userID string) ([]*domain.List, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var lists []*domain.List
+	for _, list := range r.lists {
+		if list.RoleOf(userID) != "" {
+			lists = append(lists, cloneList(list))
+		}
+	}
+	return lists, nil
+}
+
+// UpdateList update the name of a list in the in-memory repository
+func (r *InMemoryListRepository) UpdateList(list *domain.List) (*domain.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.lists[list.ID]
+	if !ok {
+		return nil, domain.ErrListNotFound
+	}
+	stored.Name = list.Name
+	stored.UpdatedAt = list.UpdatedAt
+	r.lists[list.ID] = stored
+	return cloneList(stored), nil
+}
+
+// DeleteList delete a list in the in-memory repository
+func (r *InMemoryListRepository) DeleteList(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.lists[id]; !ok {
+		return domain.ErrListNotFound
+	}
+	delete(r.lists, id)
+	return nil
+}
+
+// SetMember add or update a member of a list in the in-memory repository
+func (r *InMemoryListRepository) SetMember(listID string, member domain.ListMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, ok := r.lists[listID]
+	if !ok {
+		return domain.ErrListNotFound
+	}
+	list.SetMember(member)
+	r.lists[listID] = list
+	return nil
+}
+
+// RemoveMember remove a member of a list in the in-memory repository
+func (r *InMemoryListRepository) RemoveMember(listID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, ok := r.lists[listID]
+	if !ok {
+		return domain.ErrListNotFound
+	}
+	if err := list.RemoveMember(userID); err != nil {
+		return err
+	}
+	r.lists[listID] = list
+	return nil
+}
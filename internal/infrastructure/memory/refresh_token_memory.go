@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"sync"
+	"time"
+	"todo-list-task/internal/domain"
+)
+
+type InMemoryRefreshTokenRepository struct {
+	tokens   map[string]*domain.RefreshToken
+	byHash   map[string]string
+	families map[string][]string
+	mu       sync.RWMutex
+}
+
+func NewInMemoryRefreshTokenRepository() *InMemoryRefreshTokenRepository {
+	return &InMemoryRefreshTokenRepository{
+		tokens:   make(map[string]*domain.RefreshToken),
+		byHash:   make(map[string]string),
+		families: make(map[string][]string),
+	}
+}
+
+// Create stores a new refresh token.
+func (r *InMemoryRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *token
+	r.tokens[token.ID] = &stored
+	r.byHash[token.TokenHash] = token.ID
+	r.families[token.FamilyID] = append(r.families[token.FamilyID], token.ID)
+	return nil
+}
+
+// GetByHash returns a copy of the refresh token with the given hash.
+func (r *InMemoryRefreshTokenRepository) GetByHash(hash string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byHash[hash]
+	if !ok {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	token := *r.tokens[id]
+	return &token, nil
+}
+
+// MarkRotated flags a refresh token as used.
+func (r *InMemoryRefreshTokenRepository) MarkRotated(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return domain.ErrInvalidRefreshToken
+	}
+	if token.RotatedAt != nil {
+		return domain.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	token.RotatedAt = &now
+	return nil
+}
+
+// RevokeFamily revokes every refresh token rotated from the same login.
+func (r *InMemoryRefreshTokenRepository) RevokeFamily(familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, id := range r.families[familyID] {
+		if token := r.tokens[id]; token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// RevokeUser revokes every refresh token issued to a user.
+func (r *InMemoryRefreshTokenRepository) RevokeUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
@@ -1,21 +1,44 @@
 package memory
 
 import (
-	"errors"
+	"log"
 	"math/rand"
+	"slices"
 	"sync"
 	"time"
 	"todo-list-task/internal/domain"
 )
 
+// outboxBacklogWarning is the number of unpublished events past which the
+// outbox logs a warning, and again every time the backlog grows by as much.
+const outboxBacklogWarning = 10000
+
+// InMemoryTaskRepository keeps tasks in a map, indexed by owner so queries
+// only visit the owner's tasks, and with an inverted index from owner and tag
+// to task IDs so tag queries only visit tagged tasks. Its
+// outbox is a slice of events, with the set of their IDs, and its trash a
+// map of deleted tasks, guarded by the same lock as the tasks. Audit entries
+// are appended to its audit log while that lock is held, so the log never
+// misses a stored change.
 type InMemoryTaskRepository struct {
-	tasks map[string]*domain.Task
-	mu    sync.RWMutex
+	tasks   map[string]*domain.Task
+	owners  map[string]map[string]*domain.Task
+	tags    map[string]map[string]map[string]struct{}
+	outbox  []domain.TaskEvent
+	pending map[string]struct{}
+	trash   map[string]*domain.Task
+	audit   *InMemoryAuditRepository
+	mu      sync.RWMutex
 }
 
 func NewInMemoryTaskRepository() *InMemoryTaskRepository {
 	return &InMemoryTaskRepository{
-		tasks: make(map[string]*domain.Task),
+		tasks:   make(map[string]*domain.Task),
+		owners:  make(map[string]map[string]*domain.Task),
+		tags:    make(map[string]map[string]map[string]struct{}),
+		pending: make(map[string]struct{}),
+		trash:   make(map[string]*domain.Task),
+		audit:   NewInMemoryAuditRepository(),
 	}
 }
 
@@ -29,55 +52,344 @@ func (r *InMemoryTaskRepository) CreateTask(task *domain.Task) (*domain.Task, er
 	defer r.mu.Unlock()
 
 	simulateDelay()
-	r.tasks[task.ID] = task
+	r.store(task.ID, task)
+	r.record(domain.NewTaskChangeEvent(task.UpdatedBy, nil, task, task.UpdatedAt))
+	r.recordAudit(domain.NewTaskChangeAudit(task.UpdatedBy, "", nil, task, task.UpdatedAt))
 	return task, nil
 }
 
-// GetTask get a task in the in-memory repository
-func (r *InMemoryTaskRepository) GetTask(id string) (*domain.Task, error) {
+// GetTask get a task owned by ownerID in the in-memory repository
+func (r *InMemoryTaskRepository) GetTask(ownerID, id string) (*domain.Task, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	task, ok := r.tasks[id]
-	if !ok {
-		return nil, errors.New("task not found")
+	if !ok || task.OwnerID != ownerID {
+		return nil, domain.ErrTaskNotFound
 	}
 	return task, nil
 }
 
-// GetTasks get all task in the in-memory repository
-func (r *InMemoryTaskRepository) GetTasks() ([]*domain.Task, error) {
+// GetTasks get all tasks owned by ownerID in the in-memory repository
+func (r *InMemoryTaskRepository) GetTasks(ownerID string) ([]*domain.Task, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var tasks []*domain.Task
-	for _, task := range r.tasks {
+	for _, task := range r.owners[ownerID] {
 		tasks = append(tasks, task)
 	}
 	return tasks, nil
 }
 
-// UpdateTask update task by id in the in-memory repository
-func (r *InMemoryTaskRepository) UpdateTask(id string, task *domain.Task) (*domain.Task, error) {
+// QueryTasks get one page of the tasks matching query in the in-memory repository
+func (r *InMemoryTaskRepository) QueryTasks(query domain.TaskQuery) (*domain.TaskPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(query.Tags) > 0 {
+		return domain.PageTasks(r.taggedTasks(query), query)
+	}
+	owned := r.owners[query.OwnerID]
+	tasks := make([]*domain.Task, 0, len(owned))
+	for _, task := range owned {
+		tasks = append(tasks, task)
+	}
+	return domain.PageTasks(tasks, query)
+}
+
+// ScanTasks calls fn with every task of ownerID, oldest first, read from the
+// owner index before fn is first called.
+func (r *InMemoryTaskRepository) ScanTasks(ownerID string, fn func(*domain.Task) error) error {
+	r.mu.RLock()
+	owned := r.owners[ownerID]
+	tasks := make([]*domain.Task, 0, len(owned))
+	for _, task := range owned {
+		tasks = append(tasks, task)
+	}
+	r.mu.RUnlock()
+
+	domain.SortTasks(tasks, domain.TaskQuery{SortBy: domain.SortCreatedAt})
+	for _, task := range tasks {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taggedTasks returns the tasks of the query owner carrying every tag of the
+// query, or any of them, from the tag index.
+func (r *InMemoryTaskRepository) taggedTasks(query domain.TaskQuery) []*domain.Task {
+	index := r.tags[query.OwnerID]
+	var ids map[string]struct{}
+	for _, tag := range query.Tags {
+		tagged := index[tag]
+		switch {
+		case ids == nil:
+			ids = make(map[string]struct{}, len(tagged))
+			for id := range tagged {
+				ids[id] = struct{}{}
+			}
+		case query.AnyTag:
+			for id := range tagged {
+				ids[id] = struct{}{}
+			}
+		default:
+			for id := range ids {
+				if _, ok := tagged[id]; !ok {
+					delete(ids, id)
+				}
+			}
+		}
+	}
+
+	tasks := make([]*domain.Task, 0, len(ids))
+	for id := range ids {
+		tasks = append(tasks, r.tasks[id])
+	}
+	return tasks
+}
+
+// GetTagCounts get the tag catalog of ownerID from the tag index
+func (r *InMemoryTaskRepository) GetTagCounts(ownerID string) ([]domain.TagCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	catalog := make([]domain.TagCount, 0, len(r.tags[ownerID]))
+	for tag, ids := range r.tags[ownerID] {
+		catalog = append(catalog, domain.TagCount{Tag: tag, Count: len(ids)})
+	}
+	domain.SortTagCounts(catalog)
+	return catalog, nil
+}
+
+// GetTasksDueBetween get the open tasks with reminders due in (from, to] in the in-memory repository
+func (r *InMemoryTaskRepository) GetTasksDueBetween(from, to time.Time) ([]*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*domain.Task
+	for _, task := range r.tasks {
+		if domain.IsDueBetween(task, from, to) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// UpdateTask update task by id in the in-memory repository, checking its version
+func (r *InMemoryTaskRepository) UpdateTask(ownerID, id string, task *domain.Task) (*domain.Task, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.tasks[id]; !ok {
-		return nil, errors.New("task not found")
+	stored, ok := r.tasks[id]
+	if !ok || stored.OwnerID != ownerID {
+		return nil, domain.ErrTaskNotFound
+	}
+	if task.Version != 0 && task.Version != stored.Version {
+		return nil, domain.ErrVersionConflict
 	}
 	task.ID = id
-	r.tasks[id] = task
+	task.OwnerID = ownerID
+	task.CreatedAt = stored.CreatedAt
+	task.Version = stored.Version + 1
+	r.store(id, task)
+	r.record(domain.NewTaskChangeEvent(task.UpdatedBy, stored, task, task.UpdatedAt))
+	r.recordAudit(domain.NewTaskChangeAudit(task.UpdatedBy, "", stored, task, task.UpdatedAt))
 	return task, nil
 }
 
-// DeleteTask delete task by id in the in-memory repository
-func (r *InMemoryTaskRepository) DeleteTask(id string) error {
+// ApplyTaskChanges applies a batch of changes in the in-memory repository
+func (r *InMemoryTaskRepository) ApplyTaskChanges(ownerID string, changes []domain.TaskChange, atomic bool) ([]domain.TaskChangeResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	simulateDelay()
+	staged, results, events, err := domain.ApplyTaskChanges(ownerID, changes, atomic, func(id string) *domain.Task {
+		return r.tasks[id]
+	})
+	if err != nil {
+		return nil, err
+	}
+	for id, task := range staged {
+		r.store(id, task)
+	}
+	for _, trashed := range domain.TrashedTasks(changes, results) {
+		r.trash[trashed.ID] = trashed
+	}
+	for _, event := range events {
+		r.record(event)
+	}
+	r.recordAudit(domain.AuditTaskChanges(changes, results)...)
+	return results, nil
+}
+
+// DeleteTask move task by id to the trash in the in-memory repository
+func (r *InMemoryTaskRepository) DeleteTask(ownerID, id, actorID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.OwnerID != ownerID {
+		return domain.ErrTaskNotFound
+	}
+	now := time.Now().UTC()
+	r.store(id, nil)
+	r.trash[id] = domain.TrashTask(task, actorID, now)
+	r.record(domain.NewTaskChangeEvent(actorID, task, nil, now))
+	r.recordAudit(domain.NewTaskChangeAudit(actorID, "", task, nil, now))
+	return nil
+}
+
+// GetTrash get the trashed tasks of ownerID in the in-memory repository
+func (r *InMemoryTaskRepository) GetTrash(ownerID string) ([]*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []*domain.Task{}
+	for _, task := range r.trash {
+		if task.OwnerID == ownerID {
+			tasks = append(tasks, task)
+		}
+	}
+	domain.SortTrash(tasks)
+	return tasks, nil
+}
+
+// RestoreTask move a trashed task back in the in-memory repository
+func (r *InMemoryTaskRepository) RestoreTask(ownerID, id, actorID string) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trashed, ok := r.trash[id]
+	if !ok || trashed.OwnerID != ownerID {
+		return nil, domain.ErrTaskNotFound
+	}
+	restored := domain.RestoreTask(trashed, actorID, time.Now().UTC())
+	delete(r.trash, id)
+	r.store(id, restored)
+	r.record(domain.NewTaskChangeEvent(actorID, trashed, restored, restored.UpdatedAt))
+	r.recordAudit(domain.NewTaskChangeAudit(actorID, "", trashed, restored, restored.UpdatedAt))
+	return restored, nil
+}
+
+// PurgeTrash remove the tasks trashed before the given time from the in-memory repository, recording their events
+func (r *InMemoryTaskRepository) PurgeTrash(before time.Time) (int, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.tasks[id]; !ok {
-		return errors.New("task not found")
+	now := time.Now().UTC()
+	var entries []*domain.AuditEntry
+	for id, task := range r.trash {
+		if task.DeletedAt.Before(before) {
+			delete(r.trash, id)
+			r.record(domain.NewTaskPurgeEvent(task, now))
+			entries = append(entries, domain.NewTaskChangeAudit("", domain.AuditPurge, task, nil, now))
+		}
 	}
-	delete(r.tasks, id)
+	r.recordAudit(entries...)
+	return len(entries), nil
+}
+
+// QueryAudit returns one page of the audit log, newest first.
+func (r *InMemoryTaskRepository) QueryAudit(query domain.AuditQuery) (*domain.AuditPage, error) {
+	return r.audit.QueryAudit(query)
+}
+
+// PendingEvents get the oldest events of the outbox
+func (r *InMemoryTaskRepository) PendingEvents(limit int) ([]domain.TaskEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.outbox[:min(limit, len(r.outbox))]), nil
+}
+
+// AckEvents remove published events from the outbox
+func (r *InMemoryTaskRepository) AckEvents(ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	acked := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := r.pending[id]; ok {
+			acked[id] = struct{}{}
+			delete(r.pending, id)
+		}
+	}
+	if len(acked) == 0 {
+		return nil
+	}
+	r.outbox = slices.DeleteFunc(r.outbox, func(event domain.TaskEvent) bool {
+		_, ok := acked[event.ID]
+		return ok
+	})
 	return nil
 }
+
+// record appends an event to the outbox unless it is already there. Callers
+// hold the write lock.
+func (r *InMemoryTaskRepository) record(event domain.TaskEvent) {
+	if _, ok := r.pending[event.ID]; ok {
+		return
+	}
+	r.pending[event.ID] = struct{}{}
+	r.outbox = append(r.outbox, event)
+	if len(r.outbox)%outboxBacklogWarning == 0 {
+		log.Printf("El outbox tiene %d eventos sin publicar", len(r.outbox))
+	}
+}
+
+// recordAudit appends entries to the audit log. Callers hold the write lock.
+func (r *InMemoryTaskRepository) recordAudit(entries ...*domain.AuditEntry) {
+	if len(entries) > 0 {
+		_ = r.audit.AppendAudit(entries)
+	}
+}
+
+// store replaces the task with the given id, deleting it when task is nil,
+// and keeps the owner and tag indexes in sync. Callers hold the write lock.
+func (r *InMemoryTaskRepository) store(id string, task *domain.Task) {
+	if stored, ok := r.tasks[id]; ok {
+		delete(r.owners[stored.OwnerID], id)
+		if len(r.owners[stored.OwnerID]) == 0 {
+			delete(r.owners, stored.OwnerID)
+		}
+		index := r.tags[stored.OwnerID]
+		for _, tag := range stored.Tags {
+			delete(index[tag], id)
+			if len(index[tag]) == 0 {
+				delete(index, tag)
+			}
+		}
+		if len(index) == 0 {
+			delete(r.tags, stored.OwnerID)
+		}
+	}
+	if task == nil {
+		delete(r.tasks, id)
+		return
+	}
+
+	r.tasks[id] = task
+	owned := r.owners[task.OwnerID]
+	if owned == nil {
+		owned = make(map[string]*domain.Task)
+		r.owners[task.OwnerID] = owned
+	}
+	owned[id] = task
+	if len(task.Tags) == 0 {
+		return
+	}
+	index := r.tags[task.OwnerID]
+	if index == nil {
+		index = make(map[string]map[string]struct{})
+		r.tags[task.OwnerID] = index
+	}
+	for _, tag := range task.Tags {
+		if index[tag] == nil {
+			index[tag] = make(map[string]struct{})
+		}
+		index[tag][id] = struct{}{}
+	}
+}
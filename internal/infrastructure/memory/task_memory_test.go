@@ -0,0 +1,203 @@
+package memory_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryTags(t *testing.T, repo *memory.InMemoryTaskRepository, tags []string, any bool) []string {
+	query := domain.TaskQuery{OwnerID: "user-1", Tags: tags, AnyTag: any}
+	require.NoError(t, query.Normalize())
+	page, err := repo.QueryTasks(query)
+	require.NoError(t, err)
+	ids := []string{}
+	for _, task := range page.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestInMemoryTaskRepository_TagIndex(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	for _, task := range []*domain.Task{
+		{ID: "1", OwnerID: "user-1", Tags: []string{"backend", "urgent"}},
+		{ID: "2", OwnerID: "user-1", Tags: []string{"urgent"}},
+		{ID: "3", OwnerID: "user-1", Tags: []string{"frontend"}},
+		{ID: "4", OwnerID: "user-2", Tags: []string{"urgent"}},
+	} {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"1", "2"}, queryTags(t, repo, []string{"urgent"}, false))
+	assert.Equal(t, []string{"1"}, queryTags(t, repo, []string{"backend", "urgent"}, false))
+	assert.Equal(t, []string{"1", "3"}, queryTags(t, repo, []string{"backend", "frontend"}, true))
+	assert.Empty(t, queryTags(t, repo, []string{"missing"}, true))
+
+	_, err := repo.UpdateTask("user-1", "2", &domain.Task{Tags: []string{"frontend"}})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTask("user-1", "1", "user-1"))
+	_, err = repo.ApplyTaskChanges("user-1", []domain.TaskChange{
+		{Op: domain.BatchCreate, ID: "5", Task: &domain.Task{ID: "5", OwnerID: "user-1", Tags: []string{"urgent"}}},
+	}, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"5"}, queryTags(t, repo, []string{"urgent"}, false))
+	assert.Equal(t, []string{"2", "3"}, queryTags(t, repo, []string{"frontend"}, false))
+
+	catalog, err := repo.GetTagCounts("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TagCount{{Tag: "frontend", Count: 2}, {Tag: "urgent", Count: 1}}, catalog)
+}
+
+func TestInMemoryTaskRepository_OwnerIndex(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	for _, task := range []*domain.Task{
+		{ID: "1", OwnerID: "user-1"},
+		{ID: "2", OwnerID: "user-2"},
+		{ID: "3", OwnerID: "user-1"},
+	} {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteTask("user-1", "1", "user-1"))
+
+	assert.Equal(t, []string{"3"}, queryTags(t, repo, nil, false))
+	tasks, err := repo.GetTasks("user-2")
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "2", tasks[0].ID)
+
+	_, err = repo.RestoreTask("user-1", "1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "3"}, queryTags(t, repo, nil, false))
+}
+
+func TestInMemoryTaskRepository_Outbox(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: "user-1", Version: 1, UpdatedBy: "user-1"})
+	require.NoError(t, err)
+	_, err = repo.UpdateTask("user-1", "1", &domain.Task{Completed: true, UpdatedBy: "user-2"})
+	require.NoError(t, err)
+	_, err = repo.UpdateTask("user-2", "1", &domain.Task{})
+	require.ErrorIs(t, err, domain.ErrTaskNotFound)
+	require.NoError(t, repo.DeleteTask("user-1", "1", "user-2"))
+
+	events, err := repo.PendingEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, []string{domain.TaskCreated, domain.TaskCompleted, domain.TaskDeleted}, []string{events[0].Type, events[1].Type, events[2].Type})
+	assert.Equal(t, []string{"1@1", "1@2", "1@3"}, []string{events[0].ID, events[1].ID, events[2].ID})
+	assert.Equal(t, "user-2", events[2].ActorID)
+	assert.Nil(t, events[2].Task)
+
+	require.NoError(t, repo.AckEvents([]string{"1@1", "1@2"}))
+	events, err = repo.PendingEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "1@3", events[0].ID)
+
+	require.NoError(t, repo.AckEvents([]string{"1@1", "missing"}))
+	events, err = repo.PendingEvents(10)
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "acking unknown or published events changes nothing")
+}
+
+func TestInMemoryTaskRepository_Trash(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: "user-1", Tags: []string{"home"}, Version: 1, UpdatedBy: "user-1"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTask("user-1", "1", "user-2"))
+
+	_, err = repo.GetTask("user-1", "1")
+	require.ErrorIs(t, err, domain.ErrTaskNotFound)
+	assert.Empty(t, queryTags(t, repo, []string{"home"}, false), "trashed tasks are left out of queries")
+	trash, err := repo.GetTrash("user-1")
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, int64(2), trash[0].Version)
+	assert.Equal(t, "user-2", trash[0].UpdatedBy)
+	require.NotNil(t, trash[0].DeletedAt)
+	other, err := repo.GetTrash("user-2")
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	_, err = repo.RestoreTask("user-2", "1", "user-2")
+	require.ErrorIs(t, err, domain.ErrTaskNotFound)
+	restored, err := repo.RestoreTask("user-1", "1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), restored.Version)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, []string{"1"}, queryTags(t, repo, []string{"home"}, false))
+
+	events, err := repo.PendingEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, domain.TaskRestored, events[2].Type)
+	assert.Equal(t, "1@3", events[2].ID)
+
+	_, err = repo.ApplyTaskChanges("user-1", []domain.TaskChange{{Op: domain.BatchDelete, ID: "1", At: time.Now().UTC(), ActorID: "user-1"}}, true)
+	require.NoError(t, err)
+	purged, err := repo.PurgeTrash(time.Now().UTC().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged, "recently trashed tasks are kept")
+	purged, err = repo.PurgeTrash(time.Now().UTC().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	trash, err = repo.GetTrash("user-1")
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	page, err := repo.QueryAudit(domain.AuditQuery{TaskID: "1", Limit: 10})
+	require.NoError(t, err)
+	actions := []string{}
+	for _, entry := range page.Entries {
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, []string{domain.AuditPurge, domain.AuditDelete, domain.AuditRestore, domain.AuditDelete, domain.AuditCreate}, actions)
+	assert.Empty(t, page.Entries[0].ActorID, "purges have no actor")
+	assert.Equal(t, "user-2", page.Entries[3].ActorID)
+
+	events, err = repo.PendingEvents(10)
+	require.NoError(t, err)
+	purge := events[len(events)-1]
+	assert.Equal(t, domain.TaskPurged, purge.Type)
+	assert.Equal(t, page.Entries[0].ID, purge.ID)
+	assert.Equal(t, "user-1", purge.OwnerID)
+}
+
+func TestInMemoryTaskRepository_BatchCompletesRecurringTask(t *testing.T) {
+	repo := memory.NewInMemoryTaskRepository()
+	due := time.Date(2026, 3, 27, 8, 0, 0, 0, time.UTC)
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: "user-1", Title: "Water plants", Version: 1, TaskSchedule: domain.TaskSchedule{DueAt: &due, Recurrence: "FREQ=WEEKLY;COUNT=2"}})
+	require.NoError(t, err)
+
+	results, err := repo.ApplyTaskChanges("user-1", []domain.TaskChange{
+		{Op: domain.BatchComplete, ID: "1", Version: 1, At: due, ActorID: "user-1", OccurrenceID: "2"},
+	}, true)
+	require.NoError(t, err)
+	require.NotNil(t, results[0].Occurrence)
+
+	occurrence, err := repo.GetTask("user-1", "2")
+	require.NoError(t, err)
+	assert.False(t, occurrence.Completed)
+	assert.Equal(t, due.AddDate(0, 0, 7), *occurrence.DueAt)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=1", occurrence.Recurrence)
+
+	// The last occurrence of the series creates no other.
+	results, err = repo.ApplyTaskChanges("user-1", []domain.TaskChange{
+		{Op: domain.BatchComplete, ID: "2", Version: 1, At: due, ActorID: "user-1", OccurrenceID: "3"},
+	}, true)
+	require.NoError(t, err)
+	assert.Nil(t, results[0].Occurrence)
+
+	events, err := repo.PendingEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, []string{domain.TaskCreated, domain.TaskCompleted, domain.TaskCreated, domain.TaskCompleted}, []string{events[0].Type, events[1].Type, events[2].Type, events[3].Type})
+}
@@ -1,29 +1,39 @@
 package memory
 
 import (
-	"fmt"
 	"sync"
 	"todo-list-task/internal/domain"
 	"todo-list-task/internal/utils"
 )
 
+// InMemoryUserRepository is a UserRepository kept in memory, with a unique
+// index of usernames.
 type InMemoryUserRepository struct {
-	users     map[string]*domain.User
-	mu        sync.RWMutex
-	appCrypto *utils.DefaultAppCrypto
+	users      map[string]*domain.User
+	byUsername map[string]string
+	mu         sync.RWMutex
+	appCrypto  *utils.DefaultAppCrypto
+	jwt        *utils.JWTManager
 }
 
-func NewInMemoryUserRepository(appCrypto *utils.DefaultAppCrypto) *InMemoryUserRepository {
+func NewInMemoryUserRepository(appCrypto *utils.DefaultAppCrypto, jwt *utils.JWTManager) *InMemoryUserRepository {
 	return &InMemoryUserRepository{
-		users:     make(map[string]*domain.User),
-		appCrypto: appCrypto,
+		users:      make(map[string]*domain.User),
+		byUsername: make(map[string]string),
+		appCrypto:  appCrypto,
+		jwt:        jwt,
 	}
 }
 
+// Create stores a user with a hashed password, rejecting taken usernames.
 func (r *InMemoryUserRepository) Create(user *domain.User) (string, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if _, ok := r.byUsername[user.Username]; ok {
+		return "", domain.ErrUsernameTaken
+	}
+
 	password, err := r.appCrypto.HashPassword(user.Password)
 	if err != nil {
 		return "", err
@@ -31,7 +41,8 @@ func (r *InMemoryUserRepository) Create(user *domain.User) (string, error) {
 
 	user.Password = password
 	r.users[user.ID] = user
-	token, err := utils.GenerateJWT()
+	r.byUsername[user.Username] = user.ID
+	token, err := r.jwt.GenerateJWT(user.ID, user.Username, user.Role)
 
 	if err != nil {
 		return "", err
@@ -40,20 +51,87 @@ func (r *InMemoryUserRepository) Create(user *domain.User) (string, error) {
 }
 
 func (r *InMemoryUserRepository) Login(user domain.User) (string, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
+	u, ok := r.users[r.byUsername[user.Username]]
+	if !ok || !r.appCrypto.CheckPasswordHash(user.Password, u.Password) {
+		return "", domain.ErrInvalidCredentials
+	}
+	if u.Disabled {
+		return "", domain.ErrUserDisabled
+	}
+
+	token, err := r.jwt.GenerateJWT(u.ID, u.Username, u.Role)
+
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Get returns a copy of the user with the given ID.
+func (r *InMemoryUserRepository) Get(id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+// GetByUsername returns a copy of the user with the given username.
+func (r *InMemoryUserRepository) GetByUsername(username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[r.byUsername[username]]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+// List returns every registered user.
+func (r *InMemoryUserRepository) List() ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(r.users))
 	for _, u := range r.users {
-		if u.Username == user.Username && r.appCrypto.CheckPasswordHash(user.Password, u.Password) {
-			token, err := utils.GenerateJWT()
+		copied := *u
+		users = append(users, &copied)
+	}
+	return users, nil
+}
+
+// SetDisabled enables or disables a user account.
+func (r *InMemoryUserRepository) SetDisabled(id string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-			if err != nil {
-				return "", err
-			}
+	u, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	u.Disabled = disabled
+	return nil
+}
 
-			return token, nil
+// SetRole changes the role of a user account.
+func (r *InMemoryUserRepository) SetRole(id, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-		}
+	u, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
 	}
-	return "", fmt.Errorf("username or password incorrect")
+	u.Role = role
+	return nil
 }
@@ -0,0 +1,51 @@
+package memory_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/memory"
+	"todo-list-task/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestInMemoryUserRepository_RejectsDuplicateUsername(t *testing.T) {
+	repo := memory.NewInMemoryUserRepository(utils.NewHashPassword(app.BcryptCrypto{}, bcrypt.MinCost), utils.NewJWTManager("test-secret", time.Hour))
+
+	_, err := repo.Create(&domain.User{ID: "user-1", Username: "root", Password: "password", Role: domain.RoleAdmin})
+	require.NoError(t, err)
+
+	_, err = repo.Create(&domain.User{ID: "user-2", Username: "root", Password: "attacker", Role: domain.RoleUser})
+	assert.ErrorIs(t, err, domain.ErrUsernameTaken)
+
+	users, err := repo.List()
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	_, err = repo.Login(domain.User{Username: "root", Password: "attacker"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	user, err := repo.GetByUsername("root")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+	_, err = repo.GetByUsername("unknown")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestInMemoryUserRepository_SetRole(t *testing.T) {
+	repo := memory.NewInMemoryUserRepository(utils.NewHashPassword(app.BcryptCrypto{}, bcrypt.MinCost), utils.NewJWTManager("test-secret", time.Hour))
+	_, err := repo.Create(&domain.User{ID: "user-1", Username: "cristianm", Password: "password", Role: domain.RoleUser})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SetRole("user-1", domain.RoleAdmin))
+	user, err := repo.Get("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, user.Role)
+
+	assert.ErrorIs(t, repo.SetRole("user-2", domain.RoleAdmin), domain.ErrUserNotFound)
+	_, err = repo.Get("user-2")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"todo-list-task/internal/domain"
+)
+
+// maxSucceededDeliveries is how many succeeded deliveries of a webhook are
+// kept in its delivery log. Pending and dead deliveries are always kept.
+const maxSucceededDeliveries = 100
+
+type InMemoryWebhookRepository struct {
+	webhooks   map[string]*domain.Webhook
+	deliveries map[string]*domain.WebhookDelivery
+	// log holds the delivery IDs of each webhook, oldest first.
+	log map[string][]string
+	mu  sync.RWMutex
+}
+
+func NewInMemoryWebhookRepository() *InMemoryWebhookRepository {
+	return &InMemoryWebhookRepository{
+		webhooks:   make(map[string]*domain.Webhook),
+		deliveries: make(map[string]*domain.WebhookDelivery),
+		log:        make(map[string][]string),
+	}
+}
+
+func (r *InMemoryWebhookRepository) CreateWebhook(webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *webhook
+	stored.Events = slices.Clone(webhook.Events)
+	r.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) GetWebhook(ownerID, id string) (*domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok || webhook.OwnerID != ownerID {
+		return nil, domain.ErrWebhookNotFound
+	}
+	copied := *webhook
+	return &copied, nil
+}
+
+// GetWebhooks returns the owner's webhooks, oldest first.
+func (r *InMemoryWebhookRepository) GetWebhooks(ownerID string) ([]*domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := []*domain.Webhook{}
+	for _, webhook := range r.webhooks {
+		if webhook.OwnerID == ownerID {
+			copied := *webhook
+			webhooks = append(webhooks, &copied)
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b *domain.Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return webhooks, nil
+}
+
+func (r *InMemoryWebhookRepository) DeleteWebhook(ownerID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok || webhook.OwnerID != ownerID {
+		return domain.ErrWebhookNotFound
+	}
+	for _, deliveryID := range r.log[id] {
+		delete(r.deliveries, deliveryID)
+	}
+	delete(r.log, id)
+	delete(r.webhooks, id)
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) CreateDelivery(delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[delivery.WebhookID]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	for _, id := range r.log[delivery.WebhookID] {
+		if r.deliveries[id].EventID == delivery.EventID {
+			return domain.ErrDeliveryExists
+		}
+	}
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	r.log[delivery.WebhookID] = append(r.log[delivery.WebhookID], delivery.ID)
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		return nil
+	}
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	if delivery.Status == domain.DeliverySucceeded {
+		r.prune(delivery.WebhookID)
+	}
+	return nil
+}
+
+// prune drops the oldest succeeded deliveries of a webhook beyond
+// maxSucceededDeliveries.
+func (r *InMemoryWebhookRepository) prune(webhookID string) {
+	succeeded := 0
+	for _, id := range r.log[webhookID] {
+		if r.deliveries[id].Status == domain.DeliverySucceeded {
+			succeeded++
+		}
+	}
+	if succeeded <= maxSucceededDeliveries {
+		return
+	}
+	r.log[webhookID] = slices.DeleteFunc(r.log[webhookID], func(id string) bool {
+		if succeeded > maxSucceededDeliveries && r.deliveries[id].Status == domain.DeliverySucceeded {
+			succeeded--
+			delete(r.deliveries, id)
+			return true
+		}
+		return false
+	})
+}
+
+func (r *InMemoryWebhookRepository) GetDeliveries(ownerID, webhookID string) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[webhookID]
+	if !ok || webhook.OwnerID != ownerID {
+		return nil, domain.ErrWebhookNotFound
+	}
+	ids := r.log[webhookID]
+	deliveries := make([]*domain.WebhookDelivery, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		copied := *r.deliveries[ids[i]]
+		deliveries = append(deliveries, &copied)
+	}
+	return deliveries, nil
+}
+
+func (r *InMemoryWebhookRepository) GetDeadDeliveries(ownerID string) ([]*domain.WebhookDelivery, error) {
+	return r.findDeliveries(func(delivery *domain.WebhookDelivery) bool {
+		return delivery.OwnerID == ownerID && delivery.Status == domain.DeliveryDead
+	}, true)
+}
+
+func (r *InMemoryWebhookRepository) GetDueDeliveries(now time.Time) ([]*domain.WebhookDelivery, error) {
+	return r.findDeliveries(func(delivery *domain.WebhookDelivery) bool {
+		return delivery.Status == domain.DeliveryPending && (delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.After(now))
+	}, false)
+}
+
+// findDeliveries returns copies of the deliveries matching keep, sorted by
+// creation time.
+func (r *InMemoryWebhookRepository) findDeliveries(keep func(*domain.WebhookDelivery) bool, newestFirst bool) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if keep(delivery) {
+			copied := *delivery
+			deliveries = append(deliveries, &copied)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b *domain.WebhookDelivery) int {
+		c := a.CreatedAt.Compare(b.CreatedAt)
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if newestFirst {
+			return -c
+		}
+		return c
+	})
+	return deliveries, nil
+}
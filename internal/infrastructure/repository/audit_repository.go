@@ -0,0 +1,12 @@
+package repository
+
+import "todo-list-task/internal/domain"
+
+// AuditRepository reads the append-only log of the changes made to tasks.
+// Entries are appended by the writes of the task repository, atomically with
+// the changes they describe, and are never updated or removed.
+type AuditRepository interface {
+	// QueryAudit returns one page of the entries matching a normalized
+	// query, newest first.
+	QueryAudit(query domain.AuditQuery) (*domain.AuditPage, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import "todo-list-task/internal/domain"
+
+// ListRepository defines the interface for list persistence operations.
+// Lists are read by ID regardless of the user; the service decides who may
+// see them from their owner and members.
+type ListRepository interface {
+	CreateList(list *domain.List) (*domain.List, error)
+	GetList(id string) (*domain.List, error)
+	// GetListsForUser returns the lists the user owns or is a member of.
+	GetListsForUser(userID string) ([]*domain.List, error)
+	// UpdateList replaces the name and modification time of a list,
+	// keeping its members.
+	UpdateList(list *domain.List) (*domain.List, error)
+	DeleteList(id string) error
+	// SetMember adds a member to a list or changes the role of an existing one.
+	SetMember(listID string, member domain.ListMember) error
+	// RemoveMember removes a member from a list, returning
+	// domain.ErrListMemberNotFound when the user is not a member.
+	RemoveMember(listID, userID string) error
+}
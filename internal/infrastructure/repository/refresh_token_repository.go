@@ -0,0 +1,14 @@
+package repository
+
+import "todo-list-task/internal/domain"
+
+// RefreshTokenRepository defines the interface for refresh token persistence operations.
+type RefreshTokenRepository interface {
+	Create(token *domain.RefreshToken) error
+	GetByHash(hash string) (*domain.RefreshToken, error)
+	// MarkRotated atomically flags a token as used, returning
+	// domain.ErrRefreshTokenReused if it had already been rotated.
+	MarkRotated(id string) error
+	RevokeFamily(familyID string) error
+	RevokeUser(userID string) error
+}
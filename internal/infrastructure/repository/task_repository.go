@@ -1,12 +1,75 @@
 package repository
 
-import "todo-list-task/internal/domain"
+import (
+	"time"
+	"todo-list-task/internal/domain"
+)
 
 // TaskRepository defines the interface for task persistence operations.
+// Every read and write is scoped to the owner of the task, and every write
+// records the events of the changes it stores in the outbox of the
+// repository, and their entries in the audit log, atomically with the
+// change. Deleted tasks are kept in the trash of the repository and left out
+// of every read but those of the trash.
 type TaskRepository interface {
+	TaskOutbox
+	TaskTrash
+
 	CreateTask(task *domain.Task) (*domain.Task, error)
-	GetTask(id string) (*domain.Task, error)
-	GetTasks() ([]*domain.Task, error)
-	UpdateTask(id string, task *domain.Task) (*domain.Task, error)
-	DeleteTask(id string) error
+	GetTask(ownerID, id string) (*domain.Task, error)
+	GetTasks(ownerID string) ([]*domain.Task, error)
+	// QueryTasks returns one page of the owner's tasks matching the
+	// filters, sort order and cursor of a normalized query.
+	QueryTasks(query domain.TaskQuery) (*domain.TaskPage, error)
+	// ScanTasks calls fn with every task of the owner, oldest first, and
+	// stops at the first error fn returns. fn is not called while the
+	// repository is locked, so it may be slow.
+	ScanTasks(ownerID string, fn func(*domain.Task) error) error
+	// GetTagCounts returns the tag catalog of the owner, sorted with
+	// domain.SortTagCounts.
+	GetTagCounts(ownerID string) ([]domain.TagCount, error)
+	// UpdateTask replaces the task and increments its version. When
+	// task.Version is not zero the update only applies if it matches the
+	// stored version; otherwise domain.ErrVersionConflict is returned.
+	UpdateTask(ownerID, id string, task *domain.Task) (*domain.Task, error)
+	// DeleteTask moves the task to the trash on behalf of actorID, the user
+	// recorded in the event of the deletion.
+	DeleteTask(ownerID, id, actorID string) error
+	// ApplyTaskChanges applies a batch of changes to the owner's tasks in
+	// order, with the semantics of domain.TaskChange.Apply. In atomic mode
+	// nothing is stored unless every change succeeds and the failure is a
+	// *domain.BatchError; otherwise each change succeeds or fails on its own.
+	// Deleted tasks are moved to the trash, and every change is audited as
+	// returned by domain.TaskChange.Audit.
+	ApplyTaskChanges(ownerID string, changes []domain.TaskChange, atomic bool) ([]domain.TaskChangeResult, error)
+	// GetTasksDueBetween returns the open tasks of every owner with
+	// reminders and a due date in (from, to]. It backs the reminder scheduler.
+	GetTasksDueBetween(from, to time.Time) ([]*domain.Task, error)
+}
+
+// TaskOutbox holds the task events recorded by the writes of a task
+// repository until they are acknowledged, so they survive a crash of the
+// process before being published. Events are kept in the order of the
+// writes, and an event ID is recorded once.
+type TaskOutbox interface {
+	// PendingEvents returns up to limit recorded events, oldest first.
+	PendingEvents(limit int) ([]domain.TaskEvent, error)
+	// AckEvents removes the events with the given IDs once published.
+	// Unknown IDs are ignored.
+	AckEvents(ids []string) error
+}
+
+// TaskTrash holds the deleted tasks of a task repository, as returned by
+// domain.TrashTask, until they are restored or purged.
+type TaskTrash interface {
+	// GetTrash returns the owner's trashed tasks, sorted with domain.SortTrash.
+	GetTrash(ownerID string) ([]*domain.Task, error)
+	// RestoreTask moves a trashed task back on behalf of actorID, as
+	// returned by domain.RestoreTask, and records its event. Tasks missing
+	// from the owner's trash are reported as domain.ErrTaskNotFound.
+	RestoreTask(ownerID, id, actorID string) (*domain.Task, error)
+	// PurgeTrash permanently removes the trashed tasks of every owner
+	// deleted before the given time, recording the event of each removal
+	// and auditing it as domain.AuditPurge, and returns how many it removed.
+	PurgeTrash(before time.Time) (int, error)
 }
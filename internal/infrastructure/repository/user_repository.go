@@ -6,4 +6,12 @@ import "todo-list-task/internal/domain"
 type UserRepository interface {
 	Create(user *domain.User) (string, error)
 	Login(user domain.User) (string, error)
+	// Get returns the user with the given ID or domain.ErrUserNotFound.
+	Get(id string) (*domain.User, error)
+	// GetByUsername returns the user with the given username, looked up
+	// through the unique username index, or domain.ErrUserNotFound.
+	GetByUsername(username string) (*domain.User, error)
+	List() ([]*domain.User, error)
+	SetDisabled(id string, disabled bool) error
+	SetRole(id, role string) error
 }
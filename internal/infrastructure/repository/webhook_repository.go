@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"time"
+	"todo-list-task/internal/domain"
+)
+
+// WebhookRepository defines the interface for webhook and delivery
+// persistence operations. Webhooks and their deliveries are scoped to their
+// owner, except for the due deliveries read by the dispatcher.
+type WebhookRepository interface {
+	CreateWebhook(webhook *domain.Webhook) error
+	GetWebhook(ownerID, id string) (*domain.Webhook, error)
+	GetWebhooks(ownerID string) ([]*domain.Webhook, error)
+	// DeleteWebhook deletes a webhook with its deliveries.
+	DeleteWebhook(ownerID, id string) error
+	// CreateDelivery queues a delivery, or returns domain.ErrDeliveryExists
+	// when a delivery of the same event to the webhook is still in its log.
+	CreateDelivery(delivery *domain.WebhookDelivery) error
+	// UpdateDelivery replaces a delivery after an attempt. Deliveries of
+	// deleted webhooks are ignored.
+	UpdateDelivery(delivery *domain.WebhookDelivery) error
+	// GetDeliveries returns the deliveries of a webhook, newest first.
+	GetDeliveries(ownerID, webhookID string) ([]*domain.WebhookDelivery, error)
+	// GetDeadDeliveries returns the owner's deliveries that ran out of
+	// attempts, newest first.
+	GetDeadDeliveries(ownerID string) ([]*domain.WebhookDelivery, error)
+	// GetDueDeliveries returns the pending deliveries of every owner whose
+	// next attempt is at or before now, oldest first.
+	GetDueDeliveries(now time.Time) ([]*domain.WebhookDelivery, error)
+}
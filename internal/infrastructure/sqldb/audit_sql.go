@@ -0,0 +1,138 @@
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"todo-list-task/internal/domain"
+)
+
+// SQLAuditRepository is an AuditRepository backed by a database/sql
+// connection. The columns filtered on are stored next to the encoded entry.
+// Entries are inserted by SQLTaskRepository in the transaction of the change
+// they describe.
+type SQLAuditRepository struct {
+	db *sql.DB
+}
+
+func NewSQLAuditRepository(db *sql.DB) *SQLAuditRepository {
+	return &SQLAuditRepository{db: db}
+}
+
+// AppendAudit inserts entries in one transaction, numbering them after the
+// latest stored entry.
+func (r *SQLAuditRepository) AppendAudit(entries []*domain.AuditEntry) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := appendAudit(tx, entries...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// appendAudit inserts entries in tx, numbering them after the latest stored
+// entry. Entries already stored are skipped.
+func appendAudit(tx *sql.Tx, entries ...*domain.AuditEntry) error {
+	for _, entry := range entries {
+		var recorded int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM task_audit WHERE id = ?`, entry.ID).Scan(&recorded); err != nil {
+			return err
+		}
+		if recorded > 0 {
+			continue
+		}
+		var seq int64
+		if err := tx.QueryRow(`SELECT COALESCE(MAX(seq), 0) + 1 FROM task_audit`).Scan(&seq); err != nil {
+			return err
+		}
+		stored := *entry
+		stored.Seq = seq
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO task_audit (seq, id, task_id, owner_id, actor_id, action, revision, at, entry) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			stored.Seq, stored.ID, stored.TaskID, stored.OwnerID, stored.ActorID, stored.Action, stored.Revision, domain.UnixNanos(stored.At), string(data),
+		); err != nil {
+			return err
+		}
+		entry.Seq = seq
+	}
+	return nil
+}
+
+// QueryAudit returns one page of the entries matching query, newest first.
+func (r *SQLAuditRepository) QueryAudit(query domain.AuditQuery) (*domain.AuditPage, error) {
+	before, err := query.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{"1 = 1"}
+	var args []any
+	for _, filter := range []struct{ column, value string }{
+		{"task_id", query.TaskID},
+		{"owner_id", query.OwnerID},
+		{"actor_id", query.ActorID},
+		{"action", query.Action},
+	} {
+		if filter.value != "" {
+			where = append(where, filter.column+" = ?")
+			args = append(args, filter.value)
+		}
+	}
+	if query.Revision != 0 {
+		where = append(where, "revision = ?")
+		args = append(args, query.Revision)
+	}
+	if query.Since != nil {
+		where = append(where, "at >= ?")
+		args = append(args, domain.UnixNanos(*query.Since))
+	}
+	if query.Until != nil {
+		where = append(where, "at < ?")
+		args = append(args, domain.UnixNanos(*query.Until))
+	}
+	if before != 0 {
+		where = append(where, "seq < ?")
+		args = append(args, before)
+	}
+	args = append(args, query.Limit+1)
+
+	rows, err := r.db.Query(`SELECT entry FROM task_audit WHERE `+strings.Join(where, " AND ")+` ORDER BY seq DESC LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.AuditPage{Entries: []*domain.AuditEntry{}}
+	for rows.Next() {
+		var (
+			data  string
+			entry domain.AuditEntry
+		)
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit entry: %w", err)
+		}
+		page.Entries = append(page.Entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Entries) > query.Limit {
+		page.Entries = page.Entries[:query.Limit]
+		page.NextCursor = strconv.FormatInt(page.Entries[query.Limit-1].Seq, 10)
+	}
+	return page, nil
+}
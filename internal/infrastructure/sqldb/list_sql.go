@@ -0,0 +1,236 @@
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"todo-list-task/internal/domain"
+)
+
+const listColumns = `id, owner_id, name, created_at, updated_at`
+
+// SQLListRepository is a ListRepository backed by a database/sql connection.
+// Members live in their own table, keyed by list and user.
+type SQLListRepository struct {
+	db *sql.DB
+}
+
+func NewSQLListRepository(db *sql.DB) *SQLListRepository {
+	return &SQLListRepository{db: db}
+}
+
+func scanList(row rowScanner) (*domain.List, error) {
+	var (
+		list                 domain.List
+		createdAt, updatedAt int64
+	)
+	if err := row.Scan(&list.ID, &list.OwnerID, &list.Name, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	list.CreatedAt = domain.FromUnixNanos(createdAt)
+	list.UpdatedAt = domain.FromUnixNanos(updatedAt)
+	list.Members = []domain.ListMember{}
+	return &list, nil
+}
+
+// CreateList inserts a new list with its members.
+func (r *SQLListRepository) CreateList(list *domain.List) (*domain.List, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(
+		`INSERT INTO lists (`+listColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		list.ID, list.OwnerID, list.Name, domain.UnixNanos(list.CreatedAt), domain.UnixNanos(list.UpdatedAt),
+	); err != nil {
+		return nil, err
+	}
+	for _, member := range list.Members {
+		if err := insertMember(tx, list.ID, member); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetList get a list by id with its members.
+func (r *SQLListRepository) GetList(id string) (*domain.List, error) {
+	list, err := scanList(r.db.QueryRow(`SELECT `+listColumns+` FROM lists WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadMembers([]*domain.List{list}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetListsForUser get the lists owned by or shared with userID.
+func (r *SQLListRepository) GetListsForUser(userID string) ([]*domain.List, error) {
+	rows, err := r.db.Query(
+		`SELECT `+listColumns+` FROM lists
+		WHERE owner_id = ? OR id IN (SELECT list_id FROM list_members WHERE user_id = ?)`,
+		userID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []*domain.List
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadMembers(lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// loadMembers fills in the members of lists.
+func (r *SQLListRepository) loadMembers(lists []*domain.List) error {
+	for _, list := range lists {
+		rows, err := r.db.Query(`SELECT user_id, username, role FROM list_members WHERE list_id = ? ORDER BY username`, list.ID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var member domain.ListMember
+			if err := rows.Scan(&member.UserID, &member.Username, &member.Role); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			list.Members = append(list.Members, member)
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateList update the name of a list.
+func (r *SQLListRepository) UpdateList(list *domain.List) (*domain.List, error) {
+	result, err := r.db.Exec(
+		`UPDATE lists SET name = ?, updated_at = ? WHERE id = ?`,
+		list.Name, domain.UnixNanos(list.UpdatedAt), list.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireList(result); err != nil {
+		return nil, err
+	}
+	return r.GetList(list.ID)
+}
+
+// DeleteList delete a list and its members.
+func (r *SQLListRepository) DeleteList(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM list_members WHERE list_id = ?`, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM lists WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err := requireList(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetMember update the role of a member, or insert the member when the
+// user is not one yet.
+func (r *SQLListRepository) SetMember(listID string, member domain.ListMember) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM lists WHERE id = ?`, listID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrListNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(
+		`UPDATE list_members SET username = ?, role = ? WHERE list_id = ? AND user_id = ?`,
+		member.Username, member.Role, listID, member.UserID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if err := insertMember(tx, listID, member); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RemoveMember delete a member of a list.
+func (r *SQLListRepository) RemoveMember(listID, userID string) error {
+	if _, err := r.GetList(listID); err != nil {
+		return err
+	}
+	result, err := r.db.Exec(`DELETE FROM list_members WHERE list_id = ? AND user_id = ?`, listID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrListMemberNotFound
+	}
+	return nil
+}
+
+func insertMember(tx *sql.Tx, listID string, member domain.ListMember) error {
+	_, err := tx.Exec(
+		`INSERT INTO list_members (list_id, user_id, username, role) VALUES (?, ?, ?, ?)`,
+		listID, member.UserID, member.Username, member.Role,
+	)
+	return err
+}
+
+func requireList(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrListNotFound
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package sqldb
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"todo-list-task/internal/domain"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migration is a versioned schema change named <version>_<description>.sql.
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// backfills fill, after the SQL of the migration with the same version and
+// in its transaction, the columns whose values can only be computed in Go.
+var backfills = map[int]func(tx *sql.Tx) error{
+	15: foldTaskText,
+}
+
+// Open connects to the database and brings its schema up to date.
+func Open(driver, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := Migrate(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate applies, in version order, every embedded migration that has not
+// been recorded in schema_migrations yet. Each migration runs in its own
+// transaction together with its bookkeeping row.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return err
+	}
+
+	pending, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return err
+	}
+	if backfill, ok := backfills[m.version]; ok {
+		if err := backfill(tx); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, m.version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no version prefix", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", name, err)
+		}
+		content, err := fs.ReadFile(migrations, "migrations/"+name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, migration{version: version, name: name, sql: string(content)})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].version < result[j].version })
+	return result, nil
+}
+
+// foldTaskText fills title_fold and description_fold of the existing tasks.
+func foldTaskText(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, title, description FROM tasks`)
+	if err != nil {
+		return err
+	}
+	type folded struct{ id, title, description string }
+	var tasks []folded
+	for rows.Next() {
+		var task folded
+		if err := rows.Scan(&task.id, &task.title, &task.description); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if _, err := tx.Exec(
+			`UPDATE tasks SET title_fold = ?, description_fold = ? WHERE id = ?`,
+			domain.FoldText(task.title), domain.FoldText(task.description), task.id,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
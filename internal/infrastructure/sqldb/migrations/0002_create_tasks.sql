@@ -0,0 +1,9 @@
+CREATE TABLE tasks (
+    id          TEXT PRIMARY KEY,
+    owner_id    TEXT NOT NULL REFERENCES users (id),
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL,
+    completed   BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX tasks_owner_id_idx ON tasks (owner_id);
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN updated_at BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX tasks_owner_created_idx ON tasks (owner_id, created_at, id);
+CREATE INDEX tasks_owner_updated_idx ON tasks (owner_id, updated_at, id);
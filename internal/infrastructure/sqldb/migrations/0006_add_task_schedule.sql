@@ -0,0 +1,7 @@
+ALTER TABLE tasks ADD COLUMN due_at BIGINT;
+ALTER TABLE tasks ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN reminder_minutes TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX tasks_owner_due_idx ON tasks (owner_id, due_at);
+CREATE INDEX tasks_due_idx ON tasks (due_at);
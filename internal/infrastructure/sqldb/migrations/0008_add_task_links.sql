@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN blocked_by TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX tasks_owner_parent_idx ON tasks (owner_id, parent_id);
@@ -0,0 +1,23 @@
+CREATE TABLE lists (
+    id         TEXT PRIMARY KEY,
+    owner_id   TEXT NOT NULL REFERENCES users (id),
+    name       TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX lists_owner_id_idx ON lists (owner_id);
+
+CREATE TABLE list_members (
+    list_id  TEXT NOT NULL REFERENCES lists (id),
+    user_id  TEXT NOT NULL REFERENCES users (id),
+    username TEXT NOT NULL,
+    role     TEXT NOT NULL,
+    PRIMARY KEY (list_id, user_id)
+);
+
+CREATE INDEX list_members_user_id_idx ON list_members (user_id);
+
+ALTER TABLE tasks ADD COLUMN list_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX tasks_owner_list_idx ON tasks (owner_id, list_id);
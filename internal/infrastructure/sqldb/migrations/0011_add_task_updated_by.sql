@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN updated_by TEXT NOT NULL DEFAULT '';
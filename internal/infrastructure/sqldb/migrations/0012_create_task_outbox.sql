@@ -0,0 +1,5 @@
+CREATE TABLE task_outbox (
+    seq   BIGINT PRIMARY KEY,
+    id    TEXT NOT NULL UNIQUE,
+    event TEXT NOT NULL
+);
@@ -0,0 +1,15 @@
+CREATE TABLE task_audit (
+    seq      BIGINT PRIMARY KEY,
+    id       TEXT NOT NULL UNIQUE,
+    task_id  TEXT NOT NULL,
+    owner_id TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    action   TEXT NOT NULL,
+    revision BIGINT NOT NULL,
+    at       BIGINT NOT NULL,
+    entry    TEXT NOT NULL
+);
+
+CREATE INDEX task_audit_task_id_idx ON task_audit (task_id, seq);
+CREATE INDEX task_audit_owner_id_idx ON task_audit (owner_id, seq);
+CREATE INDEX task_audit_actor_id_idx ON task_audit (actor_id, seq);
@@ -0,0 +1,9 @@
+CREATE TABLE task_trash (
+    id         TEXT PRIMARY KEY,
+    owner_id   TEXT NOT NULL,
+    deleted_at BIGINT NOT NULL,
+    task       TEXT NOT NULL
+);
+
+CREATE INDEX task_trash_owner_id_idx ON task_trash (owner_id, deleted_at);
+CREATE INDEX task_trash_deleted_at_idx ON task_trash (deleted_at);
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN title_fold TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN description_fold TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,659 @@
+package sqldb_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+	"todo-list-task/internal/app"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/sqldb"
+	"todo-list-task/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	_ "modernc.org/sqlite"
+)
+
+const ownerID = "user-1"
+
+var jwtManager = utils.NewJWTManager("test-secret", time.Hour)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sqldb.Open("sqlite", filepath.Join(t.TempDir(), "todo.db")+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestMigrate_IsIdempotent(t *testing.T) {
+	db := openDB(t)
+
+	require.NoError(t, sqldb.Migrate(db))
+
+	var versions int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&versions))
+	assert.Equal(t, 15, versions)
+}
+
+func TestSQLTaskRepository_CRUD(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: ownerID, Title: "title", Description: "description"})
+	require.NoError(t, err)
+
+	task, err := repo.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "title", task.Title)
+
+	_, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "updated", Description: "updated", Completed: true})
+	require.NoError(t, err)
+
+	tasks, err := repo.GetTasks(ownerID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "updated", tasks[0].Title)
+	assert.True(t, tasks[0].Completed)
+
+	require.NoError(t, repo.DeleteTask(ownerID, "1", ownerID))
+	_, err = repo.GetTask(ownerID, "1")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+}
+
+func TestSQLTaskRepository_ScopesToOwner(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: ownerID, Title: "title", Description: "description"})
+	require.NoError(t, err)
+
+	_, err = repo.GetTask("user-2", "1")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	_, err = repo.UpdateTask("user-2", "1", &domain.Task{Title: "stolen"})
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.DeleteTask("user-2", "1", "user-2"), domain.ErrTaskNotFound)
+
+	tasks, err := repo.GetTasks("user-2")
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestSQLTaskRepository_UpdateChecksVersion(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: ownerID, Title: "title", Version: 1})
+	require.NoError(t, err)
+
+	updated, err := repo.UpdateTask(ownerID, "1", &domain.Task{Title: "first", Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	_, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "stale", Version: 1})
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	_, err = repo.UpdateTask("user-2", "1", &domain.Task{Title: "stolen", Version: 2})
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	updated, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "forced"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), updated.Version)
+	assert.Equal(t, "forced", updated.Title)
+}
+
+func TestSQLTaskRepository_ApplyTaskChanges(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []string{"1", "2"} {
+		_, err := repo.CreateTask(&domain.Task{ID: id, OwnerID: ownerID, Title: "title", Description: "description", Version: 1})
+		require.NoError(t, err)
+	}
+	_, err := repo.CreateTask(&domain.Task{ID: "other", OwnerID: "user-2", Title: "title", Version: 1})
+	require.NoError(t, err)
+
+	_, err = repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchCreate, ID: "3", Task: &domain.Task{ID: "3", OwnerID: ownerID, Title: "new", Version: 1}},
+		{Op: domain.BatchDelete, ID: "other"},
+	}, true)
+	var batchErr *domain.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 1, batchErr.Index)
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	_, err = repo.GetTask(ownerID, "3")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	results, err := repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchCreate, ID: "3", Task: &domain.Task{ID: "3", OwnerID: ownerID, Title: "new", Version: 1}},
+		{Op: domain.BatchCreate, ID: "1", Task: &domain.Task{ID: "1", OwnerID: ownerID, Title: "duplicate", Version: 1}},
+		{Op: domain.BatchComplete, ID: "1", Version: 1, At: now},
+		{Op: domain.BatchDelete, ID: "2", Version: 1},
+	}, false)
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, domain.ErrTaskExists)
+	assert.Equal(t, int64(2), results[2].Task.Version)
+
+	task, err := repo.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+	assert.Equal(t, now, task.UpdatedAt)
+	_, err = repo.GetTask(ownerID, "2")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	_, err = repo.GetTask(ownerID, "3")
+	assert.NoError(t, err)
+}
+
+func TestSQLTaskRepository_ApplyTaskChangesCreatesOccurrence(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	due := time.Date(2026, 3, 27, 8, 0, 0, 0, time.UTC)
+	for _, id := range []string{"1", "taken"} {
+		_, err := repo.CreateTask(&domain.Task{ID: id, OwnerID: ownerID, Title: "Water plants", Description: "Balcony", Version: 1, TaskSchedule: domain.TaskSchedule{DueAt: &due, Recurrence: "FREQ=WEEKLY;COUNT=3"}})
+		require.NoError(t, err)
+	}
+
+	// An occurrence that cannot be created rolls back the completion with it.
+	results, err := repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchComplete, ID: "1", Version: 1, At: due, ActorID: ownerID, OccurrenceID: "taken"},
+	}, false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, domain.ErrTaskExists)
+	task, err := repo.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.False(t, task.Completed)
+
+	results, err = repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchComplete, ID: "1", Version: 1, At: due, ActorID: ownerID, OccurrenceID: "2"},
+	}, true)
+	require.NoError(t, err)
+	require.NotNil(t, results[0].Occurrence)
+
+	occurrence, err := repo.GetTask(ownerID, "2")
+	require.NoError(t, err)
+	assert.False(t, occurrence.Completed)
+	assert.True(t, occurrence.DueAt.Equal(due.AddDate(0, 0, 7)))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", occurrence.Recurrence)
+}
+
+func TestSQLTaskRepository_Outbox(t *testing.T) {
+	db := openDB(t)
+	repo := sqldb.NewSQLTaskRepository(db)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: ownerID, Title: "title", Version: 1, UpdatedBy: ownerID})
+	require.NoError(t, err)
+	_, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "stale", Version: 7})
+	require.ErrorIs(t, err, domain.ErrVersionConflict)
+	_, err = repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchComplete, ID: "1", At: now, ActorID: "user-2"},
+		{Op: domain.BatchDelete, ID: "missing"},
+	}, true)
+	require.Error(t, err)
+	_, err = repo.UpdateTask(ownerID, "1", &domain.Task{Title: "done", Completed: true, UpdatedAt: now, UpdatedBy: "user-2"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTask(ownerID, "1", ownerID))
+
+	events, err := repo.PendingEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 3, "failed writes record nothing")
+	assert.Equal(t, []string{"1@1", "1@2", "1@3"}, []string{events[0].ID, events[1].ID, events[2].ID})
+	assert.Equal(t, []string{domain.TaskCreated, domain.TaskCompleted, domain.TaskDeleted}, []string{events[0].Type, events[1].Type, events[2].Type})
+	assert.Equal(t, "user-2", events[1].ActorID)
+	assert.Equal(t, "user-2", events[1].Task.UpdatedBy)
+	assert.Equal(t, now, events[1].OccurredAt)
+
+	require.NoError(t, repo.AckEvents([]string{"1@1", "1@2"}))
+	events, err = repo.PendingEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "1@3", events[0].ID)
+
+	page, err := sqldb.NewSQLAuditRepository(db).QueryAudit(domain.AuditQuery{TaskID: "1", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 3, "failed writes audit nothing")
+	assert.Equal(t, []string{"1@3", "1@2", "1@1"}, []string{page.Entries[0].ID, page.Entries[1].ID, page.Entries[2].ID})
+	assert.Equal(t, []string{domain.AuditDelete, domain.AuditComplete, domain.AuditCreate}, []string{page.Entries[0].Action, page.Entries[1].Action, page.Entries[2].Action})
+	assert.Equal(t, "user-2", page.Entries[1].ActorID)
+}
+
+func TestSQLTaskRepository_Trash(t *testing.T) {
+	db := openDB(t)
+	repo := sqldb.NewSQLTaskRepository(db)
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []string{"1", "2"} {
+		_, err := repo.CreateTask(&domain.Task{ID: id, OwnerID: ownerID, Title: "title " + id, Tags: []string{"home"}, Version: 1})
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteTask(ownerID, "1", "user-2"))
+	_, err := repo.ApplyTaskChanges(ownerID, []domain.TaskChange{{Op: domain.BatchDelete, ID: "2", At: deletedAt, ActorID: ownerID}}, true)
+	require.NoError(t, err)
+
+	_, err = repo.GetTask(ownerID, "1")
+	require.ErrorIs(t, err, domain.ErrTaskNotFound)
+	tags, err := repo.GetTagCounts(ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+	trash, err := repo.GetTrash(ownerID)
+	require.NoError(t, err)
+	require.Len(t, trash, 2)
+	assert.Equal(t, []string{"1", "2"}, []string{trash[0].ID, trash[1].ID})
+	assert.Equal(t, "user-2", trash[0].UpdatedBy)
+	assert.Equal(t, deletedAt, *trash[1].DeletedAt)
+
+	_, err = repo.RestoreTask("user-2", "1", "user-2")
+	require.ErrorIs(t, err, domain.ErrTaskNotFound)
+	restored, err := repo.RestoreTask(ownerID, "1", ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), restored.Version)
+	stored, err := repo.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.Equal(t, restored, stored)
+
+	events, err := repo.PendingEvents(10)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, domain.TaskRestored, last.Type)
+	assert.Equal(t, "1@3", last.ID)
+
+	purged, err := repo.PurgeTrash(deletedAt.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	trash, err = repo.GetTrash(ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	audit := sqldb.NewSQLAuditRepository(db)
+	restore, err := audit.QueryAudit(domain.AuditQuery{TaskID: "1", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, restore.Entries, 1)
+	assert.Equal(t, domain.AuditRestore, restore.Entries[0].Action)
+	purge, err := audit.QueryAudit(domain.AuditQuery{TaskID: "2", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, purge.Entries, 1)
+	assert.Equal(t, domain.AuditPurge, purge.Entries[0].Action)
+	assert.Equal(t, int64(3), purge.Entries[0].Revision)
+	assert.Empty(t, purge.Entries[0].ActorID)
+
+	events, err = repo.PendingEvents(10)
+	require.NoError(t, err)
+	last = events[len(events)-1]
+	assert.Equal(t, domain.TaskPurged, last.Type)
+	assert.Equal(t, "2@3", last.ID)
+	assert.Nil(t, last.Task)
+}
+
+func TestSQLAuditRepository(t *testing.T) {
+	repo := sqldb.NewSQLAuditRepository(openDB(t))
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := &domain.Task{ID: "1", OwnerID: ownerID, Title: "title", Version: 1}
+	renamed := &domain.Task{ID: "1", OwnerID: ownerID, Title: "renamed", Version: 2}
+
+	entries := []*domain.AuditEntry{}
+	for i, change := range []struct {
+		actorID       string
+		before, after *domain.Task
+	}{
+		{ownerID, nil, created},
+		{"user-2", created, renamed},
+		{ownerID, renamed, nil},
+	} {
+		entry := domain.NewAuditEntry(fmt.Sprintf("audit-%d", i+1), change.actorID, "", change.before, change.after, at.Add(time.Duration(i)*time.Hour))
+		entries = append(entries, &entry)
+	}
+	require.NoError(t, repo.AppendAudit(entries[:2]))
+	require.NoError(t, repo.AppendAudit(entries[2:]))
+	assert.Equal(t, int64(3), entries[2].Seq)
+
+	page, err := repo.QueryAudit(domain.AuditQuery{OwnerID: ownerID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, []string{domain.AuditDelete, domain.AuditUpdate}, []string{page.Entries[0].Action, page.Entries[1].Action})
+	assert.Equal(t, renamed, page.Entries[1].After)
+	assert.Equal(t, at.Add(time.Hour), page.Entries[1].At)
+
+	next, err := repo.QueryAudit(domain.AuditQuery{OwnerID: ownerID, Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, next.Entries, 1)
+	assert.Equal(t, domain.AuditCreate, next.Entries[0].Action)
+	assert.Empty(t, next.NextCursor)
+
+	until := at.Add(2 * time.Hour)
+	filtered, err := repo.QueryAudit(domain.AuditQuery{TaskID: "1", ActorID: "user-2", Until: &until, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, filtered.Entries, 1)
+	assert.Equal(t, "audit-2", filtered.Entries[0].ID)
+
+	revision, err := repo.QueryAudit(domain.AuditQuery{TaskID: "1", Revision: 3, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, revision.Entries, 1)
+	assert.Nil(t, revision.Entries[0].After)
+}
+
+func TestSQLTaskRepository_QueryTasks(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"1", "2", "3", "4", "5"} {
+		createdAt := base.Add(time.Duration(i) * time.Minute)
+		_, err := repo.CreateTask(&domain.Task{
+			ID:          id,
+			OwnerID:     ownerID,
+			Title:       "title " + id,
+			Description: "50% off_" + id,
+			Completed:   i%2 == 0,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		})
+		require.NoError(t, err)
+	}
+	_, err := repo.CreateTask(&domain.Task{ID: "6", OwnerID: "user-2", Title: "title 6", CreatedAt: base})
+	require.NoError(t, err)
+
+	query := domain.TaskQuery{OwnerID: ownerID, SortBy: domain.SortCreatedAt, Limit: 2}
+	var ids []string
+	for {
+		page, err := repo.QueryTasks(query)
+		require.NoError(t, err)
+		for _, task := range page.Tasks {
+			ids = append(ids, task.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, ids)
+
+	completed := false
+	page, err := repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, Completed: &completed, Description: "% OFF_4", SortBy: domain.SortUpdatedAt, Descending: true, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "4", page.Tasks[0].ID)
+	assert.Equal(t, base.Add(3*time.Minute), page.Tasks[0].CreatedAt)
+
+	_, err = repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, SortBy: domain.SortCreatedAt, Descending: true, Limit: 2, Cursor: query.Cursor})
+	assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+}
+
+func TestSQLTaskRepository_ScanTasks(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	count := domain.MaxTaskLimit + 5
+	for i := count - 1; i >= 0; i-- {
+		createdAt := base.Add(time.Duration(i) * time.Minute)
+		_, err := repo.CreateTask(&domain.Task{ID: fmt.Sprintf("%03d", i), OwnerID: ownerID, Title: "title", CreatedAt: createdAt, UpdatedAt: createdAt})
+		require.NoError(t, err)
+	}
+	_, err := repo.CreateTask(&domain.Task{ID: "other", OwnerID: "user-2", Title: "title", CreatedAt: base})
+	require.NoError(t, err)
+
+	var ids []string
+	require.NoError(t, repo.ScanTasks(ownerID, func(task *domain.Task) error {
+		ids = append(ids, task.ID)
+		return nil
+	}))
+	require.Len(t, ids, count)
+	assert.Equal(t, "000", ids[0])
+	assert.Equal(t, fmt.Sprintf("%03d", count-1), ids[count-1])
+
+	stop := errors.New("stop")
+	calls := 0
+	assert.ErrorIs(t, repo.ScanTasks(ownerID, func(*domain.Task) error {
+		calls++
+		return stop
+	}), stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSQLTaskRepository_QueryTasksFoldsUnicode(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	_, err := repo.CreateTask(&domain.Task{ID: "1", OwnerID: ownerID, Title: "ÁRBOL de Navidad", Description: "ÑANDÚ"})
+	require.NoError(t, err)
+
+	for _, query := range []domain.TaskQuery{
+		{OwnerID: ownerID, Title: "árbol", SortBy: domain.SortCreatedAt, Limit: 10},
+		{OwnerID: ownerID, Description: "ñandú", SortBy: domain.SortCreatedAt, Limit: 10},
+	} {
+		page, err := repo.QueryTasks(query)
+		require.NoError(t, err)
+		require.Len(t, page.Tasks, 1)
+		assert.True(t, query.Matches(page.Tasks[0]), "memory backends agree")
+	}
+}
+
+func TestSQLTaskRepository_DueDates(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tasks := []*domain.Task{
+		{ID: "1", OwnerID: ownerID, TaskSchedule: domain.TaskSchedule{DueAt: &past, TimeZone: "Europe/Madrid", Priority: domain.PriorityHigh, ReminderMinutes: []int{10, 90}, Recurrence: "FREQ=DAILY"}},
+		{ID: "2", OwnerID: ownerID, Completed: true, TaskSchedule: domain.TaskSchedule{DueAt: &past, ReminderMinutes: []int{10}}},
+		{ID: "3", OwnerID: ownerID, TaskSchedule: domain.TaskSchedule{DueAt: &future}},
+		{ID: "4", OwnerID: ownerID},
+		{ID: "5", OwnerID: "user-2", TaskSchedule: domain.TaskSchedule{DueAt: &future, ReminderMinutes: []int{5}}},
+	}
+	for _, task := range tasks {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	task, err := repo.GetTask(ownerID, "1")
+	require.NoError(t, err)
+	assert.Equal(t, tasks[0].TaskSchedule, task.TaskSchedule)
+
+	ids := func(tasks []*domain.Task) []string {
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	page, err := repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, Overdue: true, Now: now, SortBy: domain.SortCreatedAt, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, ids(page.Tasks))
+
+	page, err = repo.QueryTasks(domain.TaskQuery{OwnerID: ownerID, DueBefore: &now, SortBy: domain.SortCreatedAt, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, ids(page.Tasks))
+
+	due, err := repo.GetTasksDueBetween(past.Add(-time.Minute), future)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "5"}, ids(due))
+
+	updated, err := repo.UpdateTask(ownerID, "1", &domain.Task{Title: "title", Description: "description"})
+	require.NoError(t, err)
+	assert.Nil(t, updated.DueAt)
+	assert.Empty(t, updated.ReminderMinutes)
+}
+
+func TestSQLUserRepository_CreateAndLogin(t *testing.T) {
+	repo := sqldb.NewSQLUserRepository(openDB(t), utils.NewHashPassword(app.BcryptCrypto{}, bcrypt.MinCost), jwtManager)
+
+	_, err := repo.Create(&domain.User{ID: "user-1", Username: "cristianm", Password: "password"})
+	require.NoError(t, err)
+
+	_, err = repo.Create(&domain.User{ID: "user-2", Username: "cristianm", Password: "password"})
+	assert.ErrorIs(t, err, domain.ErrUsernameTaken)
+
+	token, err := repo.Login(domain.User{Username: "cristianm", Password: "password"})
+	require.NoError(t, err)
+	claims, err := jwtManager.ValidateJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.(*utils.Claims).UserID)
+
+	_, err = repo.Login(domain.User{Username: "cristianm", Password: "wrong"})
+	assert.Error(t, err)
+
+	user, err := repo.GetByUsername("cristianm")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+	_, err = repo.GetByUsername("unknown")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestSQLUserRepository_ConcurrentCreate(t *testing.T) {
+	repo := sqldb.NewSQLUserRepository(openDB(t), utils.NewHashPassword(app.BcryptCrypto{}, bcrypt.MinCost), jwtManager)
+
+	const attempts = 8
+	errs := make(chan error, attempts)
+	for i := range attempts {
+		go func() {
+			_, err := repo.Create(&domain.User{ID: fmt.Sprintf("user-%d", i), Username: "cristianm", Password: "password"})
+			errs <- err
+		}()
+	}
+
+	var created int
+	for range attempts {
+		err := <-errs
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, domain.ErrUsernameTaken)
+	}
+	assert.Equal(t, 1, created)
+}
+
+func TestSQLUserRepository_DisableAndList(t *testing.T) {
+	repo := sqldb.NewSQLUserRepository(openDB(t), utils.NewHashPassword(app.BcryptCrypto{}, bcrypt.MinCost), jwtManager)
+
+	_, err := repo.Create(&domain.User{ID: "user-1", Username: "cristianm", Password: "password", Role: domain.RoleAdmin})
+	require.NoError(t, err)
+	require.NoError(t, repo.SetDisabled("user-1", true))
+	assert.ErrorIs(t, repo.SetDisabled("missing", true), domain.ErrUserNotFound)
+
+	_, err = repo.Login(domain.User{Username: "cristianm", Password: "password"})
+	assert.ErrorIs(t, err, domain.ErrUserDisabled)
+
+	users, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.True(t, users[0].Disabled)
+	assert.Equal(t, domain.RoleAdmin, users[0].Role)
+
+	require.NoError(t, repo.SetDisabled("user-1", false))
+	token, err := repo.Login(domain.User{Username: "cristianm", Password: "password"})
+	require.NoError(t, err)
+	claims, err := jwtManager.ValidateJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, claims.(*utils.Claims).Role)
+}
+
+func TestSQLTaskRepository_Links(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	links := domain.TaskLinks{ParentID: "1", BlockedBy: []string{"2", "3"}}
+	_, err := repo.CreateTask(&domain.Task{ID: "4", OwnerID: ownerID, Title: "title", Description: "description", Version: 1, TaskLinks: links})
+	require.NoError(t, err)
+
+	task, err := repo.GetTask(ownerID, "4")
+	require.NoError(t, err)
+	assert.Equal(t, links, task.TaskLinks)
+
+	updated, err := repo.UpdateTask(ownerID, "4", &domain.Task{Title: "title", Description: "description", TaskLinks: domain.TaskLinks{BlockedBy: []string{"3"}}})
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskLinks{BlockedBy: []string{"3"}}, updated.TaskLinks)
+
+	results, err := repo.ApplyTaskChanges(ownerID, []domain.TaskChange{
+		{Op: domain.BatchUpdate, ID: "4", Task: &domain.Task{Title: "title", Description: "description", TaskLinks: domain.TaskLinks{ParentID: "2"}}},
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskLinks{ParentID: "2"}, results[0].Task.TaskLinks)
+	task, err = repo.GetTask(ownerID, "4")
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskLinks{ParentID: "2"}, task.TaskLinks)
+}
+
+func TestSQLListRepository_Members(t *testing.T) {
+	db := openDB(t)
+	repo := sqldb.NewSQLListRepository(db)
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := repo.CreateList(&domain.List{ID: "list-1", OwnerID: ownerID, Name: "home", CreatedAt: created, UpdatedAt: created})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SetMember("list-1", domain.ListMember{UserID: "user-2", Username: "ana", Role: domain.ListViewer}))
+	require.NoError(t, repo.SetMember("list-1", domain.ListMember{UserID: "user-2", Username: "ana", Role: domain.ListEditor}))
+	assert.ErrorIs(t, repo.SetMember("missing", domain.ListMember{UserID: "user-2"}), domain.ErrListNotFound)
+
+	list, err := repo.GetList("list-1")
+	require.NoError(t, err)
+	assert.Equal(t, created, list.CreatedAt)
+	assert.Equal(t, []domain.ListMember{{UserID: "user-2", Username: "ana", Role: domain.ListEditor}}, list.Members)
+
+	shared, err := repo.GetListsForUser("user-2")
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+	assert.Equal(t, "list-1", shared[0].ID)
+	none, err := repo.GetListsForUser("user-3")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	require.NoError(t, repo.RemoveMember("list-1", "user-2"))
+	assert.ErrorIs(t, repo.RemoveMember("list-1", "user-2"), domain.ErrListMemberNotFound)
+
+	require.NoError(t, repo.DeleteList("list-1"))
+	_, err = repo.GetList("list-1")
+	assert.ErrorIs(t, err, domain.ErrListNotFound)
+}
+
+func TestSQLTaskRepository_QueryByList(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	for _, task := range []*domain.Task{
+		{ID: "1", OwnerID: ownerID, Title: "title", ListID: "list-1"},
+		{ID: "2", OwnerID: ownerID, Title: "title"},
+	} {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	query := domain.TaskQuery{OwnerID: ownerID, ListID: "list-1"}
+	require.NoError(t, query.Normalize())
+	page, err := repo.QueryTasks(query)
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "1", page.Tasks[0].ID)
+	assert.Equal(t, "list-1", page.Tasks[0].ListID)
+}
+
+func TestSQLTaskRepository_Tags(t *testing.T) {
+	repo := sqldb.NewSQLTaskRepository(openDB(t))
+	for _, task := range []*domain.Task{
+		{ID: "1", OwnerID: ownerID, Title: "title", Tags: []string{"backend", "urgent"}},
+		{ID: "2", OwnerID: ownerID, Title: "title", Tags: []string{"urgent"}},
+		{ID: "3", OwnerID: ownerID, Title: "title", Tags: []string{"front_end"}},
+		{ID: "4", OwnerID: ownerID, Title: "title"},
+		{ID: "5", OwnerID: "user-2", Title: "title", Tags: []string{"urgent"}},
+	} {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	query := func(tags []string, any bool) []string {
+		q := domain.TaskQuery{OwnerID: ownerID, Tags: tags, AnyTag: any}
+		require.NoError(t, q.Normalize())
+		page, err := repo.QueryTasks(q)
+		require.NoError(t, err)
+		ids := []string{}
+		for _, task := range page.Tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"1", "2"}, query([]string{"urgent"}, false))
+	assert.Equal(t, []string{"1"}, query([]string{"backend", "urgent"}, false))
+	assert.Equal(t, []string{"1", "3"}, query([]string{"backend", "front_end"}, true))
+	assert.Empty(t, query([]string{"front"}, false))
+	assert.Empty(t, query([]string{"front_"}, false))
+
+	_, err := repo.UpdateTask(ownerID, "2", &domain.Task{Title: "title", Tags: []string{"backend"}})
+	require.NoError(t, err)
+	task, err := repo.GetTask(ownerID, "2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, task.Tags)
+
+	catalog, err := repo.GetTagCounts(ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TagCount{{Tag: "backend", Count: 2}, {Tag: "front_end", Count: 1}, {Tag: "urgent", Count: 1}}, catalog)
+}
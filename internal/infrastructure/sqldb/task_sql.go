@@ -0,0 +1,742 @@
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list-task/internal/domain"
+)
+
+const (
+	taskColumns      = `id, owner_id, title, description, completed, created_at, updated_at, version, due_at, time_zone, priority, reminder_minutes, recurrence, parent_id, blocked_by, list_id, tags, updated_by`
+	taskPlaceholders = `?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`
+	// insertTaskSQL also fills the folded title and description, which are
+	// only written, never read back.
+	insertTaskSQL = `INSERT INTO tasks (` + taskColumns + `, title_fold, description_fold) VALUES (` + taskPlaceholders + `, ?, ?)`
+)
+
+// SQLTaskRepository is a TaskRepository backed by a database/sql connection.
+// Timestamps are stored as Unix nanoseconds so they sort the same way in
+// every database; a task without a due date has a NULL due_at. Reminders and
+// blockers are stored as comma-separated lists, and so are tags, with a
+// leading and trailing comma so a tag is matched with LIKE '%,tag,%'. The
+// title and description are also stored folded with domain.FoldText, so
+// substring filters fold case the same way as the other backends.
+//
+// The outbox is the task_outbox table, written in the transaction of the
+// change, with events as JSON in the order of their seq.
+type SQLTaskRepository struct {
+	db *sql.DB
+}
+
+func NewSQLTaskRepository(db *sql.DB) *SQLTaskRepository {
+	return &SQLTaskRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row rowScanner) (*domain.Task, error) {
+	var (
+		task                 domain.Task
+		createdAt, updatedAt int64
+		dueAt                sql.NullInt64
+		reminders, blockedBy string
+		tags                 string
+	)
+	if err := row.Scan(
+		&task.ID, &task.OwnerID, &task.Title, &task.Description, &task.Completed, &createdAt, &updatedAt, &task.Version,
+		&dueAt, &task.TimeZone, &task.Priority, &reminders, &task.Recurrence,
+		&task.ParentID, &blockedBy, &task.ListID, &tags, &task.UpdatedBy,
+	); err != nil {
+		return nil, err
+	}
+	task.CreatedAt = domain.FromUnixNanos(createdAt)
+	task.UpdatedAt = domain.FromUnixNanos(updatedAt)
+	if dueAt.Valid {
+		due := domain.FromUnixNanos(dueAt.Int64)
+		task.DueAt = &due
+	}
+	reminderMinutes, err := parseReminders(reminders)
+	if err != nil {
+		return nil, err
+	}
+	task.ReminderMinutes = reminderMinutes
+	if blockedBy != "" {
+		task.BlockedBy = strings.Split(blockedBy, ",")
+	}
+	task.Tags = parseTags(tags)
+	return &task, nil
+}
+
+// taskValues returns the values of task in the order of insertTaskSQL.
+func taskValues(task *domain.Task) []any {
+	return []any{
+		task.ID, task.OwnerID, task.Title, task.Description, task.Completed,
+		domain.UnixNanos(task.CreatedAt), domain.UnixNanos(task.UpdatedAt), task.Version,
+		dueAtValue(task.DueAt), task.TimeZone, task.Priority, formatReminders(task.ReminderMinutes), task.Recurrence,
+		task.ParentID, strings.Join(task.BlockedBy, ","), task.ListID, formatTags(task.Tags), task.UpdatedBy,
+		domain.FoldText(task.Title), domain.FoldText(task.Description),
+	}
+}
+
+func dueAtValue(dueAt *time.Time) any {
+	if dueAt == nil {
+		return nil
+	}
+	return domain.UnixNanos(*dueAt)
+}
+
+func formatReminders(minutes []int) string {
+	items := make([]string, len(minutes))
+	for i, m := range minutes {
+		items[i] = strconv.Itoa(m)
+	}
+	return strings.Join(items, ",")
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "," + strings.Join(tags, ",") + ","
+}
+
+func parseTags(value string) []string {
+	value = strings.Trim(value, ",")
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func parseReminders(value string) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	items := strings.Split(value, ",")
+	minutes := make([]int, len(items))
+	for i, item := range items {
+		m, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reminder_minutes %q: %w", value, err)
+		}
+		minutes[i] = m
+	}
+	return minutes, nil
+}
+
+// CreateTask inserts a new task, its event and its audit entry.
+func (r *SQLTaskRepository) CreateTask(task *domain.Task) (*domain.Task, error) {
+	err := r.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(insertTaskSQL, taskValues(task)...); err != nil {
+			return err
+		}
+		if err := recordEvents(tx, domain.NewTaskChangeEvent(task.UpdatedBy, nil, task, task.UpdatedAt)); err != nil {
+			return err
+		}
+		return appendAudit(tx, domain.NewTaskChangeAudit(task.UpdatedBy, "", nil, task, task.UpdatedAt))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetTask get a task owned by ownerID.
+func (r *SQLTaskRepository) GetTask(ownerID, id string) (*domain.Task, error) {
+	task, err := scanTask(r.db.QueryRow(
+		`SELECT `+taskColumns+` FROM tasks WHERE id = ? AND owner_id = ?`,
+		id, ownerID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetTasks get all tasks owned by ownerID.
+func (r *SQLTaskRepository) GetTasks(ownerID string) ([]*domain.Task, error) {
+	return r.queryTasks(`SELECT `+taskColumns+` FROM tasks WHERE owner_id = ?`, ownerID)
+}
+
+// QueryTasks get one page of the tasks matching query, letting the database
+// filter, sort and seek past the cursor using the (owner_id, <sort>, id) indexes.
+func (r *SQLTaskRepository) QueryTasks(query domain.TaskQuery) (*domain.TaskPage, error) {
+	cursor, err := query.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	column := "created_at"
+	if query.SortBy == domain.SortUpdatedAt {
+		column = "updated_at"
+	}
+	direction, seek := "ASC", ">"
+	if query.Descending {
+		direction, seek = "DESC", "<"
+	}
+
+	where := []string{"owner_id = ?"}
+	args := []any{query.OwnerID}
+	if query.ListID != "" {
+		where = append(where, "list_id = ?")
+		args = append(args, query.ListID)
+	}
+	if len(query.Tags) > 0 {
+		tagged := make([]string, len(query.Tags))
+		for i, tag := range query.Tags {
+			tagged[i] = "tags LIKE ? ESCAPE '\\'"
+			args = append(args, "%"+likeEscaper.Replace(","+tag+",")+"%")
+		}
+		joiner := " AND "
+		if query.AnyTag {
+			joiner = " OR "
+		}
+		where = append(where, "("+strings.Join(tagged, joiner)+")")
+	}
+	if query.Completed != nil {
+		where = append(where, "completed = ?")
+		args = append(args, *query.Completed)
+	}
+	if query.Title != "" {
+		where = append(where, "title_fold LIKE ? ESCAPE '\\'")
+		args = append(args, likePattern(query.Title))
+	}
+	if query.Description != "" {
+		where = append(where, "description_fold LIKE ? ESCAPE '\\'")
+		args = append(args, likePattern(query.Description))
+	}
+	if query.Overdue {
+		where = append(where, "completed = ? AND due_at IS NOT NULL AND due_at < ?")
+		args = append(args, false, domain.UnixNanos(query.Now))
+	}
+	if query.DueBefore != nil {
+		where = append(where, "due_at IS NOT NULL AND due_at < ?")
+		args = append(args, domain.UnixNanos(*query.DueBefore))
+	}
+	if cursor != nil {
+		where = append(where, "("+column+" "+seek+" ? OR ("+column+" = ? AND id "+seek+" ?))")
+		args = append(args, cursor.Value, cursor.Value, cursor.ID)
+	}
+	args = append(args, query.Limit+1)
+
+	tasks, err := r.queryTasks(
+		`SELECT `+taskColumns+` FROM tasks WHERE `+strings.Join(where, " AND ")+
+			` ORDER BY `+column+` `+direction+`, id `+direction+` LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.TaskPage{Tasks: tasks}
+	if len(tasks) > query.Limit {
+		page.Tasks = tasks[:query.Limit]
+		page.NextCursor = query.EncodeCursor(page.Tasks[query.Limit-1])
+	}
+	if page.Tasks == nil {
+		page.Tasks = []*domain.Task{}
+	}
+	return page, nil
+}
+
+// GetTagCounts get the tag catalog of ownerID, counting the tags of the
+// owner's tagged tasks.
+func (r *SQLTaskRepository) GetTagCounts(ownerID string) ([]domain.TagCount, error) {
+	rows, err := r.db.Query(`SELECT tags FROM tasks WHERE owner_id = ? AND tags <> ''`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tags string
+		if err := rows.Scan(&tags); err != nil {
+			return nil, err
+		}
+		for _, tag := range parseTags(tags) {
+			counts[tag]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	catalog := make([]domain.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		catalog = append(catalog, domain.TagCount{Tag: tag, Count: count})
+	}
+	domain.SortTagCounts(catalog)
+	return catalog, nil
+}
+
+// ScanTasks calls fn with every task of ownerID, oldest first, reading them
+// one page at a time through the (owner_id, created_at, id) index. No rows
+// are open while fn runs, so a slow fn never holds up writers.
+func (r *SQLTaskRepository) ScanTasks(ownerID string, fn func(*domain.Task) error) error {
+	query := domain.TaskQuery{OwnerID: ownerID, SortBy: domain.SortCreatedAt, Limit: domain.MaxTaskLimit}
+	for {
+		page, err := r.QueryTasks(query)
+		if err != nil {
+			return err
+		}
+		for _, task := range page.Tasks {
+			if err := fn(task); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// GetTasksDueBetween get the open tasks of every owner with reminders due in
+// (from, to], using the due_at index.
+func (r *SQLTaskRepository) GetTasksDueBetween(from, to time.Time) ([]*domain.Task, error) {
+	return r.queryTasks(
+		`SELECT `+taskColumns+` FROM tasks WHERE due_at > ? AND due_at <= ? AND completed = ? AND reminder_minutes <> ''`,
+		domain.UnixNanos(from), domain.UnixNanos(to), false,
+	)
+}
+
+func (r *SQLTaskRepository) queryTasks(query string, args ...any) ([]*domain.Task, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*domain.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// UpdateTask update task by id when it is owned by ownerID, and records its
+// event and audit entry. The version check is part of the UPDATE so concurrent writers
+// cannot both succeed.
+func (r *SQLTaskRepository) UpdateTask(ownerID, id string, task *domain.Task) (*domain.Task, error) {
+	var updated *domain.Task
+	err := r.inTx(func(tx *sql.Tx) error {
+		current, err := scanTask(tx.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ? AND owner_id = ?`, id, ownerID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if task.Version != 0 && task.Version != current.Version {
+			return domain.ErrVersionConflict
+		}
+
+		next := *task
+		next.ID = id
+		next.OwnerID = ownerID
+		next.CreatedAt = current.CreatedAt
+		next.Version = current.Version + 1
+		if err := updateTaskRow(tx, &next, current.Version); err != nil {
+			return err
+		}
+		updated = &next
+		if err := recordEvents(tx, domain.NewTaskChangeEvent(next.UpdatedBy, current, &next, next.UpdatedAt)); err != nil {
+			return err
+		}
+		return appendAudit(tx, domain.NewTaskChangeAudit(next.UpdatedBy, "", current, &next, next.UpdatedAt))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// updateTaskRow writes next over the task with its ID if it still has the
+// given version, or fails with domain.ErrVersionConflict.
+func updateTaskRow(tx *sql.Tx, next *domain.Task, version int64) error {
+	result, err := tx.Exec(
+		`UPDATE tasks SET title = ?, description = ?, completed = ?, updated_at = ?, version = ?,
+		due_at = ?, time_zone = ?, priority = ?, reminder_minutes = ?, recurrence = ?, parent_id = ?, blocked_by = ?,
+		list_id = ?, tags = ?, updated_by = ?, title_fold = ?, description_fold = ? WHERE id = ? AND version = ?`,
+		next.Title, next.Description, next.Completed, domain.UnixNanos(next.UpdatedAt), next.Version,
+		dueAtValue(next.DueAt), next.TimeZone, next.Priority, formatReminders(next.ReminderMinutes), next.Recurrence,
+		next.ParentID, strings.Join(next.BlockedBy, ","), next.ListID, formatTags(next.Tags), next.UpdatedBy,
+		domain.FoldText(next.Title), domain.FoldText(next.Description), next.ID, version,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteTask moves a task owned by ownerID to the trash, and records its
+// event and audit entry.
+func (r *SQLTaskRepository) DeleteTask(ownerID, id, actorID string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		current, err := scanTask(tx.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ? AND owner_id = ?`, id, ownerID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec(`DELETE FROM tasks WHERE id = ? AND version = ?`, id, current.Version)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return domain.ErrVersionConflict
+		}
+		now := time.Now().UTC()
+		if err := trashTask(tx, domain.TrashTask(current, actorID, now)); err != nil {
+			return err
+		}
+		if err := recordEvents(tx, domain.NewTaskChangeEvent(actorID, current, nil, now)); err != nil {
+			return err
+		}
+		return appendAudit(tx, domain.NewTaskChangeAudit(actorID, "", current, nil, now))
+	})
+}
+
+// trashTask stores a trashed task, encoded, in the trash.
+func trashTask(tx *sql.Tx, task *domain.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO task_trash (id, owner_id, deleted_at, task) VALUES (?, ?, ?, ?)`,
+		task.ID, task.OwnerID, domain.UnixNanos(*task.DeletedAt), string(data),
+	)
+	return err
+}
+
+func scanTrashedTask(row rowScanner) (*domain.Task, error) {
+	var (
+		data string
+		task domain.Task
+	)
+	if err := row.Scan(&data); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return nil, fmt.Errorf("invalid trashed task: %w", err)
+	}
+	return &task, nil
+}
+
+// GetTrash get the trashed tasks of ownerID, most recently deleted first.
+func (r *SQLTaskRepository) GetTrash(ownerID string) ([]*domain.Task, error) {
+	rows, err := r.db.Query(`SELECT task FROM task_trash WHERE owner_id = ? ORDER BY deleted_at DESC, id`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*domain.Task{}
+	for rows.Next() {
+		task, err := scanTrashedTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// RestoreTask moves a trashed task owned by ownerID back to the tasks, and
+// records its event and audit entry.
+func (r *SQLTaskRepository) RestoreTask(ownerID, id, actorID string) (*domain.Task, error) {
+	var restored *domain.Task
+	err := r.inTx(func(tx *sql.Tx) error {
+		trashed, err := scanTrashedTask(tx.QueryRow(`SELECT task FROM task_trash WHERE id = ? AND owner_id = ?`, id, ownerID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM task_trash WHERE id = ?`, id); err != nil {
+			return err
+		}
+		restored = domain.RestoreTask(trashed, actorID, time.Now().UTC())
+		if _, err := tx.Exec(insertTaskSQL, taskValues(restored)...); err != nil {
+			return err
+		}
+		if err := recordEvents(tx, domain.NewTaskChangeEvent(actorID, trashed, restored, restored.UpdatedAt)); err != nil {
+			return err
+		}
+		return appendAudit(tx, domain.NewTaskChangeAudit(actorID, "", trashed, restored, restored.UpdatedAt))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// PurgeTrash delete the tasks of every owner trashed before the given time,
+// and records the events and audit entries of their removal in the same
+// transaction.
+func (r *SQLTaskRepository) PurgeTrash(before time.Time) (int, error) {
+	var purged []*domain.Task
+	err := r.inTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(`SELECT task FROM task_trash WHERE deleted_at < ? ORDER BY deleted_at, id`, domain.UnixNanos(before))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			task, err := scanTrashedTask(rows)
+			if err != nil {
+				return err
+			}
+			purged = append(purged, task)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, task := range purged {
+			if _, err := tx.Exec(`DELETE FROM task_trash WHERE id = ?`, task.ID); err != nil {
+				return err
+			}
+			if err := recordEvents(tx, domain.NewTaskPurgeEvent(task, now)); err != nil {
+				return err
+			}
+			if err := appendAudit(tx, domain.NewTaskChangeAudit("", domain.AuditPurge, task, nil, now)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(purged), nil
+}
+
+// inTx runs fn in a transaction, committed when fn succeeds.
+func (r *SQLTaskRepository) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordEvents appends events to the outbox, skipping those already there.
+func recordEvents(tx *sql.Tx, events ...domain.TaskEvent) error {
+	for _, event := range events {
+		var recorded int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM task_outbox WHERE id = ?`, event.ID).Scan(&recorded); err != nil {
+			return err
+		}
+		if recorded > 0 {
+			continue
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO task_outbox (seq, id, event) SELECT COALESCE(MAX(seq), 0) + 1, ?, ? FROM task_outbox`,
+			event.ID, string(data),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PendingEvents get the oldest events of the outbox.
+func (r *SQLTaskRepository) PendingEvents(limit int) ([]domain.TaskEvent, error) {
+	rows, err := r.db.Query(`SELECT event FROM task_outbox ORDER BY seq LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.TaskEvent{}
+	for rows.Next() {
+		var (
+			data  string
+			event domain.TaskEvent
+		)
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("invalid outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// AckEvents delete published events from the outbox.
+func (r *SQLTaskRepository) AckEvents(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	_, err := r.db.Exec(`DELETE FROM task_outbox WHERE id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)`, args...)
+	return err
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTaskNotFound
+	}
+	return nil
+}
+
+// likeEscaper escapes the LIKE wildcards of a literal.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePattern builds a substring LIKE pattern over a folded column, escaping wildcards.
+func likePattern(substr string) string {
+	return "%" + likeEscaper.Replace(domain.FoldText(substr)) + "%"
+}
+
+// ApplyTaskChanges applies a batch of changes inside one transaction. Every
+// write is conditioned on the version read earlier in the same transaction,
+// so a concurrent update makes the change fail with domain.ErrVersionConflict.
+func (r *SQLTaskRepository) ApplyTaskChanges(ownerID string, changes []domain.TaskChange, atomic bool) ([]domain.TaskChangeResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	results := make([]domain.TaskChangeResult, len(changes))
+	for i, change := range changes {
+		result, err := applyTaskChangeWithOccurrence(tx, ownerID, change)
+		if err != nil && !isDomainError(err) {
+			return nil, err
+		}
+		if err != nil {
+			if atomic {
+				return nil, &domain.BatchError{Index: i, Err: err}
+			}
+			results[i].Err = err
+			continue
+		}
+		results[i] = result
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// applyTaskChangeWithOccurrence applies one change, with the next occurrence
+// of a recurring task it completes, and records their events and audit
+// entries. A change that fails is rolled back to a savepoint, so the rest of
+// the transaction holds either both writes or neither.
+func applyTaskChangeWithOccurrence(tx *sql.Tx, ownerID string, change domain.TaskChange) (domain.TaskChangeResult, error) {
+	if _, err := tx.Exec(`SAVEPOINT task_change`); err != nil {
+		return domain.TaskChangeResult{}, err
+	}
+	result, err := func() (domain.TaskChangeResult, error) {
+		previous, task, err := applyTaskChange(tx, ownerID, change)
+		if err != nil {
+			return domain.TaskChangeResult{}, err
+		}
+		events := []domain.TaskEvent{change.Event(previous, task)}
+		result := domain.TaskChangeResult{Task: task, Previous: previous}
+
+		if create, ok := change.NextOccurrence(previous, task); ok {
+			if _, result.Occurrence, err = applyTaskChange(tx, ownerID, create); err != nil {
+				return domain.TaskChangeResult{}, err
+			}
+			events = append(events, create.Event(nil, result.Occurrence))
+		}
+		if err := recordEvents(tx, events...); err != nil {
+			return domain.TaskChangeResult{}, err
+		}
+		audit := domain.AuditTaskChanges([]domain.TaskChange{change}, []domain.TaskChangeResult{result})
+		return result, appendAudit(tx, audit...)
+	}()
+	if err != nil {
+		if _, rerr := tx.Exec(`ROLLBACK TO task_change`); rerr != nil {
+			return domain.TaskChangeResult{}, rerr
+		}
+		return domain.TaskChangeResult{}, err
+	}
+	_, err = tx.Exec(`RELEASE task_change`)
+	return result, err
+}
+
+// applyTaskChange applies one change and returns the task before and after it.
+func applyTaskChange(tx *sql.Tx, ownerID string, change domain.TaskChange) (*domain.Task, *domain.Task, error) {
+	current, err := scanTask(tx.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, change.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		current = nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	next, err := change.Apply(ownerID, current)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case change.Op == domain.BatchCreate:
+		_, err = tx.Exec(insertTaskSQL, taskValues(next)...)
+	case next == nil:
+		var result sql.Result
+		result, err = tx.Exec(`DELETE FROM tasks WHERE id = ? AND version = ?`, current.ID, current.Version)
+		if err == nil && requireAffected(result) != nil {
+			err = domain.ErrVersionConflict
+		}
+		if err == nil {
+			err = trashTask(tx, domain.TrashTask(current, change.ActorID, change.At))
+		}
+	default:
+		err = updateTaskRow(tx, next, current.Version)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, next, nil
+}
+
+// isDomainError reports whether err is an expected outcome of a change rather
+// than a database failure.
+func isDomainError(err error) bool {
+	var domainErr *domain.Error
+	return errors.As(err, &domainErr)
+}
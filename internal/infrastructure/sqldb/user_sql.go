@@ -0,0 +1,145 @@
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/utils"
+)
+
+// SQLUserRepository is a UserRepository backed by a database/sql connection.
+type SQLUserRepository struct {
+	db        *sql.DB
+	appCrypto *utils.DefaultAppCrypto
+	jwt       *utils.JWTManager
+}
+
+func NewSQLUserRepository(db *sql.DB, appCrypto *utils.DefaultAppCrypto, jwt *utils.JWTManager) *SQLUserRepository {
+	return &SQLUserRepository{
+		db:        db,
+		appCrypto: appCrypto,
+		jwt:       jwt,
+	}
+}
+
+// Create stores a user with a hashed password, rejecting taken usernames.
+// Uniqueness is left to the UNIQUE index on username, so concurrent
+// registrations of the same name cannot both succeed.
+func (r *SQLUserRepository) Create(user *domain.User) (string, error) {
+	password, err := r.appCrypto.HashPassword(user.Password)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.db.Exec(
+		`INSERT INTO users (id, username, password, role) VALUES (?, ?, ?, ?)`,
+		user.ID, user.Username, password, user.Role,
+	); err != nil {
+		return "", r.insertError(user.Username, err)
+	}
+
+	user.Password = password
+	return r.jwt.GenerateJWT(user.ID, user.Username, user.Role)
+}
+
+// insertError maps a failed insert to domain.ErrUsernameTaken when the
+// username is already stored, which is how a UNIQUE violation shows up
+// regardless of the driver reporting it.
+func (r *SQLUserRepository) insertError(username string, err error) error {
+	var exists int
+	if r.db.QueryRow(`SELECT 1 FROM users WHERE username = ?`, username).Scan(&exists) == nil {
+		return domain.ErrUsernameTaken
+	}
+	return err
+}
+
+// Login looks the user up by username and checks the password.
+func (r *SQLUserRepository) Login(user domain.User) (string, error) {
+	var stored domain.User
+	err := r.db.QueryRow(
+		`SELECT id, username, password, role, disabled FROM users WHERE username = ?`,
+		user.Username,
+	).Scan(&stored.ID, &stored.Username, &stored.Password, &stored.Role, &stored.Disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !r.appCrypto.CheckPasswordHash(user.Password, stored.Password) {
+		return "", domain.ErrInvalidCredentials
+	}
+	if stored.Disabled {
+		return "", domain.ErrUserDisabled
+	}
+
+	return r.jwt.GenerateJWT(stored.ID, stored.Username, stored.Role)
+}
+
+// Get returns the user with the given ID.
+func (r *SQLUserRepository) Get(id string) (*domain.User, error) {
+	return r.getUser(`SELECT id, username, password, role, disabled FROM users WHERE id = ?`, id)
+}
+
+// GetByUsername returns the user with the given username, using the unique
+// username index.
+func (r *SQLUserRepository) GetByUsername(username string) (*domain.User, error) {
+	return r.getUser(`SELECT id, username, password, role, disabled FROM users WHERE username = ?`, username)
+}
+
+func (r *SQLUserRepository) getUser(query string, arg any) (*domain.User, error) {
+	var user domain.User
+	err := r.db.QueryRow(query, arg).Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.Disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// List returns every registered user.
+func (r *SQLUserRepository) List() ([]*domain.User, error) {
+	rows, err := r.db.Query(`SELECT id, username, password, role, disabled FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.Disabled); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// SetDisabled enables or disables a user account.
+func (r *SQLUserRepository) SetDisabled(id string, disabled bool) error {
+	return r.updateUser(`UPDATE users SET disabled = ? WHERE id = ?`, disabled, id)
+}
+
+// SetRole changes the role of a user account.
+func (r *SQLUserRepository) SetRole(id, role string) error {
+	return r.updateUser(`UPDATE users SET role = ? WHERE id = ?`, role, id)
+}
+
+func (r *SQLUserRepository) updateUser(query string, args ...any) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
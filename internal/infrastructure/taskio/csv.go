@@ -0,0 +1,196 @@
+package taskio
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list-task/internal/domain"
+)
+
+var csvHeader = []string{
+	"id", "title", "description", "completed", "created_at", "updated_at",
+	"due_at", "time_zone", "priority", "reminder_minutes", "recurrence", "tags",
+}
+
+type csvEncoder struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(task *domain.Task) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.w.Write([]string{
+		task.ID,
+		task.Title,
+		task.Description,
+		strconv.FormatBool(task.Completed),
+		formatTime(task.CreatedAt),
+		formatTime(task.UpdatedAt),
+		formatDueAt(task.DueAt),
+		task.TimeZone,
+		strconv.Itoa(task.Priority),
+		formatMinutes(task.ReminderMinutes),
+		task.Recurrence,
+		strings.Join(task.Tags, " "),
+	})
+}
+
+// Close writes the header even when there were no tasks, so an empty export
+// is still a valid file.
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	return e.w.Write(csvHeader)
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVDecoder(r io.Reader) *csvDecoder {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	return &csvDecoder{r: reader}
+}
+
+// Decode matches columns by the names in the header row, in any order and
+// case. Only title is required; unknown columns are ignored. Reminder
+// minutes and tags are separated by spaces.
+func (d *csvDecoder) Decode() (*domain.Task, error) {
+	if d.columns == nil {
+		if err := d.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	row, err := d.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, invalidRecord("invalid CSV: %s", err)
+		}
+		return nil, err
+	}
+
+	value := func(column string) string {
+		i, ok := d.columns[column]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	task := &domain.Task{
+		ID:          value("id"),
+		Title:       value("title"),
+		Description: value("description"),
+	}
+	if completed := value("completed"); completed != "" {
+		if task.Completed, err = strconv.ParseBool(completed); err != nil {
+			return nil, invalidRecord("invalid completed %q", completed)
+		}
+	}
+	if task.CreatedAt, err = parseTime(value("created_at")); err != nil {
+		return nil, invalidRecord("invalid created_at %q", value("created_at"))
+	}
+	if task.UpdatedAt, err = parseTime(value("updated_at")); err != nil {
+		return nil, invalidRecord("invalid updated_at %q", value("updated_at"))
+	}
+	if dueAt := value("due_at"); dueAt != "" {
+		due, err := parseTime(dueAt)
+		if err != nil {
+			return nil, invalidRecord("invalid due_at %q", dueAt)
+		}
+		task.DueAt = &due
+	}
+	task.TimeZone = value("time_zone")
+	if priority := value("priority"); priority != "" {
+		if task.Priority, err = strconv.Atoi(priority); err != nil {
+			return nil, invalidRecord("invalid priority %q", priority)
+		}
+	}
+	for _, field := range strings.Fields(value("reminder_minutes")) {
+		minutes, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, invalidRecord("invalid reminder_minutes %q", value("reminder_minutes"))
+		}
+		task.ReminderMinutes = append(task.ReminderMinutes, minutes)
+	}
+	task.Recurrence = value("recurrence")
+	if tags := value("tags"); tags != "" {
+		task.Tags = strings.Fields(tags)
+	}
+	return task, nil
+}
+
+func (d *csvDecoder) readHeader() error {
+	header, err := d.r.Read()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return domain.NewError(domain.ErrValidation, "invalid CSV header: "+err.Error())
+	}
+
+	d.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		d.columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := d.columns["title"]; !ok {
+		return domain.NewError(domain.ErrValidation, "CSV header has no title column")
+	}
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatDueAt(dueAt *time.Time) string {
+	if dueAt == nil {
+		return ""
+	}
+	return formatTime(*dueAt)
+}
+
+func formatMinutes(minutes []int) string {
+	fields := make([]string, len(minutes))
+	for i, m := range minutes {
+		fields[i] = strconv.Itoa(m)
+	}
+	return strings.Join(fields, " ")
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
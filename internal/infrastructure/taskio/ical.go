@@ -0,0 +1,365 @@
+package taskio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list-task/internal/domain"
+)
+
+const (
+	icalTimeLayout      = "20060102T150405Z"
+	icalLocalTimeLayout = "20060102T150405"
+	// icalLineLimit is the longest content line in octets before it is folded (RFC 5545 §3.1).
+	icalLineLimit = 75
+)
+
+var (
+	icalEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+// icalPriorities maps task priorities to iCalendar ones, where 1 is the
+// highest, 9 the lowest and 0 undefined.
+var icalPriorities = map[int]int{
+	domain.PriorityHigh:   1,
+	domain.PriorityMedium: 5,
+	domain.PriorityLow:    9,
+}
+
+type icalendarEncoder struct {
+	w       *bufio.Writer
+	started bool
+	err     error
+}
+
+func newICalendarEncoder(w io.Writer) *icalendarEncoder {
+	return &icalendarEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *icalendarEncoder) Encode(task *domain.Task) error {
+	e.begin()
+	e.line("BEGIN", "VTODO")
+	e.line("UID", task.ID)
+	stamp := task.UpdatedAt
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	e.line("DTSTAMP", stamp.UTC().Format(icalTimeLayout))
+	if !task.CreatedAt.IsZero() {
+		e.line("CREATED", task.CreatedAt.UTC().Format(icalTimeLayout))
+	}
+	if !task.UpdatedAt.IsZero() {
+		e.line("LAST-MODIFIED", task.UpdatedAt.UTC().Format(icalTimeLayout))
+	}
+	e.line("SUMMARY", icalEscaper.Replace(task.Title))
+	e.line("DESCRIPTION", icalEscaper.Replace(task.Description))
+	if task.Completed {
+		e.line("STATUS", "COMPLETED")
+	} else {
+		e.line("STATUS", "NEEDS-ACTION")
+	}
+	if task.DueAt != nil {
+		if task.TimeZone != "" {
+			e.line("DUE;TZID="+task.TimeZone, task.DueAt.In(task.Location()).Format(icalLocalTimeLayout))
+		} else {
+			e.line("DUE", task.DueAt.UTC().Format(icalTimeLayout))
+		}
+	}
+	if task.Recurrence != "" {
+		e.line("RRULE", task.Recurrence)
+	}
+	if priority, ok := icalPriorities[task.Priority]; ok {
+		e.line("PRIORITY", strconv.Itoa(priority))
+	}
+	if len(task.Tags) > 0 {
+		categories := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			categories[i] = icalEscaper.Replace(tag)
+		}
+		e.line("CATEGORIES", strings.Join(categories, ","))
+	}
+	for _, minutes := range task.ReminderMinutes {
+		e.line("BEGIN", "VALARM")
+		e.line("ACTION", "DISPLAY")
+		e.line("DESCRIPTION", icalEscaper.Replace(task.Title))
+		e.line("TRIGGER", fmt.Sprintf("-PT%dM", minutes))
+		e.line("END", "VALARM")
+	}
+	e.line("END", "VTODO")
+	return e.err
+}
+
+func (e *icalendarEncoder) Close() error {
+	e.begin()
+	e.line("END", "VCALENDAR")
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+func (e *icalendarEncoder) begin() {
+	if e.started {
+		return
+	}
+	e.started = true
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", "-//todo-list-task//EN")
+}
+
+// line writes a content line, folding it so no physical line exceeds the
+// octet limit and never splitting a UTF-8 sequence.
+func (e *icalendarEncoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+	content := name + ":" + value
+	limit := icalLineLimit
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(content[cut]) {
+			cut--
+		}
+		if _, e.err = e.w.WriteString(content[:cut] + "\r\n "); e.err != nil {
+			return
+		}
+		content = content[cut:]
+		limit = icalLineLimit - 1
+	}
+	_, e.err = e.w.WriteString(content + "\r\n")
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+type icalendarDecoder struct {
+	scanner *bufio.Scanner
+	pending string
+	hasNext bool
+}
+
+func newICalendarDecoder(r io.Reader) *icalendarDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &icalendarDecoder{scanner: scanner}
+}
+
+// Decode returns the next VTODO of the calendar, ignoring every other
+// component. SUMMARY, DESCRIPTION, STATUS (or COMPLETED), CREATED,
+// LAST-MODIFIED, DUE, RRULE, PRIORITY and CATEGORIES are mapped to the task,
+// UID becomes its ID and the TRIGGER of every VALARM relative to the due date
+// a reminder.
+func (d *icalendarDecoder) Decode() (*domain.Task, error) {
+	var (
+		task    *domain.Task
+		broken  error
+		inAlarm bool
+	)
+
+	for {
+		line, err := d.next()
+		if err != nil {
+			if err == io.EOF && task != nil {
+				return nil, invalidRecord("unterminated VTODO")
+			}
+			return nil, err
+		}
+
+		name, params, value := splitContentLine(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO"):
+			task = &domain.Task{}
+			broken = nil
+			inAlarm = false
+		case task == nil:
+			continue
+		case name == "BEGIN" && strings.EqualFold(value, "VALARM"):
+			inAlarm = true
+		case name == "END" && strings.EqualFold(value, "VALARM"):
+			inAlarm = false
+		case inAlarm:
+			if name == "TRIGGER" && !strings.Contains(strings.ToUpper(params), "RELATED=END") {
+				if minutes, ok := parseTriggerMinutes(value); ok {
+					task.ReminderMinutes = append(task.ReminderMinutes, minutes)
+				}
+			}
+		case name == "END" && strings.EqualFold(value, "VTODO"):
+			if broken != nil {
+				return nil, broken
+			}
+			return task, nil
+		case name == "UID":
+			task.ID = value
+		case name == "SUMMARY":
+			task.Title = icalUnescaper.Replace(value)
+		case name == "DESCRIPTION":
+			task.Description = icalUnescaper.Replace(value)
+		case name == "STATUS":
+			task.Completed = strings.EqualFold(value, "COMPLETED")
+		case name == "COMPLETED":
+			task.Completed = true
+		case name == "CREATED" || name == "LAST-MODIFIED":
+			t, err := parseICalTime(value, params)
+			if err != nil && broken == nil {
+				broken = invalidRecord("invalid %s %q", name, value)
+			}
+			if name == "CREATED" {
+				task.CreatedAt = t
+			} else {
+				task.UpdatedAt = t
+			}
+		case name == "DUE":
+			due, err := parseICalTime(value, params)
+			if err != nil && broken == nil {
+				broken = invalidRecord("invalid DUE %q", value)
+			}
+			task.DueAt = &due
+			task.TimeZone = icalTimeZone(params)
+		case name == "RRULE":
+			task.Recurrence = value
+		case name == "PRIORITY":
+			priority, err := strconv.Atoi(value)
+			if (err != nil || priority < 0 || priority > 9) && broken == nil {
+				broken = invalidRecord("invalid PRIORITY %q", value)
+			}
+			task.Priority = taskPriority(priority)
+		case name == "CATEGORIES":
+			for _, category := range strings.Split(value, ",") {
+				task.Tags = append(task.Tags, icalUnescaper.Replace(category))
+			}
+		}
+	}
+}
+
+// next returns the next unfolded content line.
+func (d *icalendarDecoder) next() (string, error) {
+	for {
+		if !d.hasNext {
+			if !d.scanner.Scan() {
+				if err := d.scanner.Err(); err != nil {
+					return "", err
+				}
+				return "", io.EOF
+			}
+			d.pending = strings.TrimRight(d.scanner.Text(), "\r")
+			d.hasNext = true
+		}
+
+		line := d.pending
+		d.hasNext = false
+		for d.scanner.Scan() {
+			next := strings.TrimRight(d.scanner.Text(), "\r")
+			if strings.HasPrefix(next, " ") || strings.HasPrefix(next, "\t") {
+				line += next[1:]
+				continue
+			}
+			d.pending = next
+			d.hasNext = true
+			break
+		}
+		if err := d.scanner.Err(); err != nil {
+			return "", err
+		}
+		if line != "" {
+			return line, nil
+		}
+	}
+}
+
+// splitContentLine splits "NAME;PARAM=x:value" into its upper-cased name,
+// its raw parameters and its value.
+func splitContentLine(line string) (string, string, string) {
+	colon := strings.IndexByte(line, ':')
+	if colon < 0 {
+		return strings.ToUpper(line), "", ""
+	}
+	name, params, _ := strings.Cut(line[:colon], ";")
+	return strings.ToUpper(name), params, line[colon+1:]
+}
+
+// parseICalTime parses UTC, floating and TZID date-times. Floating and
+// zoned times fall back to UTC when the zone is unknown.
+func parseICalTime(value, params string) (time.Time, error) {
+	location := time.UTC
+	if zone := icalTimeZone(params); zone != "" {
+		location, _ = time.LoadLocation(zone)
+	}
+
+	for _, layout := range []string{icalTimeLayout, icalLocalTimeLayout, "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, invalidRecord("invalid date-time %q", value)
+}
+
+// icalTimeZone returns the TZID parameter when it names a known IANA zone.
+func icalTimeZone(params string) string {
+	for _, param := range strings.Split(params, ";") {
+		if key, zone, ok := strings.Cut(param, "="); ok && strings.EqualFold(key, "TZID") {
+			zone = strings.Trim(zone, `"`)
+			if _, err := time.LoadLocation(zone); err == nil {
+				return zone
+			}
+		}
+	}
+	return ""
+}
+
+// taskPriority maps an iCalendar priority to the closest task priority.
+func taskPriority(priority int) int {
+	switch {
+	case priority == 0:
+		return domain.PriorityNone
+	case priority < 5:
+		return domain.PriorityHigh
+	case priority == 5:
+		return domain.PriorityMedium
+	default:
+		return domain.PriorityLow
+	}
+}
+
+// parseTriggerMinutes parses a negative or zero duration trigger such as
+// -PT15M, -PT2H or -P1D as minutes before the due date.
+func parseTriggerMinutes(value string) (int, bool) {
+	value = strings.ToUpper(value)
+	if value == "PT0S" || value == "-PT0S" || value == "PT0M" {
+		return 0, true
+	}
+	rest, ok := strings.CutPrefix(value, "-P")
+	if !ok {
+		return 0, false
+	}
+
+	minutes := 0
+	unit := map[byte]int{'W': 7 * 24 * 60, 'D': 24 * 60, 'H': 60, 'M': 1}
+	inTime := false
+	number := ""
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+			inTime = true
+		case c == 'S' && inTime:
+			number = ""
+		default:
+			n, err := strconv.Atoi(number)
+			factor, known := unit[c]
+			if err != nil || !known || (c == 'M' || c == 'H') != inTime {
+				return 0, false
+			}
+			minutes += n * factor
+			number = ""
+		}
+	}
+	return minutes, number == ""
+}
@@ -0,0 +1,59 @@
+package taskio
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"todo-list-task/internal/domain"
+)
+
+// maxLineSize bounds a single JSON Lines record.
+const maxLineSize = 1 << 20
+
+type jsonLinesEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLinesEncoder(w io.Writer) *jsonLinesEncoder {
+	buffered := bufio.NewWriter(w)
+	return &jsonLinesEncoder{w: buffered, enc: json.NewEncoder(buffered)}
+}
+
+func (e *jsonLinesEncoder) Encode(task *domain.Task) error {
+	return e.enc.Encode(task)
+}
+
+func (e *jsonLinesEncoder) Close() error {
+	return e.w.Flush()
+}
+
+type jsonLinesDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func newJSONLinesDecoder(r io.Reader) *jsonLinesDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &jsonLinesDecoder{scanner: scanner}
+}
+
+// Decode skips blank lines, so a trailing newline does not count as a record.
+func (d *jsonLinesDecoder) Decode() (*domain.Task, error) {
+	for d.scanner.Scan() {
+		line := strings.TrimSpace(d.scanner.Text())
+		if line == "" {
+			continue
+		}
+		var task domain.Task
+		if err := json.Unmarshal([]byte(line), &task); err != nil {
+			return nil, invalidRecord("invalid JSON: %s", err)
+		}
+		return &task, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,58 @@
+// Package taskio streams tasks to and from the export formats: JSON Lines,
+// CSV and iCalendar VTODO.
+package taskio
+
+import (
+	"fmt"
+	"io"
+	"todo-list-task/internal/domain"
+)
+
+// NewEncoder returns an encoder writing tasks to w in format.
+func NewEncoder(format string, w io.Writer) (domain.TaskEncoder, error) {
+	switch format {
+	case domain.FormatJSONLines:
+		return newJSONLinesEncoder(w), nil
+	case domain.FormatCSV:
+		return newCSVEncoder(w), nil
+	case domain.FormatICalendar:
+		return newICalendarEncoder(w), nil
+	default:
+		return nil, unknownFormat(format)
+	}
+}
+
+// NewDecoder returns a decoder reading tasks in format from r.
+func NewDecoder(format string, r io.Reader) (domain.TaskDecoder, error) {
+	switch format {
+	case domain.FormatJSONLines:
+		return newJSONLinesDecoder(r), nil
+	case domain.FormatCSV:
+		return newCSVDecoder(r), nil
+	case domain.FormatICalendar:
+		return newICalendarDecoder(r), nil
+	default:
+		return nil, unknownFormat(format)
+	}
+}
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	switch format {
+	case domain.FormatCSV:
+		return "text/csv; charset=utf-8"
+	case domain.FormatICalendar:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "application/jsonl; charset=utf-8"
+	}
+}
+
+func unknownFormat(format string) error {
+	return domain.NewError(domain.ErrValidation, fmt.Sprintf("unknown format %q", format))
+}
+
+// invalidRecord reports a record that cannot be parsed.
+func invalidRecord(format string, args ...any) error {
+	return domain.NewError(domain.ErrInvalidRecord, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,200 @@
+package taskio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/taskio"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	firstDue  = time.Date(2024, 1, 5, 17, 30, 0, 0, time.UTC)
+	secondDue = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+var exported = []*domain.Task{
+	{
+		ID:          "1",
+		Title:       "Buy milk",
+		Description: "Semi-skimmed, 2 litres; or oat milk\nif there is none",
+		CreatedAt:   time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+		Tags:        []string{"groceries", "home"},
+		TaskSchedule: domain.TaskSchedule{
+			DueAt:           &firstDue,
+			TimeZone:        "Europe/Madrid",
+			Priority:        domain.PriorityHigh,
+			ReminderMinutes: []int{15, 1440},
+			Recurrence:      "FREQ=WEEKLY;BYDAY=FR",
+		},
+	},
+	{
+		ID:           "2",
+		Title:        "Résumé " + strings.Repeat("ñ", 60),
+		Description:  `Path C:\tmp, "quoted"`,
+		Completed:    true,
+		CreatedAt:    time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC),
+		UpdatedAt:    time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC),
+		TaskSchedule: domain.TaskSchedule{DueAt: &secondDue, Priority: domain.PriorityLow},
+	},
+}
+
+func encode(t *testing.T, format string, tasks []*domain.Task) string {
+	var buf bytes.Buffer
+	enc, err := taskio.NewEncoder(format, &buf)
+	require.NoError(t, err)
+	for _, task := range tasks {
+		require.NoError(t, enc.Encode(task))
+	}
+	require.NoError(t, enc.Close())
+	return buf.String()
+}
+
+func decodeAll(t *testing.T, format, data string) ([]*domain.Task, []error) {
+	dec, err := taskio.NewDecoder(format, strings.NewReader(data))
+	require.NoError(t, err)
+
+	var (
+		tasks []*domain.Task
+		errs  []error
+	)
+	for {
+		task, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			return tasks, errs
+		}
+		if err != nil {
+			require.ErrorIs(t, err, domain.ErrInvalidRecord)
+			errs = append(errs, err)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{domain.FormatJSONLines, domain.FormatCSV, domain.FormatICalendar} {
+		t.Run(format, func(t *testing.T) {
+			tasks, errs := decodeAll(t, format, encode(t, format, exported))
+
+			assert.Empty(t, errs)
+			require.Len(t, tasks, len(exported))
+			for i, task := range tasks {
+				assert.Equal(t, exported[i].ID, task.ID)
+				assert.Equal(t, exported[i].Title, task.Title)
+				assert.Equal(t, exported[i].Description, task.Description)
+				assert.Equal(t, exported[i].Completed, task.Completed)
+				assert.Equal(t, exported[i].CreatedAt, task.CreatedAt)
+				assert.Equal(t, exported[i].UpdatedAt, task.UpdatedAt)
+				assert.Equal(t, exported[i].Tags, task.Tags)
+				assert.Equal(t, exported[i].TaskSchedule, task.TaskSchedule)
+			}
+		})
+	}
+}
+
+func TestEmptyExport(t *testing.T) {
+	assert.Equal(t, "", encode(t, domain.FormatJSONLines, nil))
+	assert.Equal(t, "id,title,description,completed,created_at,updated_at,due_at,time_zone,priority,reminder_minutes,recurrence,tags\n", encode(t, domain.FormatCSV, nil))
+	assert.Equal(t, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//todo-list-task//EN\r\nEND:VCALENDAR\r\n", encode(t, domain.FormatICalendar, nil))
+}
+
+func TestICalendarEncoder_FoldsLongLines(t *testing.T) {
+	data := encode(t, domain.FormatICalendar, exported)
+
+	for _, line := range strings.Split(data, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, data, `DESCRIPTION:Semi-skimmed\, 2 litres\; or oat milk\nif there is none`)
+	assert.Contains(t, data, "DUE;TZID=Europe/Madrid:20240105T183000\r\n")
+	assert.Contains(t, data, "TRIGGER:-PT1440M\r\n")
+}
+
+func TestICalendarDecoder_IgnoresOtherComponents(t *testing.T) {
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:Meeting",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"SUMMARY;LANGUAGE=en:Water the",
+		"  plants",
+		"DESCRIPTION:Balcony",
+		"CREATED;TZID=Europe/Madrid:20240101T100000",
+		"DUE:20240103T100000Z",
+		"PRIORITY:2",
+		"BEGIN:VALARM",
+		"DESCRIPTION:Not the task description",
+		"TRIGGER:-P1DT2H",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"TRIGGER;RELATED=END:PT0S",
+		"END:VALARM",
+		"COMPLETED:20240102T100000Z",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Broken",
+		"CREATED:yesterday",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\n")
+
+	tasks, errs := decodeAll(t, domain.FormatICalendar, data)
+
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Water the plants", tasks[0].Title)
+	assert.Equal(t, "Balcony", tasks[0].Description)
+	assert.True(t, tasks[0].Completed)
+	assert.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), tasks[0].CreatedAt)
+	require.NotNil(t, tasks[0].DueAt)
+	assert.Equal(t, time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC), *tasks[0].DueAt)
+	assert.Equal(t, domain.PriorityHigh, tasks[0].Priority)
+	assert.Equal(t, []int{26 * 60}, tasks[0].ReminderMinutes)
+	assert.Len(t, errs, 1)
+}
+
+func TestCSVDecoder(t *testing.T) {
+	t.Run("Should match columns by name and report invalid rows", func(t *testing.T) {
+		data := "\ufeffCompleted,Title,Description,notes\ntrue,Milk,Buy milk,x\nmaybe,Bread,Buy bread,y\nfalse,Eggs,Buy eggs,z\n"
+
+		tasks, errs := decodeAll(t, domain.FormatCSV, data)
+
+		require.Len(t, tasks, 2)
+		assert.Equal(t, "Milk", tasks[0].Title)
+		assert.True(t, tasks[0].Completed)
+		assert.Equal(t, "Eggs", tasks[1].Title)
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "maybe")
+	})
+
+	t.Run("Should fail without a title column", func(t *testing.T) {
+		dec, err := taskio.NewDecoder(domain.FormatCSV, strings.NewReader("name,description\nMilk,Buy milk\n"))
+		require.NoError(t, err)
+
+		_, err = dec.Decode()
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		assert.NotErrorIs(t, err, domain.ErrInvalidRecord)
+	})
+}
+
+func TestJSONLinesDecoder_ReportsInvalidLines(t *testing.T) {
+	tasks, errs := decodeAll(t, domain.FormatJSONLines, "{\"title\":\"Milk\"}\n\nnot json\n{\"title\":\"Eggs\",\"completed\":true}\n")
+
+	require.Len(t, tasks, 2)
+	assert.True(t, tasks[1].Completed)
+	assert.Len(t, errs, 1)
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := taskio.NewEncoder("xml", io.Discard)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	_, err = taskio.NewDecoder("xml", strings.NewReader(""))
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
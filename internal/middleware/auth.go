@@ -1,26 +1,119 @@
 package middleware
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"strings"
+	"todo-list-task/internal/domain"
 	"todo-list-task/internal/utils"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+const (
+	// UserIDKey is the gin context key holding the authenticated user ID.
+	UserIDKey = "userID"
+	// UsernameKey is the gin context key holding the authenticated username.
+	UsernameKey = "username"
+	// RoleKey is the gin context key holding the authenticated user role.
+	RoleKey = "role"
+)
+
+// UserLookup returns the current state of the account a token was issued to.
+type UserLookup interface {
+	Get(id string) (*domain.User, error)
+}
+
+// AuthMiddleware rejects requests without a valid bearer token and stores the
+// authenticated user in the gin context. The account is looked up on every
+// request, so disabling it or changing its role takes effect immediately
+// instead of when its access tokens expire.
+func AuthMiddleware(jwt *utils.JWTManager, users UserLookup) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 		if tokenString == "" {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
+			AbortWithProblem(c, http.StatusUnauthorized, "missing bearer token")
 			return
 		}
 
-		_, err := utils.ValidateJWT(tokenString)
+		parsed, err := jwt.ValidateJWT(tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
+			AbortWithProblem(c, http.StatusUnauthorized, "invalid bearer token")
 			return
 		}
-		c.Next()
+
+		authenticate(c, users, parsed.(*utils.Claims).UserID)
 	}
 }
+
+// TicketRedeemer exchanges a single-use stream ticket for the ID of the user
+// it was issued to.
+type TicketRedeemer interface {
+	Redeem(ticket string) (string, bool)
+}
+
+// StreamAuthMiddleware authenticates the task stream endpoints. It accepts a
+// stream ticket in the ticket query parameter, since browsers cannot set the
+// Authorization header of EventSource and WebSocket requests, and otherwise
+// works like AuthMiddleware.
+func StreamAuthMiddleware(jwt *utils.JWTManager, users UserLookup, tickets TicketRedeemer) gin.HandlerFunc {
+	bearer := AuthMiddleware(jwt, users)
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			bearer(c)
+			return
+		}
+
+		userID, ok := tickets.Redeem(ticket)
+		if !ok {
+			AbortWithProblem(c, http.StatusUnauthorized, "invalid stream ticket")
+			return
+		}
+		authenticate(c, users, userID)
+	}
+}
+
+// authenticate loads the account of userID, rejects the request when it is
+// unknown or disabled, and otherwise stores the user in the gin context and
+// runs the next handlers.
+func authenticate(c *gin.Context, users UserLookup, userID string) {
+	user, err := users.Get(userID)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		AbortWithProblem(c, http.StatusUnauthorized, "unknown user")
+		return
+	case err != nil:
+		AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	case user.Disabled:
+		AbortWithProblem(c, http.StatusUnauthorized, domain.ErrUserDisabled.Error())
+		return
+	}
+
+	c.Set(UserIDKey, user.ID)
+	c.Set(UsernameKey, user.Username)
+	c.Set(RoleKey, user.Role)
+	c.Next()
+}
+
+// RequireRole rejects authenticated requests whose role is not one of roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(RoleKey)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		AbortWithProblem(c, http.StatusForbidden, "insufficient role")
+	}
+}
+
+// CurrentUserID returns the ID of the authenticated user stored by AuthMiddleware.
+func CurrentUserID(c *gin.Context) string {
+	return c.GetString(UserIDKey)
+}
@@ -0,0 +1,106 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/middleware"
+	"todo-list-task/internal/utils"
+	"todo-list-task/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var jwtManager = utils.NewJWTManager("test-secret", time.Hour)
+
+func newRouter() *gin.Engine {
+	users := new(mocks.UserRepository)
+	users.On("Get", "user-1").Return(&domain.User{ID: "user-1", Username: "cristianm", Role: domain.RoleUser}, nil)
+	users.On("Get", "user-2").Return(&domain.User{ID: "user-2", Username: "root", Role: domain.RoleAdmin}, nil)
+	users.On("Get", "user-3").Return(&domain.User{ID: "user-3", Username: "mallory", Role: domain.RoleAdmin, Disabled: true}, nil)
+	users.On("Get", mock.Anything).Return(nil, domain.ErrUserNotFound)
+
+	router := gin.Default()
+	auth := middleware.AuthMiddleware(jwtManager, users)
+	router.GET("/tasks", auth, func(c *gin.Context) {
+		c.String(http.StatusOK, middleware.CurrentUserID(c))
+	})
+	router.GET("/admin", auth, middleware.RequireRole("admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func request(router *gin.Engine, route, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", route, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	router := newRouter()
+	token, _ := jwtManager.GenerateJWT("user-1", "cristianm", "user")
+
+	resp := request(router, "/tasks", token)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "user-1", resp.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/tasks", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/tasks", "invalid").Code)
+}
+
+func TestAuthMiddleware_ChecksAccount(t *testing.T) {
+	router := newRouter()
+
+	disabled, _ := jwtManager.GenerateJWT("user-3", "mallory", "admin")
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/tasks", disabled).Code)
+
+	unknown, _ := jwtManager.GenerateJWT("user-9", "ghost", "user")
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/tasks", unknown).Code)
+
+	// The stored role wins over the role a token was issued with.
+	stale, _ := jwtManager.GenerateJWT("user-1", "cristianm", "admin")
+	assert.Equal(t, http.StatusForbidden, request(router, "/admin", stale).Code)
+}
+
+func TestStreamAuthMiddleware(t *testing.T) {
+	users := new(mocks.UserRepository)
+	users.On("Get", "user-1").Return(&domain.User{ID: "user-1", Username: "cristianm", Role: domain.RoleUser}, nil)
+	users.On("Get", "user-3").Return(&domain.User{ID: "user-3", Username: "mallory", Role: domain.RoleUser, Disabled: true}, nil)
+	tickets := new(mocks.TicketRedeemer)
+	tickets.On("Redeem", "good").Return("user-1", true)
+	tickets.On("Redeem", "disabled").Return("user-3", true)
+	tickets.On("Redeem", mock.Anything).Return("", false)
+
+	router := gin.Default()
+	router.GET("/tasks/stream", middleware.StreamAuthMiddleware(jwtManager, users, tickets), func(c *gin.Context) {
+		c.String(http.StatusOK, middleware.CurrentUserID(c))
+	})
+	token, _ := jwtManager.GenerateJWT("user-1", "cristianm", "user")
+
+	resp := request(router, "/tasks/stream?ticket=good", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "user-1", resp.Body.String())
+	assert.Equal(t, http.StatusOK, request(router, "/tasks/stream", token).Code, "a bearer token still works")
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/tasks/stream?ticket=bad", token).Code, "a ticket is not retried as a token")
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/tasks/stream?ticket=disabled", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/tasks/stream", "").Code)
+}
+
+func TestRequireRole(t *testing.T) {
+	router := newRouter()
+	userToken, _ := jwtManager.GenerateJWT("user-1", "cristianm", "user")
+	adminToken, _ := jwtManager.GenerateJWT("user-2", "root", "admin")
+
+	assert.Equal(t, http.StatusForbidden, request(router, "/admin", userToken).Code)
+	assert.Equal(t, http.StatusOK, request(router, "/admin", adminToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, request(router, "/admin", "").Code)
+}
@@ -0,0 +1,84 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorStatus(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status int
+	}{
+		{err: domain.ErrInvalidQuery, status: http.StatusBadRequest},
+		{err: domain.ErrInvalidPatch, status: http.StatusBadRequest},
+		{err: domain.ErrInvalidCredentials, status: http.StatusUnauthorized},
+		{err: domain.ErrInvalidRefreshToken, status: http.StatusUnauthorized},
+		{err: domain.ErrRefreshTokenReused, status: http.StatusUnauthorized},
+		{err: domain.ErrUserDisabled, status: http.StatusForbidden},
+		{err: domain.ErrTaskNotFound, status: http.StatusNotFound},
+		{err: domain.ErrUserNotFound, status: http.StatusNotFound},
+		{err: domain.ErrUsernameTaken, status: http.StatusConflict},
+		{err: domain.ErrPatchTestFailed, status: http.StatusConflict},
+		{err: domain.ErrVersionConflict, status: http.StatusPreconditionFailed},
+		{err: fmt.Errorf("loading task: %w", domain.ErrTaskNotFound), status: http.StatusNotFound},
+		{err: errors.New("disk full"), status: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tc.status, middleware.ErrorStatus(tc.err))
+		})
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		_ = c.Error(domain.ErrTaskNotFound)
+	})
+	router.GET("/boom", func(c *gin.Context) {
+		_ = c.Error(errors.New("connection refused"))
+	})
+	router.GET("/ok", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	t.Run("Should render domain errors as problem details", func(t *testing.T) {
+		resp := request(router, "/tasks/1", "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Equal(t, middleware.ProblemContentType, resp.Header().Get("Content-Type"))
+
+		var problem middleware.Problem
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+		assert.Equal(t, middleware.Problem{
+			Type:     "about:blank",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "task not found",
+			Instance: "/tasks/1",
+		}, problem)
+	})
+
+	t.Run("Should hide the detail of internal errors", func(t *testing.T) {
+		resp := request(router, "/boom", "")
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+		assert.NotContains(t, resp.Body.String(), "connection refused")
+	})
+
+	t.Run("Should leave successful responses untouched", func(t *testing.T) {
+		resp := request(router, "/ok", "")
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Empty(t, resp.Body.String())
+	})
+}
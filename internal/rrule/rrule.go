@@ -0,0 +1,482 @@
+// Package rrule parses and expands RFC 5545 recurrence rules.
+//
+// The supported parts are FREQ (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL,
+// COUNT, UNTIL, BYMONTH, BYMONTHDAY, BYDAY and WKST. Occurrences keep the
+// wall-clock time of DTSTART in its location, so a rule starting at 09:00
+// stays at 09:00 across daylight saving transitions. A wall-clock time that
+// falls in a DST gap is moved forward by the length of the gap, as RFC 5545
+// prescribes, and dates that do not exist, such as the 31st of a shorter
+// month, are skipped.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRule is wrapped by every error returned by Parse.
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Frequency is the FREQ of a rule.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+const (
+	untilLayout     = "20060102T150405Z"
+	untilDateLayout = "20060102"
+
+	// maxEmptyPeriods bounds the search for the next occurrence of a rule
+	// that matches rarely or never, such as FREQ=DAILY;BYMONTH=2;BYMONTHDAY=30.
+	maxEmptyPeriods = 5000
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY value: a weekday, optionally preceded by the
+// position of the weekday within the month or year (1 is the first, -1 the
+// last). N is zero for every occurrence of the weekday.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed recurrence rule. Interval is at least 1. Count and Until
+// are mutually exclusive; zero values mean the rule does not end.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	WeekStart  time.Weekday
+
+	// untilDate is set when UNTIL is a date; it then includes the whole day
+	// in the location of DTSTART.
+	untilDate bool
+}
+
+// Parse parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10". An
+// "RRULE:" prefix is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || name == "" || val == "" {
+			return nil, invalid("malformed part %q", part)
+		}
+		if seen[name] {
+			return nil, invalid("duplicate %s", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(val)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly && rule.Freq != Yearly {
+				err = invalid("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(name, val)
+		case "COUNT":
+			rule.Count, err = parsePositive(name, val)
+		case "UNTIL":
+			err = rule.parseUntil(val)
+		case "BYMONTH":
+			err = parseList(name, val, func(n int) error {
+				if n < 1 || n > 12 {
+					return invalid("BYMONTH %d out of range", n)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+				return nil
+			})
+		case "BYMONTHDAY":
+			err = parseList(name, val, func(n int) error {
+				if n == 0 || n < -31 || n > 31 {
+					return invalid("BYMONTHDAY %d out of range", n)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+				return nil
+			})
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				day, dayErr := parseWeekdayNum(item)
+				if dayErr != nil {
+					err = dayErr
+					break
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "WKST":
+			day, ok := weekdays[val]
+			if !ok {
+				err = invalid("invalid WKST %q", val)
+			}
+			rule.WeekStart = day
+		default:
+			err = invalid("unsupported part %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, invalid("FREQ is required")
+	}
+	if rule.Count != 0 && !rule.Until.IsZero() {
+		return nil, invalid("COUNT and UNTIL cannot be combined")
+	}
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return nil, invalid("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly && rule.Freq != Yearly {
+			return nil, invalid("BYDAY positions require FREQ=MONTHLY or FREQ=YEARLY")
+		}
+		if day.N != 0 && rule.Freq == Monthly && (day.N < -5 || day.N > 5) {
+			return nil, invalid("BYDAY position %d out of range", day.N)
+		}
+	}
+	return rule, nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRule, fmt.Sprintf(format, args...))
+}
+
+func parsePositive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, invalid("%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+func parseList(name, value string, add func(int) error) error {
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return invalid("invalid %s %q", name, item)
+		}
+		if err := add(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	if len(value) < 2 {
+		return WeekdayNum{}, invalid("invalid BYDAY %q", value)
+	}
+	day, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, invalid("invalid BYDAY %q", value)
+	}
+	result := WeekdayNum{Weekday: day}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, invalid("invalid BYDAY %q", value)
+		}
+		result.N = n
+	}
+	return result, nil
+}
+
+func (r *Rule) parseUntil(value string) error {
+	if t, err := time.Parse(untilLayout, value); err == nil {
+		r.Until = t
+		return nil
+	}
+	if t, err := time.Parse(untilDateLayout, value); err == nil {
+		r.Until = t
+		r.untilDate = true
+		return nil
+	}
+	return invalid("UNTIL must be a UTC date-time or a date, got %q", value)
+}
+
+// String formats the rule in a canonical order, without the "RRULE:" prefix.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.untilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+		}
+	}
+	if len(r.ByMonth) > 0 {
+		items := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			items[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(items, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		items := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			items[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(items, ","))
+	}
+	if len(r.ByDay) > 0 {
+		items := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			items[i] = weekdayName(day.Weekday)
+			if day.N != 0 {
+				items[i] = strconv.Itoa(day.N) + items[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(items, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayName(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func weekdayName(day time.Weekday) string {
+	for name, weekday := range weekdays {
+		if weekday == day {
+			return name
+		}
+	}
+	return ""
+}
+
+// Occurrences yields the occurrences of the rule starting at dtstart, in
+// order. DTSTART is the first occurrence only when it matches the rule.
+// The sequence ends after COUNT occurrences or past UNTIL, and is otherwise
+// unbounded.
+func (r Rule) Occurrences(dtstart time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		interval := max(r.Interval, 1)
+		hour, minute, sec := dtstart.Clock()
+		start := civil(dtstart)
+		count, empty := 0, 0
+
+		for period := 0; empty < maxEmptyPeriods; period += interval {
+			found := false
+			for _, day := range r.candidates(start, period) {
+				occurrence := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, sec, dtstart.Nanosecond(), dtstart.Location())
+				if occurrence.Before(dtstart) {
+					continue
+				}
+				if r.pastUntil(occurrence) {
+					return
+				}
+				found = true
+				count++
+				if !yield(occurrence) || (r.Count > 0 && count >= r.Count) {
+					return
+				}
+			}
+			if found {
+				empty = 0
+			} else {
+				empty++
+			}
+		}
+	}
+}
+
+// After returns the first occurrence of the rule starting at dtstart that is
+// strictly after t.
+func (r Rule) After(dtstart, t time.Time) (time.Time, bool) {
+	for occurrence := range r.Occurrences(dtstart) {
+		if occurrence.After(t) {
+			return occurrence, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r Rule) pastUntil(occurrence time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.untilDate {
+		return civil(occurrence).After(r.Until.Add(12 * time.Hour))
+	}
+	return occurrence.After(r.Until)
+}
+
+// civil returns the calendar date of t as noon UTC, so that date arithmetic
+// never crosses a daylight saving transition.
+func civil(t time.Time) time.Time {
+	return date(t.Year(), t.Month(), t.Day())
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func daysIn(year int, month time.Month) int {
+	return date(year, month+1, 0).Day()
+}
+
+// candidates returns the sorted dates of the given period of the rule,
+// counted in units of its frequency from the period of start.
+func (r Rule) candidates(start time.Time, period int) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := start.AddDate(0, 0, period)
+		if r.matchesDay(day) {
+			days = append(days, day)
+		}
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := start.AddDate(0, 0, 7*period-offset)
+		for i := range 7 {
+			day := weekStart.AddDate(0, 0, i)
+			if r.matchesWeekday(day, start) && r.matchesMonth(day) {
+				days = append(days, day)
+			}
+		}
+	case Monthly:
+		month := date(start.Year(), start.Month()+time.Month(period), 1)
+		if r.matchesMonth(month) {
+			days = r.monthDays(month.Year(), month.Month(), start)
+		}
+	case Yearly:
+		year := start.Year() + period
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, month := range r.ByMonth {
+				days = append(days, r.monthDays(year, month, start)...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for month := time.January; month <= time.December; month++ {
+				days = append(days, r.monthDays(year, month, start)...)
+			}
+		case len(r.ByDay) > 0:
+			days = r.weekdaysIn(date(year, time.January, 1), date(year, time.December, 31))
+		default:
+			if start.Day() <= daysIn(year, start.Month()) {
+				days = append(days, date(year, start.Month(), start.Day()))
+			}
+		}
+	}
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(days, func(a, b time.Time) bool { return a.Equal(b) })
+}
+
+// monthDays expands BYMONTHDAY and BYDAY within a month, or returns the day
+// of month of start when neither is set. When both are set BYDAY limits the
+// days of BYMONTHDAY.
+func (r Rule) monthDays(year int, month time.Month, start time.Time) []time.Time {
+	last := daysIn(year, month)
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if start.Day() > last {
+			return nil
+		}
+		return []time.Time{date(year, month, start.Day())}
+	}
+
+	var days []time.Time
+	if len(r.ByMonthDay) > 0 {
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last + day + 1
+			}
+			if day >= 1 && day <= last {
+				days = append(days, date(year, month, day))
+			}
+		}
+		if len(r.ByDay) > 0 {
+			days = slices.DeleteFunc(days, func(day time.Time) bool { return !r.matchesWeekday(day, start) })
+		}
+		return days
+	}
+	return r.weekdaysIn(date(year, month, 1), date(year, month, last))
+}
+
+// weekdaysIn returns the days between first and last (inclusive) selected by
+// BYDAY, resolving positions relative to that range.
+func (r Rule) weekdaysIn(first, last time.Time) []time.Time {
+	var days []time.Time
+	for _, byDay := range r.ByDay {
+		var matching []time.Time
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == byDay.Weekday {
+				matching = append(matching, day)
+			}
+		}
+		switch {
+		case byDay.N == 0:
+			days = append(days, matching...)
+		case byDay.N > 0 && byDay.N <= len(matching):
+			days = append(days, matching[byDay.N-1])
+		case byDay.N < 0 && -byDay.N <= len(matching):
+			days = append(days, matching[len(matching)+byDay.N])
+		}
+	}
+	return days
+}
+
+func (r Rule) matchesDay(day time.Time) bool {
+	if !r.matchesMonth(day) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		last := daysIn(day.Year(), day.Month())
+		if !slices.ContainsFunc(r.ByMonthDay, func(n int) bool {
+			return n == day.Day() || (n < 0 && last+n+1 == day.Day())
+		}) {
+			return false
+		}
+	}
+	return len(r.ByDay) == 0 || r.matchesWeekday(day, day)
+}
+
+// matchesWeekday reports whether day is one of the BYDAY weekdays, or the
+// weekday of start when BYDAY is not set.
+func (r Rule) matchesWeekday(day, start time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return day.Weekday() == start.Weekday()
+	}
+	return slices.ContainsFunc(r.ByDay, func(byDay WeekdayNum) bool { return byDay.Weekday == day.Weekday() })
+}
+
+func (r Rule) matchesMonth(day time.Time) bool {
+	return len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, day.Month())
+}
@@ -0,0 +1,204 @@
+package rrule_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/rrule"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return location
+}
+
+func occurrences(t *testing.T, value string, dtstart time.Time, limit int) []time.Time {
+	rule, err := rrule.Parse(value)
+	require.NoError(t, err)
+
+	var result []time.Time
+	for occurrence := range rule.Occurrences(dtstart) {
+		result = append(result, occurrence)
+		if len(result) == limit {
+			break
+		}
+	}
+	return result
+}
+
+func dates(times []time.Time) []string {
+	result := make([]string, len(times))
+	for i, t := range times {
+		result[i] = t.Format("2006-01-02 15:04 MST")
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		value     string
+		canonical string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;byday=mo,we;interval=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", "FREQ=MONTHLY;COUNT=3;BYDAY=-1FR"},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1;UNTIL=20301231T000000Z", "FREQ=YEARLY;UNTIL=20301231T000000Z;BYMONTH=2;BYMONTHDAY=-1"},
+		{"FREQ=WEEKLY;UNTIL=20260601;WKST=SU;INTERVAL=1", "FREQ=WEEKLY;UNTIL=20260601;WKST=SU"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.value, func(t *testing.T) {
+			rule, err := rrule.Parse(testCase.value)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.canonical, rule.String())
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101T000000Z",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=DAILY;COUNT",
+	} {
+		t.Run(value, func(t *testing.T) {
+			_, err := rrule.Parse(value)
+
+			assert.ErrorIs(t, err, rrule.ErrInvalidRule)
+		})
+	}
+}
+
+func TestOccurrences_KeepWallClockAcrossDST(t *testing.T) {
+	madrid := mustLoad(t, "Europe/Madrid")
+
+	// Summer time starts on 2026-03-29 in Madrid.
+	result := occurrences(t, "FREQ=WEEKLY;COUNT=3", time.Date(2026, 3, 23, 9, 0, 0, 0, madrid), 10)
+
+	assert.Equal(t, []string{"2026-03-23 09:00 CET", "2026-03-30 09:00 CEST", "2026-04-06 09:00 CEST"}, dates(result))
+	assert.Equal(t, 7*24*time.Hour-time.Hour, result[1].Sub(result[0]))
+
+	// Winter time starts on 2026-11-01 in New York.
+	newYork := mustLoad(t, "America/New_York")
+	result = occurrences(t, "FREQ=DAILY", time.Date(2026, 10, 31, 18, 30, 0, 0, newYork), 2)
+
+	assert.Equal(t, []string{"2026-10-31 18:30 EDT", "2026-11-01 18:30 EST"}, dates(result))
+	assert.Equal(t, 25*time.Hour, result[1].Sub(result[0]))
+}
+
+func TestOccurrences_SkipForwardInDSTGap(t *testing.T) {
+	madrid := mustLoad(t, "Europe/Madrid")
+
+	result := occurrences(t, "FREQ=DAILY;COUNT=3", time.Date(2026, 3, 28, 2, 30, 0, 0, madrid), 10)
+
+	assert.Equal(t, []string{"2026-03-28 02:30 CET", "2026-03-29 03:30 CEST", "2026-03-30 02:30 CEST"}, dates(result))
+}
+
+func TestOccurrences_MonthEnd(t *testing.T) {
+	start := time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		expected []string
+	}{
+		{
+			name:     "should skip months without the day",
+			rule:     "FREQ=MONTHLY",
+			start:    start,
+			expected: []string{"2026-01-31", "2026-03-31", "2026-05-31", "2026-07-31"},
+		},
+		{
+			name:     "should use the last day of every month",
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start:    start,
+			expected: []string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"},
+		},
+		{
+			name:     "should find the last friday of every month",
+			rule:     "FREQ=MONTHLY;BYDAY=-1FR",
+			start:    start,
+			expected: []string{"2026-02-27", "2026-03-27", "2026-04-24", "2026-05-29"},
+		},
+		{
+			name:     "should only repeat leap days in leap years",
+			rule:     "FREQ=YEARLY",
+			start:    time.Date(2028, 2, 29, 10, 0, 0, 0, time.UTC),
+			expected: []string{"2028-02-29", "2032-02-29", "2036-02-29", "2040-02-29"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := occurrences(t, testCase.rule, testCase.start, 4)
+
+			days := make([]string, len(result))
+			for i, occurrence := range result {
+				days[i] = occurrence.Format(time.DateOnly)
+			}
+			assert.Equal(t, testCase.expected, days)
+		})
+	}
+}
+
+func TestOccurrences_Termination(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		rule     string
+		expected int
+	}{
+		{name: "should stop after COUNT occurrences", rule: "FREQ=DAILY;COUNT=4", expected: 4},
+		{name: "should count only matching days", rule: "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=5", expected: 5},
+		{name: "should include an UNTIL date-time", rule: "FREQ=DAILY;UNTIL=20260105T090000Z", expected: 5},
+		{name: "should stop before an earlier UNTIL time", rule: "FREQ=DAILY;UNTIL=20260105T085959Z", expected: 4},
+		{name: "should include the whole UNTIL date", rule: "FREQ=WEEKLY;UNTIL=20260129", expected: 5},
+		{name: "should give up on rules that never match", rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", expected: 0},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Len(t, occurrences(t, testCase.rule, start, 100), testCase.expected)
+		})
+	}
+}
+
+func TestOccurrences_IntervalAndWeekdays(t *testing.T) {
+	// 2026-01-07 is a Wednesday.
+	start := time.Date(2026, 1, 7, 9, 0, 0, 0, time.UTC)
+
+	result := occurrences(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", start, 5)
+
+	days := make([]string, len(result))
+	for i, occurrence := range result {
+		days[i] = occurrence.Format(time.DateOnly)
+	}
+	assert.Equal(t, []string{"2026-01-07", "2026-01-19", "2026-01-21", "2026-02-02", "2026-02-04"}, days)
+}
+
+func TestRule_After(t *testing.T) {
+	rule, err := rrule.Parse("FREQ=MONTHLY;BYMONTHDAY=15;COUNT=2")
+	require.NoError(t, err)
+	start := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	next, ok := rule.After(start, start)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, 2, 15, 9, 0, 0, 0, time.UTC), next)
+
+	_, ok = rule.After(start, next)
+	assert.False(t, ok)
+}
@@ -0,0 +1,199 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"todo-list-task/internal/domain"
+)
+
+const (
+	// titleWeight is how much more a word of the title counts than a word of
+	// the description.
+	titleWeight = 2
+	// prefixWeight is how much a word matched by prefix counts next to an
+	// exact match.
+	prefixWeight = 0.5
+)
+
+// Result is a task matching a search, with its relevance score.
+type Result struct {
+	Task  *domain.Task `json:"task"`
+	Score float64      `json:"score"`
+}
+
+// Index is an inverted index over the titles and descriptions of tasks,
+// partitioned by owner. It is safe for concurrent use.
+type Index struct {
+	mu     sync.RWMutex
+	owners map[string]*ownerIndex
+	// ownerOf maps a task ID to its owner, so tasks can be removed by ID.
+	ownerOf map[string]string
+}
+
+// ownerIndex indexes the tasks of one owner. vocabulary holds the indexed
+// words sorted, so the words starting with a prefix are a contiguous range.
+type ownerIndex struct {
+	docs       map[string]*document
+	postings   map[string]map[string]posting
+	vocabulary []string
+}
+
+type document struct {
+	task  *domain.Task
+	words []string
+}
+
+// posting counts the occurrences of a word in the title and the
+// description of a task.
+type posting struct {
+	title, description int
+}
+
+func NewIndex() *Index {
+	return &Index{owners: make(map[string]*ownerIndex), ownerOf: make(map[string]string)}
+}
+
+// Put indexes a task, replacing the previous version of it. An older version
+// than the one indexed, from a write that finished late, is ignored.
+func (x *Index) Put(task *domain.Task) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if ownerID, ok := x.ownerOf[task.ID]; ok && x.owners[ownerID].docs[task.ID].task.Version > task.Version {
+		return
+	}
+	x.remove(task.ID)
+	owner := x.owners[task.OwnerID]
+	if owner == nil {
+		owner = &ownerIndex{docs: make(map[string]*document), postings: make(map[string]map[string]posting)}
+		x.owners[task.OwnerID] = owner
+	}
+
+	counts := make(map[string]posting)
+	for _, word := range Tokenize(task.Title) {
+		p := counts[word]
+		p.title++
+		counts[word] = p
+	}
+	for _, word := range Tokenize(task.Description) {
+		p := counts[word]
+		p.description++
+		counts[word] = p
+	}
+
+	copied := *task
+	doc := &document{task: &copied, words: make([]string, 0, len(counts))}
+	for word, p := range counts {
+		doc.words = append(doc.words, word)
+		postings := owner.postings[word]
+		if postings == nil {
+			postings = make(map[string]posting)
+			owner.postings[word] = postings
+			i := sort.SearchStrings(owner.vocabulary, word)
+			owner.vocabulary = append(owner.vocabulary, "")
+			copy(owner.vocabulary[i+1:], owner.vocabulary[i:])
+			owner.vocabulary[i] = word
+		}
+		postings[task.ID] = p
+	}
+	owner.docs[task.ID] = doc
+	x.ownerOf[task.ID] = task.OwnerID
+}
+
+// Remove drops a task from the index. Unknown IDs are ignored.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+}
+
+func (x *Index) remove(id string) {
+	ownerID, ok := x.ownerOf[id]
+	if !ok {
+		return
+	}
+	delete(x.ownerOf, id)
+	owner := x.owners[ownerID]
+	for _, word := range owner.docs[id].words {
+		delete(owner.postings[word], id)
+		if len(owner.postings[word]) == 0 {
+			delete(owner.postings, word)
+			i := sort.SearchStrings(owner.vocabulary, word)
+			owner.vocabulary = append(owner.vocabulary[:i], owner.vocabulary[i+1:]...)
+		}
+	}
+	delete(owner.docs, id)
+	if len(owner.docs) == 0 {
+		delete(x.owners, ownerID)
+	}
+}
+
+// Search returns up to limit tasks of the owner matching every term of q,
+// most relevant first. A term matches the words it equals or starts with;
+// exact matches and words of the title weigh more, and rare words more than
+// common ones. Ties are broken by the most recently updated task.
+func (x *Index) Search(ownerID, q string, limit int) []Result {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	terms := queryTerms(q)
+	owner := x.owners[ownerID]
+	if owner == nil || len(terms) == 0 {
+		return []Result{}
+	}
+
+	var scores map[string]float64
+	for _, term := range terms {
+		termScores := owner.score(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{Task: owner.docs[id].task, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Task.UpdatedAt.Equal(b.Task.UpdatedAt) {
+			return a.Task.UpdatedAt.After(b.Task.UpdatedAt)
+		}
+		return a.Task.ID < b.Task.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// score returns the score of term for every task with a word matching it.
+func (o *ownerIndex) score(term string) map[string]float64 {
+	scores := make(map[string]float64)
+	for i := sort.SearchStrings(o.vocabulary, term); i < len(o.vocabulary) && strings.HasPrefix(o.vocabulary[i], term); i++ {
+		word := o.vocabulary[i]
+		postings := o.postings[word]
+		weight := math.Log(1 + float64(len(o.docs))/float64(len(postings)))
+		if word != term {
+			weight *= prefixWeight
+		}
+		for id, p := range postings {
+			scores[id] += weight * float64(titleWeight*p.title+p.description)
+		}
+	}
+	return scores
+}
@@ -0,0 +1,136 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/search"
+	"todo-list-task/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "Comprar LECHE y café", expected: []string{"comprar", "leche", "cafe"}},
+		{text: "Canción de cumpleaños: ¡ñandú!", expected: []string{"cancion", "cumpleanos", "nandu"}},
+		{text: "Fix the login-page (v2) for iOS", expected: []string{"fix", "login", "page", "v2", "ios"}},
+		{text: "a, y... o", expected: nil},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.text, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, search.Tokenize(testCase.text))
+		})
+	}
+}
+
+func searchIDs(index *search.Index, ownerID, q string) []string {
+	ids := []string{}
+	for _, result := range index.Search(ownerID, q, 10) {
+		ids = append(ids, result.Task.ID)
+	}
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	index := search.NewIndex()
+	for i, task := range []*domain.Task{
+		{ID: "1", OwnerID: "user-1", Title: "Comprar leche", Description: "Leche de avena"},
+		{ID: "2", OwnerID: "user-1", Title: "Llamar al médico", Description: "Pedir cita para la leche de fórmula"},
+		{ID: "3", OwnerID: "user-1", Title: "Deploy backend", Description: "Release the new API"},
+		{ID: "4", OwnerID: "user-1", Title: "Backup", Description: "Copy the database"},
+		{ID: "5", OwnerID: "user-2", Title: "Comprar leche", Description: "Entera"},
+	} {
+		task.UpdatedAt = updated.Add(time.Duration(i) * time.Hour)
+		index.Put(task)
+	}
+
+	testCases := []struct {
+		name     string
+		q        string
+		expected []string
+	}{
+		{name: "should rank title matches first", q: "leche", expected: []string{"1", "2"}},
+		{name: "should fold accents in the query", q: "MEDICO", expected: []string{"2"}},
+		{name: "should fold accents in the text", q: "formula", expected: []string{"2"}},
+		{name: "should match every term", q: "leche avena", expected: []string{"1"}},
+		{name: "should match words by prefix", q: "back", expected: []string{"4", "3"}},
+		{name: "should rank exact matches above prefix matches", q: "backup", expected: []string{"4"}},
+		{name: "should match the last term as it is typed", q: "deploy b", expected: []string{"3"}},
+		{name: "should ignore stop words", q: "the backend", expected: []string{"3"}},
+		{name: "should return nothing when a term matches nothing", q: "leche pan", expected: []string{}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, searchIDs(index, "user-1", testCase.q))
+		})
+	}
+}
+
+func TestIndex_PutAndRemove(t *testing.T) {
+	index := search.NewIndex()
+	index.Put(&domain.Task{ID: "1", OwnerID: "user-1", Title: "Comprar leche", Version: 1})
+	index.Put(&domain.Task{ID: "1", OwnerID: "user-1", Title: "Comprar pan", Version: 2})
+
+	assert.Equal(t, []string{}, searchIDs(index, "user-1", "leche"))
+	assert.Equal(t, []string{"1"}, searchIDs(index, "user-1", "pan"))
+
+	index.Put(&domain.Task{ID: "1", OwnerID: "user-1", Title: "Comprar leche", Version: 1})
+	assert.Equal(t, []string{"1"}, searchIDs(index, "user-1", "pan"), "an older version must not replace a newer one")
+
+	index.Remove("1")
+	assert.Equal(t, []string{}, searchIDs(index, "user-1", "comprar"))
+	index.Remove("1")
+}
+
+func TestIndexedTaskRepository(t *testing.T) {
+	repo := new(mocks.TaskRepository)
+	index := search.NewIndex()
+	indexed := search.NewIndexedTaskRepository(repo, index)
+
+	repo.On("GetTasks", "user-1").Return([]*domain.Task{{ID: "1", OwnerID: "user-1", Title: "Comprar leche", Version: 1}}, nil)
+	require.NoError(t, indexed.Rebuild([]string{"user-1"}))
+	assert.Equal(t, []string{"1"}, searchIDs(index, "user-1", "leche"))
+
+	created := &domain.Task{ID: "2", OwnerID: "user-1", Title: "Pagar la luz", Version: 1}
+	repo.On("CreateTask", created).Return(created, nil)
+	_, err := indexed.CreateTask(created)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, searchIDs(index, "user-1", "luz"))
+
+	repo.On("UpdateTask", "user-1", "2", mock.Anything).Return(&domain.Task{ID: "2", OwnerID: "user-1", Title: "Pagar el agua", Version: 2}, nil)
+	_, err = indexed.UpdateTask("user-1", "2", &domain.Task{Title: "Pagar el agua"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, searchIDs(index, "user-1", "luz"))
+	assert.Equal(t, []string{"2"}, searchIDs(index, "user-1", "agua"))
+
+	repo.On("UpdateTask", "user-1", "1", mock.Anything).Return(nil, domain.ErrVersionConflict)
+	_, err = indexed.UpdateTask("user-1", "1", &domain.Task{Title: "Comprar pan"})
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	assert.Equal(t, []string{"1"}, searchIDs(index, "user-1", "leche"))
+
+	changes := []domain.TaskChange{
+		{Op: domain.BatchDelete, ID: "1"},
+		{Op: domain.BatchComplete, ID: "missing"},
+		{Op: domain.BatchUpdate, ID: "2"},
+	}
+	repo.On("ApplyTaskChanges", "user-1", changes, false).Return([]domain.TaskChangeResult{
+		{},
+		{Err: domain.ErrTaskNotFound},
+		{Task: &domain.Task{ID: "2", OwnerID: "user-1", Title: "Pagar el gas", Version: 3}},
+	}, nil)
+	_, err = indexed.ApplyTaskChanges("user-1", changes, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, searchIDs(index, "user-1", "leche"))
+	assert.Equal(t, []string{"2"}, searchIDs(index, "user-1", "gas"))
+
+	repo.On("DeleteTask", "user-1", "2", "user-1").Return(nil)
+	require.NoError(t, indexed.DeleteTask("user-1", "2", "user-1"))
+	assert.Equal(t, []string{}, searchIDs(index, "user-1", "pagar"))
+}
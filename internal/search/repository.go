@@ -0,0 +1,88 @@
+package search
+
+import (
+	"todo-list-task/internal/domain"
+	"todo-list-task/internal/infrastructure/repository"
+)
+
+// IndexedTaskRepository is a TaskRepository that keeps an Index in sync with
+// every successful write of the repository it wraps. Reads go straight to the
+// wrapped repository.
+type IndexedTaskRepository struct {
+	repository.TaskRepository
+	index *Index
+}
+
+func NewIndexedTaskRepository(repo repository.TaskRepository, index *Index) *IndexedTaskRepository {
+	return &IndexedTaskRepository{TaskRepository: repo, index: index}
+}
+
+// Rebuild indexes every task of the given owners from scratch. It runs at
+// startup, before the repository takes writes.
+func (r *IndexedTaskRepository) Rebuild(ownerIDs []string) error {
+	for _, ownerID := range ownerIDs {
+		tasks, err := r.TaskRepository.GetTasks(ownerID)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			r.index.Put(task)
+		}
+	}
+	return nil
+}
+
+func (r *IndexedTaskRepository) CreateTask(task *domain.Task) (*domain.Task, error) {
+	created, err := r.TaskRepository.CreateTask(task)
+	if err != nil {
+		return nil, err
+	}
+	r.index.Put(created)
+	return created, nil
+}
+
+func (r *IndexedTaskRepository) UpdateTask(ownerID, id string, task *domain.Task) (*domain.Task, error) {
+	updated, err := r.TaskRepository.UpdateTask(ownerID, id, task)
+	if err != nil {
+		return nil, err
+	}
+	r.index.Put(updated)
+	return updated, nil
+}
+
+func (r *IndexedTaskRepository) DeleteTask(ownerID, id, actorID string) error {
+	if err := r.TaskRepository.DeleteTask(ownerID, id, actorID); err != nil {
+		return err
+	}
+	r.index.Remove(id)
+	return nil
+}
+
+func (r *IndexedTaskRepository) RestoreTask(ownerID, id, actorID string) (*domain.Task, error) {
+	restored, err := r.TaskRepository.RestoreTask(ownerID, id, actorID)
+	if err != nil {
+		return nil, err
+	}
+	r.index.Put(restored)
+	return restored, nil
+}
+
+func (r *IndexedTaskRepository) ApplyTaskChanges(ownerID string, changes []domain.TaskChange, atomic bool) ([]domain.TaskChangeResult, error) {
+	results, err := r.TaskRepository.ApplyTaskChanges(ownerID, changes, atomic)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		switch {
+		case result.Err != nil:
+		case result.Task == nil:
+			r.index.Remove(changes[i].ID)
+		default:
+			r.index.Put(result.Task)
+		}
+		if result.Occurrence != nil {
+			r.index.Put(result.Occurrence)
+		}
+	}
+	return results, nil
+}
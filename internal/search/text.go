@@ -0,0 +1,66 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// minTokenLength is the shortest token that is indexed.
+const minTokenLength = 2
+
+// stopWords are frequent Spanish and English words, folded, that carry no
+// meaning on their own and are left out of the index.
+var stopWords = map[string]bool{
+	"al": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"con": true, "de": true, "del": true, "el": true, "en": true, "es": true, "for": true, "from": true,
+	"in": true, "is": true, "it": true, "la": true, "las": true, "lo": true, "los": true, "mas": true,
+	"no": true, "of": true, "on": true, "or": true, "para": true, "pero": true, "por": true, "que": true,
+	"se": true, "su": true, "sus": true, "the": true, "to": true, "un": true, "una": true, "with": true,
+}
+
+// Fold lowercases s and strips its diacritics, so "Canción" and "cancion"
+// are the same word.
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return norm.NFC.String(b.String())
+}
+
+// Tokenize splits s into folded words, dropping stop words and words shorter
+// than two characters.
+func Tokenize(s string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(Fold(s), isSeparator) {
+		if len([]rune(word)) >= minTokenLength && !stopWords[word] {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// queryTerms splits a search query into folded terms. The last term is kept
+// even when it is a stop word or a single character, since it is usually a
+// word still being typed.
+func queryTerms(q string) []string {
+	words := strings.FieldsFunc(Fold(q), isSeparator)
+	var terms []string
+	for i, word := range words {
+		last := i == len(words)-1
+		if last || len([]rune(word)) >= minTokenLength && !stopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
@@ -6,26 +6,60 @@ import (
 	"time"
 )
 
+// Claims represents the JWT payload issued to an authenticated user.
 type Claims struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT() (string, error) {
+// JWTManager issues tokens signed with the active key of a KeyRing and
+// validates them against any of its non-retired keys.
+type JWTManager struct {
+	keys *KeyRing
+	ttl  time.Duration
+}
+
+// NewJWTManager builds a manager that signs with a single HS256 shared secret.
+func NewJWTManager(secret string, ttl time.Duration) *JWTManager {
+	keys, _ := NewKeyRing("hs256", NewHMACKey("hs256", secret))
+	return NewJWTManagerWithKeys(keys, ttl)
+}
+
+// NewJWTManagerWithKeys builds a manager on top of a KeyRing.
+func NewJWTManagerWithKeys(keys *KeyRing, ttl time.Duration) *JWTManager {
+	return &JWTManager{
+		keys: keys,
+		ttl:  ttl,
+	}
+}
+
+// Keys returns the key ring used by the manager.
+func (m *JWTManager) Keys() *KeyRing {
+	return m.keys
+}
+
+// GenerateJWT issues a signed token whose subject is the given user.
+func (m *JWTManager) GenerateJWT(userID, username, role string) (string, error) {
 	claims := Claims{
+		UserID:   userID,
+		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 1)),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.ttl)),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, _ := token.SignedString([]byte("secret"))
+	active := m.keys.active
+	token := jwt.NewWithClaims(active.Method, claims)
+	token.Header["kid"] = active.ID
 
-	return ss, nil
+	return token.SignedString(active.private)
 }
 
-func ValidateJWT(token string) (interface{}, error) {
-	tokenClaims, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte("secret"), nil
-	})
+func (m *JWTManager) ValidateJWT(token string) (interface{}, error) {
+	tokenClaims, err := jwt.ParseWithClaims(token, &Claims{}, m.keys.verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -36,5 +70,9 @@ func ValidateJWT(token string) (interface{}, error) {
 		return nil, fmt.Errorf("token inválido")
 	}
 
+	if claims.UserID == "" {
+		return nil, fmt.Errorf("token sin usuario")
+	}
+
 	return claims, nil
 }
@@ -8,15 +8,28 @@ import (
 	"todo-list-task/internal/utils"
 )
 
+var jwtManager = utils.NewJWTManager("secret", time.Hour)
+
 func TestGenerateJWT(t *testing.T) {
-	token, err := utils.GenerateJWT()
+	token, err := jwtManager.GenerateJWT("user-1", "cristianm", "admin")
 
 	assert.NotEmpty(t, token)
 	assert.NoError(t, err)
+
+	claims, err := jwtManager.ValidateJWT(token)
+
+	assert.NoError(t, err)
+	castedClaims, ok := claims.(*utils.Claims)
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", castedClaims.UserID)
+	assert.Equal(t, "user-1", castedClaims.Subject)
+	assert.Equal(t, "cristianm", castedClaims.Username)
+	assert.Equal(t, "admin", castedClaims.Role)
 }
 
 func generateValidJWT(secret string, expires time.Duration) (string, error) {
 	claims := utils.Claims{
+		UserID: "user-1",
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expires)),
 		},
@@ -32,7 +45,7 @@ func TestValidateJWT_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tokenString)
 
-	claims, err := utils.ValidateJWT(tokenString)
+	claims, err := jwtManager.ValidateJWT(tokenString)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, claims)
@@ -48,7 +61,7 @@ func TestValidateJWT_InvalidSignature(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tokenString)
 
-	claims, err := utils.ValidateJWT(tokenString)
+	claims, err := jwtManager.ValidateJWT(tokenString)
 
 	assert.Error(t, err)
 	assert.Nil(t, claims)
@@ -62,9 +75,38 @@ func TestValidateJWT_ExpiredToken(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tokenString)
 
-	claims, err := utils.ValidateJWT(tokenString)
+	claims, err := jwtManager.ValidateJWT(tokenString)
 
 	assert.Error(t, err)
 	assert.Nil(t, claims)
 	assert.Contains(t, err.Error(), "token is expired")
 }
+
+func TestValidateJWT_MissingUser(t *testing.T) {
+	claims := utils.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	parsed, err := jwtManager.ValidateJWT(tokenString)
+
+	assert.Error(t, err)
+	assert.Nil(t, parsed)
+}
+
+func TestGenerateJWT_UsesConfiguredTTL(t *testing.T) {
+	manager := utils.NewJWTManager("another-secret", 15*time.Minute)
+
+	token, err := manager.GenerateJWT("user-1", "cristianm", "user")
+	assert.NoError(t, err)
+
+	claims, err := manager.ValidateJWT(token)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), claims.(*utils.Claims).ExpiresAt.Time, time.Minute)
+
+	_, err = jwtManager.ValidateJWT(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SigningKey is a key of the KeyRing identified by the kid header of the tokens it signs.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Retired bool
+	private interface{}
+	public  interface{}
+}
+
+// NewHMACKey builds a symmetric HS256 key from a shared secret.
+func NewHMACKey(id, secret string) *SigningKey {
+	return &SigningKey{
+		ID:      id,
+		Method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+	}
+}
+
+// NewAsymmetricKey builds a key from an RSA, ECDSA or Ed25519 private key,
+// picking RS256, ES256/ES384/ES512 or EdDSA from the key type.
+func NewAsymmetricKey(id string, private crypto.Signer) (*SigningKey, error) {
+	key := &SigningKey{ID: id, private: private, public: private.Public()}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			key.Method = jwt.SigningMethodES256
+		case elliptic.P384():
+			key.Method = jwt.SigningMethodES384
+		case elliptic.P521():
+			key.Method = jwt.SigningMethodES512
+		default:
+			return nil, fmt.Errorf("key %s: unsupported curve %s", id, k.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("key %s: unsupported key type %T", id, private)
+	}
+	return key, nil
+}
+
+// ParsePrivateKeyPEM decodes a PKCS#8, PKCS#1 or SEC 1 PEM private key.
+func ParsePrivateKeyPEM(id string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", id)
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key %s: unsupported key type %T", id, parsed)
+	}
+	return NewAsymmetricKey(id, signer)
+}
+
+// KeyRing holds the key used to sign new tokens plus every key still
+// accepted when validating them. Retired keys are kept only so tokens
+// signed with them are explicitly rejected and left out of the JWKS.
+type KeyRing struct {
+	keys   map[string]*SigningKey
+	active *SigningKey
+}
+
+// NewKeyRing builds a ring that signs with the key identified by active.
+func NewKeyRing(active string, keys ...*SigningKey) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, ok := ring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicated key id %s", key.ID)
+		}
+		ring.keys[key.ID] = key
+	}
+
+	ring.active = ring.keys[active]
+	if ring.active == nil {
+		return nil, fmt.Errorf("active key %s not found", active)
+	}
+	if ring.active.Retired {
+		return nil, fmt.Errorf("active key %s is retired", active)
+	}
+	return ring, nil
+}
+
+// LoadKeyRing reads every *.pem private key in dir, using the file name
+// without extension as the key id.
+func LoadKeyRing(dir, active string, retired []string) (*KeyRing, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no PEM keys found in %s", dir)
+	}
+
+	isRetired := make(map[string]bool, len(retired))
+	for _, id := range retired {
+		isRetired[id] = true
+	}
+
+	keys := make([]*SigningKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := ParsePrivateKeyPEM(id, data)
+		if err != nil {
+			return nil, err
+		}
+		key.Retired = isRetired[id]
+		keys = append(keys, key)
+	}
+	return NewKeyRing(active, keys...)
+}
+
+// verificationKey returns the key a token must be verified with. Tokens
+// without a kid header are checked against the active key.
+func (r *KeyRing) verificationKey(token *jwt.Token) (interface{}, error) {
+	key := r.active
+	if kid, ok := token.Header["kid"].(string); ok {
+		key = r.keys[kid]
+	}
+	if key == nil {
+		return nil, errors.New("unknown signing key")
+	}
+	if key.Retired {
+		return nil, fmt.Errorf("signing key %s is retired", key.ID)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public part of every non-retired asymmetric key.
+func (r *KeyRing) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	for _, key := range r.keys {
+		if key.Retired {
+			continue
+		}
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeBase64URL(public.N.Bytes())
+			jwk.E = encodeBase64URL(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = public.Curve.Params().Name
+			jwk.X = encodeBase64URL(public.X.FillBytes(make([]byte, size)))
+			jwk.Y = encodeBase64URL(public.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encodeBase64URL(public)
+		default:
+			// Symmetric keys must never be published.
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
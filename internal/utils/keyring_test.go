@@ -0,0 +1,168 @@
+package utils_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-list-task/internal/utils"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T, dir, id string, key crypto.Signer) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id+".pem"), data, 0o600))
+}
+
+func generateKeys(t *testing.T) (crypto.Signer, crypto.Signer, crypto.Signer) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return rsaKey, ecKey, edKey
+}
+
+func TestKeyRing_SignsWithEveryAlgorithm(t *testing.T) {
+	rsaKey, ecKey, edKey := generateKeys(t)
+
+	testCases := []struct {
+		name string
+		key  crypto.Signer
+		alg  string
+	}{
+		{name: "RSA", key: rsaKey, alg: "RS256"},
+		{name: "ECDSA", key: ecKey, alg: "ES256"},
+		{name: "Ed25519", key: edKey, alg: "EdDSA"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := utils.NewAsymmetricKey("k1", tc.key)
+			require.NoError(t, err)
+			ring, err := utils.NewKeyRing("k1", key)
+			require.NoError(t, err)
+			manager := utils.NewJWTManagerWithKeys(ring, time.Hour)
+
+			token, err := manager.GenerateJWT("user-1", "cristianm", "user")
+			require.NoError(t, err)
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, &utils.Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.alg, parsed.Header["alg"])
+			assert.Equal(t, "k1", parsed.Header["kid"])
+
+			claims, err := manager.ValidateJWT(token)
+			assert.NoError(t, err)
+			assert.Equal(t, "user-1", claims.(*utils.Claims).UserID)
+		})
+	}
+}
+
+func TestLoadKeyRing_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, _, edKey := generateKeys(t)
+	writeKey(t, dir, "old", rsaKey)
+	writeKey(t, dir, "new", edKey)
+
+	before, err := utils.LoadKeyRing(dir, "old", nil)
+	require.NoError(t, err)
+	oldToken, err := utils.NewJWTManagerWithKeys(before, time.Hour).GenerateJWT("user-1", "cristianm", "user")
+	require.NoError(t, err)
+
+	rotated, err := utils.LoadKeyRing(dir, "new", nil)
+	require.NoError(t, err)
+	manager := utils.NewJWTManagerWithKeys(rotated, time.Hour)
+
+	_, err = manager.ValidateJWT(oldToken)
+	assert.NoError(t, err, "tokens signed with a previous, non-retired key are still valid")
+
+	retired, err := utils.LoadKeyRing(dir, "new", []string{"old"})
+	require.NoError(t, err)
+	manager = utils.NewJWTManagerWithKeys(retired, time.Hour)
+
+	_, err = manager.ValidateJWT(oldToken)
+	assert.Error(t, err, "tokens signed with a retired key are rejected")
+
+	newToken, err := manager.GenerateJWT("user-1", "cristianm", "user")
+	require.NoError(t, err)
+	_, err = manager.ValidateJWT(newToken)
+	assert.NoError(t, err)
+}
+
+func TestLoadKeyRing_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := utils.LoadKeyRing(dir, "k1", nil)
+	assert.Error(t, err, "empty directory")
+
+	_, _, edKey := generateKeys(t)
+	writeKey(t, dir, "k1", edKey)
+
+	_, err = utils.LoadKeyRing(dir, "missing", nil)
+	assert.Error(t, err, "unknown active key")
+
+	_, err = utils.LoadKeyRing(dir, "k1", []string{"k1"})
+	assert.Error(t, err, "retired active key")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+	_, err = utils.LoadKeyRing(dir, "k1", nil)
+	assert.Error(t, err, "malformed PEM")
+}
+
+func TestKeyRing_RejectsUnknownKid(t *testing.T) {
+	_, ecKey, edKey := generateKeys(t)
+	signingKey, err := utils.NewAsymmetricKey("other", ecKey)
+	require.NoError(t, err)
+	otherRing, err := utils.NewKeyRing("other", signingKey)
+	require.NoError(t, err)
+	token, err := utils.NewJWTManagerWithKeys(otherRing, time.Hour).GenerateJWT("user-1", "cristianm", "user")
+	require.NoError(t, err)
+
+	key, err := utils.NewAsymmetricKey("k1", edKey)
+	require.NoError(t, err)
+	ring, err := utils.NewKeyRing("k1", key)
+	require.NoError(t, err)
+
+	_, err = utils.NewJWTManagerWithKeys(ring, time.Hour).ValidateJWT(token)
+	assert.Error(t, err)
+}
+
+func TestKeyRing_JWKS(t *testing.T) {
+	rsaKey, ecKey, edKey := generateKeys(t)
+	rsaSigning, _ := utils.NewAsymmetricKey("rsa", rsaKey)
+	ecSigning, _ := utils.NewAsymmetricKey("ec", ecKey)
+	edSigning, _ := utils.NewAsymmetricKey("ed", edKey)
+	edSigning.Retired = true
+
+	ring, err := utils.NewKeyRing("rsa", rsaSigning, ecSigning, edSigning, utils.NewHMACKey("hmac", "s3cr3t"))
+	require.NoError(t, err)
+
+	set := ring.JWKS()
+
+	require.Len(t, set.Keys, 2, "retired and symmetric keys are not published")
+	assert.Equal(t, "ec", set.Keys[0].Kid)
+	assert.Equal(t, "EC", set.Keys[0].Kty)
+	assert.Equal(t, "P-256", set.Keys[0].Crv)
+	assert.Equal(t, "ES256", set.Keys[0].Alg)
+	assert.NotEmpty(t, set.Keys[0].X)
+	assert.NotEmpty(t, set.Keys[0].Y)
+	assert.Equal(t, "rsa", set.Keys[1].Kid)
+	assert.Equal(t, "RSA", set.Keys[1].Kty)
+	assert.Equal(t, "AQAB", set.Keys[1].E)
+	assert.NotEmpty(t, set.Keys[1].N)
+}
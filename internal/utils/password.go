@@ -2,23 +2,24 @@ package utils
 
 import (
 	"todo-list-task/internal/infrastructure/repository"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type DefaultAppCrypto struct {
 	crypto repository.AppCrypto
+	cost   int
 }
 
-func NewHashPassword(crypto repository.AppCrypto) *DefaultAppCrypto {
+// NewHashPassword builds a DefaultAppCrypto that hashes with the given bcrypt cost.
+func NewHashPassword(crypto repository.AppCrypto, cost int) *DefaultAppCrypto {
 	return &DefaultAppCrypto{
 		crypto: crypto,
+		cost:   cost,
 	}
 }
 
 // HashPassword generate a hash for password with bcrypt.
 func (a DefaultAppCrypto) HashPassword(password string) (string, error) {
-	bytes, err := a.crypto.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	bytes, err := a.crypto.GenerateFromPassword([]byte(password), a.cost)
 	return string(bytes), err
 }
 
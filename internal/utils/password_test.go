@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type valuesTestCases struct {
@@ -37,9 +38,9 @@ func TestPassword(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mockPassword := new(mocks.AppCrypto)
 
-			mockPassword.On("GenerateFromPassword", mock.Anything, mock.Anything).Return([]byte(test.hashPassword), test.err)
+			mockPassword.On("GenerateFromPassword", mock.Anything, bcrypt.DefaultCost).Return([]byte(test.hashPassword), test.err)
 
-			passwordUtils := utils.NewHashPassword(mockPassword)
+			passwordUtils := utils.NewHashPassword(mockPassword, bcrypt.DefaultCost)
 
 			password, err := passwordUtils.HashPassword(test.password)
 
@@ -76,7 +77,7 @@ func TestCheckPasswordHash(t *testing.T) {
 
 			mockPassword.On("CompareHashAndPassword", mock.Anything, mock.Anything).Return(test.err)
 
-			passwordUtils := utils.NewHashPassword(mockPassword)
+			passwordUtils := utils.NewHashPassword(mockPassword, bcrypt.DefaultCost)
 
 			result := passwordUtils.CheckPasswordHash(test.password, test.hashPassword)
 
@@ -0,0 +1,202 @@
+// Code generated by mockery v2.53.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "todo-list-task/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ListRepository is an autogenerated mock type for the ListRepository type
+type ListRepository struct {
+	mock.Mock
+}
+
+// CreateList provides a mock function with given fields: list
+func (_m *ListRepository) CreateList(list *domain.List) (*domain.List, error) {
+	ret := _m.Called(list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateList")
+	}
+
+	var r0 *domain.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*domain.List) (*domain.List, error)); ok {
+		return rf(list)
+	}
+	if rf, ok := ret.Get(0).(func(*domain.List) *domain.List); ok {
+		r0 = rf(list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*domain.List) error); ok {
+		r1 = rf(list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteList provides a mock function with given fields: id
+func (_m *ListRepository) DeleteList(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteList")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetList provides a mock function with given fields: id
+func (_m *ListRepository) GetList(id string) (*domain.List, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetList")
+	}
+
+	var r0 *domain.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*domain.List, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) *domain.List); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetListsForUser provides a mock function with given fields: userID
+func (_m *ListRepository) GetListsForUser(userID string) ([]*domain.List, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetListsForUser")
+	}
+
+	var r0 []*domain.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*domain.List, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*domain.List); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: listID, userID
+func (_m *ListRepository) RemoveMember(listID string, userID string) error {
+	ret := _m.Called(listID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(listID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetMember provides a mock function with given fields: listID, member
+func (_m *ListRepository) SetMember(listID string, member domain.ListMember) error {
+	ret := _m.Called(listID, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, domain.ListMember) error); ok {
+		r0 = rf(listID, member)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateList provides a mock function with given fields: list
+func (_m *ListRepository) UpdateList(list *domain.List) (*domain.List, error) {
+	ret := _m.Called(list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateList")
+	}
+
+	var r0 *domain.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*domain.List) (*domain.List, error)); ok {
+		return rf(list)
+	}
+	if rf, ok := ret.Get(0).(func(*domain.List) *domain.List); ok {
+		r0 = rf(list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*domain.List) error); ok {
+		r1 = rf(list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewListRepository creates a new instance of ListRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewListRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ListRepository {
+	mock := &ListRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.53.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "todo-list-task/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TaskDecoder is an autogenerated mock type for the TaskDecoder type
+type TaskDecoder struct {
+	mock.Mock
+}
+
+// Decode provides a mock function with no fields
+func (_m *TaskDecoder) Decode() (*domain.Task, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decode")
+	}
+
+	var r0 *domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*domain.Task, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *domain.Task); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTaskDecoder creates a new instance of TaskDecoder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTaskDecoder(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TaskDecoder {
+	mock := &TaskDecoder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,76 @@
+// Code generated by mockery v2.53.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "todo-list-task/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TaskOutbox is an autogenerated mock type for the TaskOutbox type
+type TaskOutbox struct {
+	mock.Mock
+}
+
+// AckEvents provides a mock function with given fields: ids
+func (_m *TaskOutbox) AckEvents(ids []string) error {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AckEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PendingEvents provides a mock function with given fields: limit
+func (_m *TaskOutbox) PendingEvents(limit int) ([]domain.TaskEvent, error) {
+	ret := _m.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PendingEvents")
+	}
+
+	var r0 []domain.TaskEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]domain.TaskEvent, error)); ok {
+		return rf(limit)
+	}
+	if rf, ok := ret.Get(0).(func(int) []domain.TaskEvent); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TaskEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTaskOutbox creates a new instance of TaskOutbox. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTaskOutbox(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TaskOutbox {
+	mock := &TaskOutbox{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
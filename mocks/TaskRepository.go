@@ -6,6 +6,8 @@ import (
 	domain "todo-list-task/internal/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // TaskRepository is an autogenerated mock type for the TaskRepository type
@@ -13,6 +15,54 @@ type TaskRepository struct {
 	mock.Mock
 }
 
+// AckEvents provides a mock function with given fields: ids
+func (_m *TaskRepository) AckEvents(ids []string) error {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AckEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ApplyTaskChanges provides a mock function with given fields: ownerID, changes, atomic
+func (_m *TaskRepository) ApplyTaskChanges(ownerID string, changes []domain.TaskChange, atomic bool) ([]domain.TaskChangeResult, error) {
+	ret := _m.Called(ownerID, changes, atomic)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyTaskChanges")
+	}
+
+	var r0 []domain.TaskChangeResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []domain.TaskChange, bool) ([]domain.TaskChangeResult, error)); ok {
+		return rf(ownerID, changes, atomic)
+	}
+	if rf, ok := ret.Get(0).(func(string, []domain.TaskChange, bool) []domain.TaskChangeResult); ok {
+		r0 = rf(ownerID, changes, atomic)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TaskChangeResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []domain.TaskChange, bool) error); ok {
+		r1 = rf(ownerID, changes, atomic)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateTask provides a mock function with given fields: task
 func (_m *TaskRepository) CreateTask(task *domain.Task) (*domain.Task, error) {
 	ret := _m.Called(task)
@@ -43,17 +93,17 @@ func (_m *TaskRepository) CreateTask(task *domain.Task) (*domain.Task, error) {
 	return r0, r1
 }
 
-// DeleteTask provides a mock function with given fields: id
-func (_m *TaskRepository) DeleteTask(id string) error {
-	ret := _m.Called(id)
+// DeleteTask provides a mock function with given fields: ownerID, id, actorID
+func (_m *TaskRepository) DeleteTask(ownerID string, id string, actorID string) error {
+	ret := _m.Called(ownerID, id, actorID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteTask")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(ownerID, id, actorID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -61,9 +111,39 @@ func (_m *TaskRepository) DeleteTask(id string) error {
 	return r0
 }
 
-// GetTask provides a mock function with given fields: id
-func (_m *TaskRepository) GetTask(id string) (*domain.Task, error) {
-	ret := _m.Called(id)
+// GetTagCounts provides a mock function with given fields: ownerID
+func (_m *TaskRepository) GetTagCounts(ownerID string) ([]domain.TagCount, error) {
+	ret := _m.Called(ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTagCounts")
+	}
+
+	var r0 []domain.TagCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]domain.TagCount, error)); ok {
+		return rf(ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []domain.TagCount); ok {
+		r0 = rf(ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TagCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTask provides a mock function with given fields: ownerID, id
+func (_m *TaskRepository) GetTask(ownerID string, id string) (*domain.Task, error) {
+	ret := _m.Called(ownerID, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetTask")
@@ -71,19 +151,19 @@ func (_m *TaskRepository) GetTask(id string) (*domain.Task, error) {
 
 	var r0 *domain.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*domain.Task, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(string, string) (*domain.Task, error)); ok {
+		return rf(ownerID, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *domain.Task); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(string, string) *domain.Task); ok {
+		r0 = rf(ownerID, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(ownerID, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -91,9 +171,9 @@ func (_m *TaskRepository) GetTask(id string) (*domain.Task, error) {
 	return r0, r1
 }
 
-// GetTasks provides a mock function with no fields
-func (_m *TaskRepository) GetTasks() ([]*domain.Task, error) {
-	ret := _m.Called()
+// GetTasks provides a mock function with given fields: ownerID
+func (_m *TaskRepository) GetTasks(ownerID string) ([]*domain.Task, error) {
+	ret := _m.Called(ownerID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetTasks")
@@ -101,19 +181,49 @@ func (_m *TaskRepository) GetTasks() ([]*domain.Task, error) {
 
 	var r0 []*domain.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*domain.Task, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(string) ([]*domain.Task, error)); ok {
+		return rf(ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*domain.Task); ok {
+		r0 = rf(ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTasksDueBetween provides a mock function with given fields: from, to
+func (_m *TaskRepository) GetTasksDueBetween(from time.Time, to time.Time) ([]*domain.Task, error) {
+	ret := _m.Called(from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTasksDueBetween")
+	}
+
+	var r0 []*domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time) ([]*domain.Task, error)); ok {
+		return rf(from, to)
 	}
-	if rf, ok := ret.Get(0).(func() []*domain.Task); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time) []*domain.Task); ok {
+		r0 = rf(from, to)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*domain.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(time.Time, time.Time) error); ok {
+		r1 = rf(from, to)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -121,9 +231,175 @@ func (_m *TaskRepository) GetTasks() ([]*domain.Task, error) {
 	return r0, r1
 }
 
-// UpdateTask provides a mock function with given fields: id, task
-func (_m *TaskRepository) UpdateTask(id string, task *domain.Task) (*domain.Task, error) {
-	ret := _m.Called(id, task)
+// GetTrash provides a mock function with given fields: ownerID
+func (_m *TaskRepository) GetTrash(ownerID string) ([]*domain.Task, error) {
+	ret := _m.Called(ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrash")
+	}
+
+	var r0 []*domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*domain.Task, error)); ok {
+		return rf(ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*domain.Task); ok {
+		r0 = rf(ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PendingEvents provides a mock function with given fields: limit
+func (_m *TaskRepository) PendingEvents(limit int) ([]domain.TaskEvent, error) {
+	ret := _m.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PendingEvents")
+	}
+
+	var r0 []domain.TaskEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]domain.TaskEvent, error)); ok {
+		return rf(limit)
+	}
+	if rf, ok := ret.Get(0).(func(int) []domain.TaskEvent); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TaskEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeTrash provides a mock function with given fields: before
+func (_m *TaskRepository) PurgeTrash(before time.Time) (int, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeTrash")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryTasks provides a mock function with given fields: query
+func (_m *TaskRepository) QueryTasks(query domain.TaskQuery) (*domain.TaskPage, error) {
+	ret := _m.Called(query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryTasks")
+	}
+
+	var r0 *domain.TaskPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(domain.TaskQuery) (*domain.TaskPage, error)); ok {
+		return rf(query)
+	}
+	if rf, ok := ret.Get(0).(func(domain.TaskQuery) *domain.TaskPage); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TaskPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(domain.TaskQuery) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreTask provides a mock function with given fields: ownerID, id, actorID
+func (_m *TaskRepository) RestoreTask(ownerID string, id string, actorID string) (*domain.Task, error) {
+	ret := _m.Called(ownerID, id, actorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreTask")
+	}
+
+	var r0 *domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (*domain.Task, error)); ok {
+		return rf(ownerID, id, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) *domain.Task); ok {
+		r0 = rf(ownerID, id, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(ownerID, id, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ScanTasks provides a mock function with given fields: ownerID, fn
+func (_m *TaskRepository) ScanTasks(ownerID string, fn func(*domain.Task) error) error {
+	ret := _m.Called(ownerID, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScanTasks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, func(*domain.Task) error) error); ok {
+		r0 = rf(ownerID, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTask provides a mock function with given fields: ownerID, id, task
+func (_m *TaskRepository) UpdateTask(ownerID string, id string, task *domain.Task) (*domain.Task, error) {
+	ret := _m.Called(ownerID, id, task)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateTask")
@@ -131,19 +407,19 @@ func (_m *TaskRepository) UpdateTask(id string, task *domain.Task) (*domain.Task
 
 	var r0 *domain.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, *domain.Task) (*domain.Task, error)); ok {
-		return rf(id, task)
+	if rf, ok := ret.Get(0).(func(string, string, *domain.Task) (*domain.Task, error)); ok {
+		return rf(ownerID, id, task)
 	}
-	if rf, ok := ret.Get(0).(func(string, *domain.Task) *domain.Task); ok {
-		r0 = rf(id, task)
+	if rf, ok := ret.Get(0).(func(string, string, *domain.Task) *domain.Task); ok {
+		r0 = rf(ownerID, id, task)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, *domain.Task) error); ok {
-		r1 = rf(id, task)
+	if rf, ok := ret.Get(1).(func(string, string, *domain.Task) error); ok {
+		r1 = rf(ownerID, id, task)
 	} else {
 		r1 = ret.Error(1)
 	}
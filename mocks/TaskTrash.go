@@ -0,0 +1,118 @@
+// Code generated by mockery v2.53.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "todo-list-task/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// TaskTrash is an autogenerated mock type for the TaskTrash type
+type TaskTrash struct {
+	mock.Mock
+}
+
+// GetTrash provides a mock function with given fields: ownerID
+func (_m *TaskTrash) GetTrash(ownerID string) ([]*domain.Task, error) {
+	ret := _m.Called(ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrash")
+	}
+
+	var r0 []*domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*domain.Task, error)); ok {
+		return rf(ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*domain.Task); ok {
+		r0 = rf(ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeTrash provides a mock function with given fields: before
+func (_m *TaskTrash) PurgeTrash(before time.Time) (int, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeTrash")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreTask provides a mock function with given fields: ownerID, id, actorID
+func (_m *TaskTrash) RestoreTask(ownerID string, id string, actorID string) (*domain.Task, error) {
+	ret := _m.Called(ownerID, id, actorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreTask")
+	}
+
+	var r0 *domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (*domain.Task, error)); ok {
+		return rf(ownerID, id, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) *domain.Task); ok {
+		r0 = rf(ownerID, id, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(ownerID, id, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTaskTrash creates a new instance of TaskTrash. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTaskTrash(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TaskTrash {
+	mock := &TaskTrash{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,52 @@
+// Code generated by mockery v2.53.2. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// TicketRedeemer is an autogenerated mock type for the TicketRedeemer type
+type TicketRedeemer struct {
+	mock.Mock
+}
+
+// Redeem provides a mock function with given fields: ticket
+func (_m *TicketRedeemer) Redeem(ticket string) (string, bool) {
+	ret := _m.Called(ticket)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Redeem")
+	}
+
+	var r0 string
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (string, bool)); ok {
+		return rf(ticket)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(ticket)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(ticket)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// NewTicketRedeemer creates a new instance of TicketRedeemer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTicketRedeemer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TicketRedeemer {
+	mock := &TicketRedeemer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}